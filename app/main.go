@@ -3,26 +3,31 @@
 package main
 
 import (
+	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 
 	"github.com/fchastanet/shell-command-bookmarker/app/application"
 	"github.com/fchastanet/shell-command-bookmarker/internal/args"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services"
-
-	// Import for side effects
-	_ "embed"
 )
 
-//go:embed resources/sqlite.schema.sql
-var sqliteSchema string
+//go:embed resources/migrations/*.sql
+var migrationsFS embed.FS
 
 func main() {
 	appService := services.NewAppService()
 	if err := mainImpl(appService); err != nil {
+		// the exit code of the command run from the command line is forwarded
+		var exitErr *services.CommandExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode)
+		}
 		slog.Error("critical error", "error", err)
-		fmt.Printf("Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
@@ -41,7 +46,20 @@ func mainImpl(appService services.AppServiceInterface) error {
 		return nil
 	}
 
-	if err := appService.Main(&cli, sqliteSchema); err != nil {
+	migrations, err := fs.Sub(migrationsFS, "resources/migrations")
+	if err != nil {
+		return err
+	}
+
+	if quit, err := appService.HandleImportExport(&cli, migrations); quit || err != nil {
+		return err
+	}
+
+	if quit, err := appService.HandleCommand(&cli, migrations); quit || err != nil {
+		return err
+	}
+
+	if err := appService.Main(&cli, migrations); err != nil {
 		return err
 	}
 
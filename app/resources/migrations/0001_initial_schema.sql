@@ -1,3 +1,6 @@
+-- Initial schema (version 1)
+-- Schema changes must be added as new migration files, never by editing this one
+
 -- Base tables
 CREATE TABLE folder (
     id INTEGER PRIMARY KEY,
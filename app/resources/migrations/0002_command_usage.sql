@@ -0,0 +1,9 @@
+-- Usage tracking (version 2)
+-- use_count and last_used_datetime are updated each time a command is
+-- selected for the shell or copied to the clipboard, they are used to compute
+-- the frecency of the command
+
+ALTER TABLE command ADD COLUMN use_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE command ADD COLUMN last_used_datetime TEXT;
+
+CREATE INDEX idx_command_last_used ON command(last_used_datetime);
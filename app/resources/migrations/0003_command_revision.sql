@@ -0,0 +1,20 @@
+-- Command revisions (version 3)
+-- A revision keeping the previous content of a command is recorded each time
+-- the command is saved, so changes can be displayed and reverted.
+-- The obsolete copy of an edited imported command references the command it
+-- has been replaced by through successor_id.
+
+CREATE TABLE command_revision (
+    id INTEGER PRIMARY KEY,
+    command_id INTEGER NOT NULL,
+    creation_datetime TEXT NOT NULL DEFAULT (datetime('now')),
+    title TEXT NOT NULL,
+    description TEXT,
+    script TEXT NOT NULL,
+    tags TEXT NOT NULL DEFAULT '',
+    FOREIGN KEY (command_id) REFERENCES command(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_command_revision_command ON command_revision(command_id);
+
+ALTER TABLE command ADD COLUMN successor_id INTEGER REFERENCES command(id) ON DELETE SET NULL;
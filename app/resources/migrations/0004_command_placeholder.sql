@@ -0,0 +1,21 @@
+-- Command placeholders (version 4)
+-- command_placeholder stores the optional description and allowed values of
+-- the placeholders (<name> or {{name:default}}) used in a command script,
+-- allowed values are comma separated.
+-- placeholder_value remembers the last value entered for each placeholder name.
+
+CREATE TABLE command_placeholder (
+    command_id INTEGER NOT NULL,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    description TEXT NOT NULL DEFAULT '',
+    allowed_values TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (command_id, name),
+    FOREIGN KEY (command_id) REFERENCES command(id) ON DELETE CASCADE
+);
+
+CREATE TABLE placeholder_value (
+    name TEXT PRIMARY KEY,
+    value TEXT NOT NULL,
+    modification_datetime TEXT NOT NULL DEFAULT (datetime('now'))
+);
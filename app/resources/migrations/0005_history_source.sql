@@ -0,0 +1,17 @@
+-- History sources (version 5)
+-- history_source lists the shell history files ingested (bash, zsh or fish),
+-- history_source_id references the history file a command has been imported
+-- from, it is NULL for the commands created in the application or imported
+-- before this version.
+
+CREATE TABLE history_source (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    format TEXT NOT NULL,
+    path TEXT NOT NULL UNIQUE,
+    creation_datetime TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+ALTER TABLE command ADD COLUMN history_source_id INTEGER
+    REFERENCES history_source(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_command_history_source ON command(history_source_id);
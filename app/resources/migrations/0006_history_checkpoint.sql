@@ -0,0 +1,14 @@
+-- History ingestion checkpoints (version 6)
+-- history_checkpoint stores, for each history source, the position following
+-- the last line ingested: the inode of the file allows to detect a rotated
+-- file, the byte offset allows to resume the ingestion and the sha256 of the
+-- last line ingested allows to detect a file truncated or rewritten in place.
+
+CREATE TABLE history_checkpoint (
+    history_source_id INTEGER PRIMARY KEY,
+    inode INTEGER NOT NULL DEFAULT 0,
+    byte_offset INTEGER NOT NULL DEFAULT 0,
+    last_line_hash TEXT NOT NULL DEFAULT '',
+    modification_datetime TEXT NOT NULL DEFAULT (datetime('now')),
+    FOREIGN KEY (history_source_id) REFERENCES history_source(id) ON DELETE CASCADE
+);
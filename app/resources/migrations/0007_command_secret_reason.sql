@@ -0,0 +1,5 @@
+-- Secrets detected during history ingestion (version 7)
+-- secret_reason explains why an imported command has been redacted or flagged
+-- by the secret scanner, it is empty when no secret has been detected.
+
+ALTER TABLE command ADD COLUMN secret_reason TEXT NOT NULL DEFAULT '';
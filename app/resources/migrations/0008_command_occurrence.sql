@@ -0,0 +1,19 @@
+-- History occurrences (version 8)
+-- occurrence_count is the number of times the script of the command has been
+-- found in the history files, first_seen_datetime and last_seen_datetime are
+-- the timestamps of its first and last occurrences. They are NULL for the
+-- commands created in the application. The imported commands run often but
+-- not saved yet are suggested to be saved.
+
+ALTER TABLE command ADD COLUMN occurrence_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE command ADD COLUMN first_seen_datetime TEXT;
+ALTER TABLE command ADD COLUMN last_seen_datetime TEXT;
+
+-- the commands imported before this version have been found at least once
+UPDATE command
+SET occurrence_count = 1,
+    first_seen_datetime = creation_datetime,
+    last_seen_datetime = creation_datetime
+WHERE history_source_id IS NOT NULL OR status = 'IMPORTED';
+
+CREATE INDEX idx_command_occurrence_count ON command(occurrence_count);
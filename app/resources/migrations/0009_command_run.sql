@@ -0,0 +1,24 @@
+-- Run log (version 9)
+-- A run is recorded each time a command is executed from the application with
+-- its start time, duration, exit code and the end of its output (stdout and
+-- stderr), only the most recent runs of each command are kept.
+-- last_run_datetime and last_run_status of the command summarize its last run,
+-- they are NULL for the commands never run.
+
+CREATE TABLE command_run (
+    id INTEGER PRIMARY KEY,
+    command_id INTEGER NOT NULL,
+    start_datetime TEXT NOT NULL,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    exit_code INTEGER NOT NULL DEFAULT -1,
+    status TEXT NOT NULL,
+    working_directory TEXT NOT NULL DEFAULT '',
+    output TEXT NOT NULL DEFAULT '',
+    output_truncated INTEGER NOT NULL DEFAULT 0,
+    FOREIGN KEY (command_id) REFERENCES command(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_command_run_command ON command_run(command_id, start_datetime);
+
+ALTER TABLE command ADD COLUMN last_run_datetime TEXT;
+ALTER TABLE command ADD COLUMN last_run_status TEXT;
@@ -0,0 +1,9 @@
+-- Dangerous commands (version 10)
+-- risk_level is the highest level (LOW, MEDIUM or HIGH) of the danger rules
+-- matching the script, it is empty when no rule matches. risk_reason lists
+-- these rules. Both are computed by the application each time the script is
+-- saved and when the application starts, so the changes of the rules apply to
+-- the existing commands.
+
+ALTER TABLE command ADD COLUMN risk_level TEXT NOT NULL DEFAULT '';
+ALTER TABLE command ADD COLUMN risk_reason TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,13 @@
+-- Full text search index update (version 11)
+-- command_fts only indexes the title, the description and the script of the
+-- commands, it is no longer rebuilt when the other columns are updated (eg:
+-- the occurrences counted during history ingestion or the status of the runs).
+
+DROP TRIGGER command_au;
+
+CREATE TRIGGER command_au AFTER UPDATE OF title, description, script ON command BEGIN
+    INSERT INTO command_fts(command_fts, rowid, title, description, script)
+    VALUES('delete', old.id, old.title, old.description, old.script);
+    INSERT INTO command_fts(rowid, title, description, script)
+    VALUES (new.id, new.title, new.description, new.script);
+END;
@@ -3,6 +3,7 @@ module github.com/fchastanet/shell-command-bookmarker
 go 1.23.1
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/alecthomas/kong v1.10.0
 	github.com/atotto/clipboard v0.1.4
 	github.com/charmbracelet/bubbletea v1.3.4
@@ -11,6 +12,7 @@ require (
 	github.com/davecgh/go-spew v1.1.1
 	github.com/lithammer/fuzzysearch v1.1.8
 	golang.org/x/exp v0.0.0-20250408133849-7e4ce0ab07d0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -35,7 +37,6 @@ require (
 	golang.org/x/net v0.40.0 // indirect
 	golang.org/x/term v0.32.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
 require (
@@ -4,21 +4,131 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/alecthomas/kong"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 )
 
 const maxScreenSize = 80
 
+// Sub commands, the interactive interface is launched when none is given
+const (
+	CommandTUI     = "tui"
+	CommandList    = "list"
+	CommandSearch  = "search"
+	CommandShow    = "show"
+	CommandAdd     = "add"
+	CommandEdit    = "edit"
+	CommandDelete  = "delete"
+	CommandRestore = "restore"
+	CommandRun     = "run"
+)
+
 type Cli struct {
-	DBPath       FilePath    `arg:""    name:"db-path"     optional:"" type:"path" help:"Path to the SQLite database file"`                  //nolint:tagalign //avoid reformat annotations
-	Version      VersionFlag `short:"v" name:"version"                             help:"Print version information and quit"`                //nolint:tagalign //avoid reformat annotations
-	OutputFile   string      `short:"o" name:"output-file" optional:""             help:"File to write selected command to"`                 //nolint:tagalign //avoid reformat annotations
-	MaxTasks     int         `short:"t" name:"max-tasks"   default:"1"             help:"Maximum number of tasks to run concurrently"`       //nolint:tagalign //avoid reformat annotations
-	Debug        bool        `short:"d"                                            help:"Set log in debug level"`                            //nolint:tagalign //avoid reformat annotations
-	GenerateZsh  bool        `          name:"zsh"         optional:""             help:"Generate Zsh integration script to stdout"`         //nolint:tagalign //avoid reformat annotations
-	GenerateBash bool        `          name:"bash"        optional:""             help:"Generate Bash integration script to stdout"`        //nolint:tagalign //avoid reformat annotations
-	AutoDetect   bool        `short:"a" name:"auto"        optional:""             help:"Auto-detect shell and generate integration script"` //nolint:tagalign //avoid reformat annotations
+	// DBPath is the database used: the argument of the tui command, the --db flag,
+	// $SHELL_CMD_BOOK_DB or db/shell-command-bookmarker.db
+	DBPath FilePath `kong:"-"`
+	// Command is the name of the selected sub command
+	Command string `kong:"-"`
+
+	DB           FilePath    `          name:"db"          optional:"" type:"path" placeholder:"FILE" help:"Path to the SQLite database file"` //nolint:tagalign //avoid reformat annotations
+	Version      VersionFlag `short:"v" name:"version"                             help:"Print version information and quit"`                  //nolint:tagalign //avoid reformat annotations
+	OutputFile   string      `short:"o" name:"output-file" optional:""             help:"File to write selected command to"`                   //nolint:tagalign //avoid reformat annotations
+	MaxTasks     int         `short:"t" name:"max-tasks"   default:"1"             help:"Maximum number of tasks to run concurrently"`         //nolint:tagalign //avoid reformat annotations
+	Debug        bool        `short:"d"                                            help:"Set log in debug level"`                              //nolint:tagalign //avoid reformat annotations
+	GenerateZsh  bool        `          name:"zsh"         optional:""             help:"Generate Zsh integration script to stdout"`           //nolint:tagalign //avoid reformat annotations
+	GenerateBash bool        `          name:"bash"        optional:""             help:"Generate Bash integration script to stdout"`          //nolint:tagalign //avoid reformat annotations
+	AutoDetect   bool        `short:"a" name:"auto"        optional:""             help:"Auto-detect shell and generate integration script"`   //nolint:tagalign //avoid reformat annotations
+
+	// configuration of the history ingestion
+	ConfigFile string `short:"c" name:"config" optional:"" placeholder:"FILE" help:"Path to the yaml configuration file (default: ~/.config/shell-command-bookmarker/config.yaml)"` //nolint:tagalign //avoid reformat annotations
+
+	// import/export of the commands
+	Export     string `name:"export"      optional:"" placeholder:"FILE" xor:"import-export" help:"Export the saved commands to FILE (- for stdout) and quit"`                 //nolint:tagalign //avoid reformat annotations
+	ExportAll  bool   `name:"export-all"  optional:""                                        help:"Export also the imported and deleted commands"`                             //nolint:tagalign //avoid reformat annotations
+	Import     string `name:"import"      optional:"" placeholder:"FILE" xor:"import-export" help:"Import the commands of FILE (- for stdin) and quit"`                        //nolint:tagalign //avoid reformat annotations
+	ImportNavi string `name:"import-navi" optional:"" placeholder:"PATH" xor:"import-export" help:"Import a navi cheat file or a directory of cheat files and quit"`           //nolint:tagalign //avoid reformat annotations
+	ImportPet  string `name:"import-pet"  optional:"" placeholder:"FILE" xor:"import-export" help:"Import a pet snippets file (snippet.toml) and quit"`                        //nolint:tagalign //avoid reformat annotations
+	Format     string `name:"format"      enum:"auto,json,yaml"           default:"auto"      help:"Format of the import/export file, auto uses the file extension (${enum})"` //nolint:tagalign //avoid reformat annotations
+	OnConflict string `name:"on-conflict" enum:"skip,overwrite,duplicate" default:"skip"      help:"Import of a command having the same script as an existing one (${enum})"`  //nolint:tagalign //avoid reformat annotations
+	DryRun     bool   `name:"dry-run"     optional:""                                        help:"Display the import summary without importing anything"`                     //nolint:tagalign //avoid reformat annotations
+
+	// preview of the ingestion filters of the configuration file
+	PreviewFilters bool `name:"preview-filters" optional:"" xor:"import-export" help:"Display the commands of the history files the ingestion filters would import and quit"` //nolint:tagalign //avoid reformat annotations
+
+	// generation of shell functions from the saved commands
+	ExportFunctions string `name:"export-functions" optional:"" placeholder:"FILE" xor:"import-export" help:"Export the saved commands as shell functions to FILE (- for stdout) and quit"` //nolint:tagalign //avoid reformat annotations
+	FunctionsShell  string `name:"functions-shell"  enum:"auto,bash,zsh"  default:"auto"             help:"Shell of the generated functions, auto uses the file extension (${enum})"`       //nolint:tagalign //avoid reformat annotations
+	FunctionsPrefix string `name:"functions-prefix" optional:""                                      help:"Prefix of the generated function names"`                                         //nolint:tagalign //avoid reformat annotations
+
+	// sub commands working without terminal
+	TUI     TUICmd     `cmd:"" name:"tui" default:"withargs" help:"Launch the interactive interface (default)"`    //nolint:tagalign //avoid reformat annotations
+	List    ListCmd    `cmd:""                               help:"List the commands"`                             //nolint:tagalign //avoid reformat annotations
+	Search  SearchCmd  `cmd:""                               help:"Search the commands using the full text index"` //nolint:tagalign //avoid reformat annotations
+	Show    ShowCmd    `cmd:""                               help:"Display a command"`                             //nolint:tagalign //avoid reformat annotations
+	Add     AddCmd     `cmd:""                               help:"Save a new command"`                            //nolint:tagalign //avoid reformat annotations
+	Edit    EditCmd    `cmd:""                               help:"Edit a command with $EDITOR"`                   //nolint:tagalign //avoid reformat annotations
+	Delete  DeleteCmd  `cmd:""                               help:"Mark the commands as deleted"`                  //nolint:tagalign //avoid reformat annotations
+	Restore RestoreCmd `cmd:""                               help:"Restore deleted commands"`                      //nolint:tagalign //avoid reformat annotations
+	Run     RunCmd     `cmd:""                               help:"Run a command and exit with its exit code"`     //nolint:tagalign //avoid reformat annotations
+}
+
+// TUICmd launches the interactive interface
+type TUICmd struct {
+	DBPath FilePath `arg:"" name:"db-path" optional:"" type:"path" help:"Path to the SQLite database file"` //nolint:tagalign //avoid reformat annotations
+}
+
+// ListCmd lists the commands having one of the statuses and all the tags
+type ListCmd struct {
+	Status []string `name:"status"        enum:"saved,imported,deleted,obsolete" default:"saved,imported" help:"Statuses of the commands listed (${enum})"`           //nolint:tagalign //avoid reformat annotations
+	Tag    []string `name:"tag"           optional:""                                                     help:"Only the commands having all the tags"`               //nolint:tagalign //avoid reformat annotations
+	Folder string   `name:"folder"        optional:""                                                     help:"Only the commands of the folder and its sub folders"` //nolint:tagalign //avoid reformat annotations
+	Output string   `name:"output-format" enum:"text,json,jsonl,tsv,script0"     default:"text"           help:"Format of the commands (${enum})"`                    //nolint:tagalign //avoid reformat annotations
+}
+
+// SearchCmd lists the commands matching the query, the most relevant first
+type SearchCmd struct {
+	Query  []string `arg:""               name:"query"                                                    help:"Words searched in the title, the description and the script"` //nolint:tagalign //avoid reformat annotations
+	Status []string `name:"status"        enum:"saved,imported,deleted,obsolete" default:"saved,imported" help:"Statuses of the commands searched (${enum})"`                 //nolint:tagalign //avoid reformat annotations
+	Output string   `name:"output-format" enum:"text,json,jsonl,tsv,script0"     default:"text"           help:"Format of the commands (${enum})"`                            //nolint:tagalign //avoid reformat annotations
+}
+
+// ShowCmd displays the details of a command
+type ShowCmd struct {
+	ID     resource.ID `arg:""               name:"id"                                         help:"Id of the command"`               //nolint:tagalign //avoid reformat annotations
+	Output string      `name:"output-format" enum:"text,json,jsonl,tsv,script0" default:"text" help:"Format of the command (${enum})"` //nolint:tagalign //avoid reformat annotations
+}
+
+// AddCmd saves a new command
+type AddCmd struct {
+	Script      string   `arg:""             optional:"" help:"Script of the command, read from stdin if missing or -"` //nolint:tagalign //avoid reformat annotations
+	Title       string   `name:"title"       optional:"" help:"Title of the command"`                                   //nolint:tagalign //avoid reformat annotations
+	Description string   `name:"description" optional:"" help:"Description of the command"`                             //nolint:tagalign //avoid reformat annotations
+	Tag         []string `name:"tag"         optional:"" help:"Tags of the command"`                                    //nolint:tagalign //avoid reformat annotations
+	Folder      string   `name:"folder"      optional:"" help:"Folder of the command, created if needed"`               //nolint:tagalign //avoid reformat annotations
+}
+
+// EditCmd edits the title, the description, the tags and the script of a command with $EDITOR
+type EditCmd struct {
+	ID resource.ID `arg:"" name:"id" help:"Id of the command"`
+}
+
+// DeleteCmd marks the commands as deleted
+type DeleteCmd struct {
+	IDs []resource.ID `arg:"" name:"id" help:"Ids of the commands"`
+}
+
+// RestoreCmd restores deleted commands
+type RestoreCmd struct {
+	IDs []resource.ID `arg:"" name:"id" help:"Ids of the commands"`
+}
+
+// RunCmd runs a command in the current shell
+type RunCmd struct {
+	ID  resource.ID       `arg:""     name:"id"           help:"Id of the command"`                                                             //nolint:tagalign //avoid reformat annotations
+	Set map[string]string `name:"set" optional:""         help:"Value of a placeholder (name=value), the last value entered is used otherwise"` //nolint:tagalign //avoid reformat annotations
+	Yes bool              `name:"yes" short:"y"           help:"Run the command even if its risk level is high"`                                //nolint:tagalign //avoid reformat annotations
 }
 
 type FilePath string
@@ -61,7 +171,7 @@ func (VersionFlag) BeforeApply(
 
 func ParseArgs(cli *Cli) (err error) {
 	// just need the yaml file, from which all the dependencies will be deduced
-	kong.Parse(cli,
+	ctx := kong.Parse(cli,
 		kong.Name("shell-command-bookmarker"),
 		kong.Description("A command line tool to bookmark shell commands"),
 		kong.UsageOnError(),
@@ -80,12 +190,20 @@ func ParseArgs(cli *Cli) (err error) {
 		},
 	)
 
+	cli.Command = strings.Fields(ctx.Command())[0]
+	cli.DBPath = cli.TUI.DBPath
+	if cli.DBPath == "" {
+		cli.DBPath = cli.DB
+	}
 	if cli.DBPath == "" {
 		cli.DBPath = "db/shell-command-bookmarker.db"
 		if os.Getenv("SHELL_CMD_BOOK_DB") != "" {
 			cli.DBPath = FilePath(os.Getenv("SHELL_CMD_BOOK_DB"))
 		}
 	}
+	if cli.ConfigFile == "" {
+		cli.ConfigFile = os.Getenv("SHELL_CMD_BOOK_CONFIG")
+	}
 
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"testing"
 
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,6 +18,23 @@ func defaultCli() *Cli {
 		GenerateZsh:  false,
 		GenerateBash: false,
 		AutoDetect:   false,
+		Export:       "",
+		ExportAll:    false,
+		Import:       "",
+		ImportNavi:   "",
+		ImportPet:    "",
+		Format:       "auto",
+		OnConflict:   "skip",
+		DryRun:       false,
+
+		ExportFunctions: "",
+		FunctionsShell:  "auto",
+		FunctionsPrefix: "",
+
+		Command: CommandTUI,
+		List:    ListCmd{Status: []string{"saved", "imported"}, Output: "text"},   //nolint:exhaustruct //test
+		Search:  SearchCmd{Status: []string{"saved", "imported"}, Output: "text"}, //nolint:exhaustruct //test
+		Show:    ShowCmd{Output: "text"},                                          //nolint:exhaustruct //test
 	}
 }
 
@@ -105,6 +123,132 @@ func TestArgs(t *testing.T) {
 	})
 }
 
+func TestArgsImportExport(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	t.Run("export", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Export = "bookmarks.yaml"
+		expectedCli.ExportAll = true
+		os.Args = []string{"cmd", "--export", "bookmarks.yaml", "--export-all"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("import", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.Import = "-"
+		expectedCli.Format = "yaml"
+		expectedCli.OnConflict = "overwrite"
+		expectedCli.DryRun = true
+		os.Args = []string{"cmd", "--import", "-", "--format", "yaml", "--on-conflict", "overwrite", "--dry-run"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("import navi", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.ImportNavi = "cheats"
+		expectedCli.DryRun = true
+		os.Args = []string{"cmd", "--import-navi", "cheats", "--dry-run"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("import pet", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.ImportPet = "snippet.toml"
+		os.Args = []string{"cmd", "--import-pet", "snippet.toml"}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("export functions", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.ExportFunctions = "functions.sh"
+		expectedCli.FunctionsShell = "zsh"
+		expectedCli.FunctionsPrefix = "bm_"
+		os.Args = []string{
+			"cmd", "--export-functions", "functions.sh", "--functions-shell", "zsh", "--functions-prefix", "bm_",
+		}
+		cli := &Cli{} //nolint:exhaustruct //test
+		err := ParseArgs(cli)
+		assert.Nil(t, err)
+		assert.Equal(t, expectedCli, cli)
+	})
+}
+
+func TestArgsCommands(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	parse := func(t *testing.T, args ...string) *Cli {
+		t.Helper()
+		os.Args = append([]string{"cmd"}, args...)
+		cli := &Cli{} //nolint:exhaustruct //test
+		assert.Nil(t, ParseArgs(cli))
+		return cli
+	}
+
+	t.Run("database as argument of the default command", func(t *testing.T) {
+		expectedCli := defaultCli()
+		expectedCli.DBPath = "/tmp/my.db"
+		expectedCli.TUI.DBPath = "/tmp/my.db"
+		cli := parse(t, "/tmp/my.db")
+		assert.Equal(t, expectedCli, cli)
+	})
+
+	t.Run("database flag", func(t *testing.T) {
+		cli := parse(t, "--db", "/tmp/my.db", "list")
+		assert.Equal(t, CommandList, cli.Command)
+		assert.Equal(t, FilePath("/tmp/my.db"), cli.DBPath)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		cli := parse(t,
+			"list", "--status", "deleted", "--tag", "git", "--tag", "docker", "--folder", "ops", "--output-format", "jsonl",
+		)
+		assert.Equal(t, CommandList, cli.Command)
+		assert.Equal(t, ListCmd{
+			Status: []string{"deleted"}, Tag: []string{"git", "docker"}, Folder: "ops", Output: "jsonl",
+		}, cli.List)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		cli := parse(t, "search", "git", "log")
+		assert.Equal(t, CommandSearch, cli.Command)
+		assert.Equal(t, []string{"git", "log"}, cli.Search.Query)
+		assert.Equal(t, []string{"saved", "imported"}, cli.Search.Status)
+	})
+
+	t.Run("add", func(t *testing.T) {
+		cli := parse(t, "add", "ls -la", "--title", "list", "--tag", "fs")
+		assert.Equal(t, CommandAdd, cli.Command)
+		assert.Equal(t, AddCmd{Script: "ls -la", Title: "list", Description: "", Tag: []string{"fs"}, Folder: ""}, cli.Add)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		cli := parse(t, "delete", "3", "4")
+		assert.Equal(t, CommandDelete, cli.Command)
+		assert.Equal(t, []resource.ID{3, 4}, cli.Delete.IDs)
+	})
+
+	t.Run("run", func(t *testing.T) {
+		cli := parse(t, "run", "5", "--set", "branch=main", "-y")
+		assert.Equal(t, CommandRun, cli.Command)
+		assert.Equal(t, RunCmd{ID: 5, Set: map[string]string{"branch": "main"}, Yes: true}, cli.Run)
+	})
+}
+
 // TestArgsGenerateFlags tests the CLI argument parsing for the integration flags
 func TestArgsGenerateFlags(t *testing.T) {
 	tests := []struct {
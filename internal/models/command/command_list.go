@@ -3,8 +3,10 @@ package command
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -40,11 +42,14 @@ type ListMaker struct {
 const (
 	idColumnPercentWidth         = 6
 	titleColumnPercentWidth      = 19
-	scriptColumnPercentWidth     = 65
+	tagsColumnPercentWidth       = 12
+	scriptColumnPercentWidth     = 40
 	statusColumnPercentWidth     = 7
 	lintStatusColumnPercentWidth = 6
+	lastStatusColumnPercentWidth = 5
+	lastRunColumnPercentWidth    = 8
 
-	indexColumnStatus = 3
+	indexColumnStatus = 4
 
 	percent    = 100
 	sidesCount = 2
@@ -53,9 +58,14 @@ const (
 func (mm *ListMaker) Make(_ resource.ID, width, height int) (structure.ChildModel, error) {
 	idColumn := newColumn(table.ColumnKey(structure.FieldID), "Id", table.NoTruncate)
 	titleColumn := newColumn(table.ColumnKey(structure.FieldTitle), "Title", table.GetDefaultTruncationFunc())
+	tagsColumn := newColumn(table.ColumnKey(structure.FieldTags), "Tags", table.GetDefaultTruncationFunc())
 	scriptColumn := newColumn(table.ColumnKey(structure.FieldScript), "Script", table.GetDefaultTruncationFunc())
 	statusColumn := newColumn(table.ColumnKey(structure.FieldStatus), "Status", table.GetDefaultTruncationFunc())
 	lintStatusColumn := newColumn(table.ColumnKey(structure.FieldLintStatus), "Lint", table.GetDefaultTruncationFunc())
+	lastStatusColumn := newColumn(
+		table.ColumnKey(structure.FieldLastRunStatus), "Last Status", table.GetDefaultTruncationFunc(),
+	)
+	lastRunColumn := newColumn(table.ColumnKey(structure.FieldLastRun), "Last Run", table.GetDefaultTruncationFunc())
 	filterScoreColumn := newColumn(table.ColumnKey(structure.FieldFilterScore), "Score", table.NoTruncate)
 
 	// set filter
@@ -86,11 +96,16 @@ func (mm *ListMaker) Make(_ resource.ID, width, height int) (structure.ChildMode
 		styles:                  mm.Styles,
 		idColumn:                &idColumn,
 		titleColumn:             &titleColumn,
+		tagsColumn:              &tagsColumn,
 		scriptColumn:            &scriptColumn,
 		statusColumn:            &statusColumn,
 		lintStatusColumn:        &lintStatusColumn,
+		lastStatusColumn:        &lastStatusColumn,
+		lastRunColumn:           &lastRunColumn,
 		filterScoreColumn:       &filterScoreColumn,
 		categoryTabs:            categoryTabs,
+		folderPath:              "",
+		folderIDs:               []resource.ID{},
 	}
 	renderer := func(cmd *dbmodels.Command) table.RenderedRow {
 		return mm.renderRow(cmd, m)
@@ -172,15 +187,41 @@ func (*ListMaker) renderRow(
 	commandsListModel *commandsList,
 ) table.RenderedRow {
 	return table.RenderedRow{
-		commandsListModel.idColumn.Key:          fmt.Sprintf("%d", cmd.GetID()),
-		commandsListModel.titleColumn.Key:       cmd.Title,
-		commandsListModel.scriptColumn.Key:      cmd.Script,
-		commandsListModel.statusColumn.Key:      formatStatus(cmd, commandsListModel.styles.EditorStyle),
-		commandsListModel.lintStatusColumn.Key:  formatLintStatus(cmd, commandsListModel.styles.EditorStyle),
+		commandsListModel.idColumn.Key:    fmt.Sprintf("%d", cmd.GetID()),
+		commandsListModel.titleColumn.Key: cmd.Title,
+		commandsListModel.tagsColumn.Key:  dbmodels.FormatTags(cmd.Tags),
+		commandsListModel.scriptColumn.Key: formatRiskBadge(cmd.RiskLevel, commandsListModel.styles.EditorStyle) +
+			formatScript(cmd, commandsListModel.styles.SearchHighlight),
+		commandsListModel.statusColumn.Key:     formatStatus(cmd, commandsListModel.styles.EditorStyle),
+		commandsListModel.lintStatusColumn.Key: formatLintStatus(cmd, commandsListModel.styles.EditorStyle),
+		commandsListModel.lastStatusColumn.Key: formatRunStatus(
+			cmd.LastRunStatus, -1, commandsListModel.styles.EditorStyle,
+		),
+		commandsListModel.lastRunColumn.Key:     formatLastRun(cmd),
 		commandsListModel.filterScoreColumn.Key: strconv.Itoa(cmd.FilterScore),
 	}
 }
 
+// formatScript displays the highlighted snippet of the script matching the filter if any
+func formatScript(cmd *dbmodels.Command, highlightStyle *lipgloss.Style) string {
+	if cmd.FilterSnippet == "" {
+		return cmd.Script
+	}
+	var sb strings.Builder
+	snippet := cmd.FilterSnippet
+	for {
+		before, rest, found := strings.Cut(snippet, dbmodels.SearchHighlightStart)
+		sb.WriteString(before)
+		if !found {
+			break
+		}
+		match, after, _ := strings.Cut(rest, dbmodels.SearchHighlightEnd)
+		sb.WriteString(highlightStyle.Render(match))
+		snippet = after
+	}
+	return sb.String()
+}
+
 func formatStatus(
 	cmd *dbmodels.Command,
 	editorStyle *styles.EditorStyle,
@@ -199,6 +240,43 @@ func formatStatus(
 	}
 }
 
+// formatLastRun displays the date of the last run, the time only if it is today
+func formatLastRun(cmd *dbmodels.Command) string {
+	if cmd.LastRunDatetime.IsZero() {
+		return ""
+	}
+	if cmd.LastRunDatetime.Format(time.DateOnly) == time.Now().Format(time.DateOnly) {
+		return cmd.LastRunDatetime.Format(time.TimeOnly)
+	}
+	return cmd.LastRunDatetime.Format(time.DateOnly)
+}
+
+// formatRunStatus returns a styled string representing the status of a command run,
+// exitCode is -1 if unknown
+func formatRunStatus(
+	status dbmodels.CommandRunStatus,
+	exitCode int,
+	editorStyle *styles.EditorStyle,
+) string {
+	switch status {
+	case dbmodels.CommandRunStatusSuccess:
+		return editorStyle.StatusOK.Render("OK")
+	case dbmodels.CommandRunStatusFailure:
+		if exitCode < 0 {
+			return editorStyle.StatusError.Render("Failed")
+		}
+		return editorStyle.StatusError.Render(fmt.Sprintf("Exit %d", exitCode))
+	case dbmodels.CommandRunStatusCanceled:
+		return editorStyle.StatusWarning.Render("Canceled")
+	case dbmodels.CommandRunStatusError:
+		return editorStyle.StatusError.Render("Error")
+	case dbmodels.CommandRunStatusNone:
+		return ""
+	default:
+		return string(status)
+	}
+}
+
 func formatLintStatus(
 	cmd *dbmodels.Command,
 	editorStyle *styles.EditorStyle,
@@ -234,11 +312,20 @@ type commandsList struct {
 
 	idColumn          *table.Column
 	titleColumn       *table.Column
+	tagsColumn        *table.Column
 	scriptColumn      *table.Column
 	statusColumn      *table.Column
 	lintStatusColumn  *table.Column
+	lastStatusColumn  *table.Column
+	lastRunColumn     *table.Column
 	filterScoreColumn *table.Column
 
+	// folderPath is the path of the folder selected in the folder tree
+	folderPath string
+	// folderIDs restricts the commands to the selected folder and its sub folders,
+	// all the commands are displayed if empty
+	folderIDs []resource.ID
+
 	height int
 	width  int
 
@@ -253,9 +340,12 @@ func (m *commandsList) getColumns() []table.Column {
 	columns := []table.Column{
 		*m.idColumn,
 		*m.titleColumn,
+		*m.tagsColumn,
 		*m.scriptColumn,
 		*m.statusColumn,
 		*m.lintStatusColumn,
+		*m.lastStatusColumn,
+		*m.lastRunColumn,
 	}
 	if m.categoryTabs.GetActiveFilter() != "" {
 		columns = append(columns, *m.filterScoreColumn)
@@ -264,7 +354,7 @@ func (m *commandsList) getColumns() []table.Column {
 }
 
 func (m *commandsList) computeColumnsWidth(width int) {
-	columnsCount := 5
+	columnsCount := 8
 	spaceForAdditionalColumn := 0
 	if m.categoryTabs.GetActiveFilter() != "" {
 		columnsCount++
@@ -277,9 +367,12 @@ func (m *commandsList) computeColumnsWidth(width int) {
 		columnsCount*m.styles.TableStyle.GetTableCellStyle().GetHorizontalPadding()*sidesCount
 	m.idColumn.Width = (idColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
 	m.titleColumn.Width = (titleColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
+	m.tagsColumn.Width = (tagsColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
 	m.scriptColumn.Width = (scriptColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
 	m.statusColumn.Width = (statusColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
 	m.lintStatusColumn.Width = (lintStatusColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
+	m.lastStatusColumn.Width = (lastStatusColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
+	m.lastRunColumn.Width = (lastRunColumnPercentWidth-spaceForAdditionalColumn)*w/percent + roundedAdaptation
 	if m.categoryTabs.GetActiveFilter() != "" {
 		m.filterScoreColumn.Width = columnsCount
 	} else {
@@ -310,7 +403,7 @@ func (m *commandsList) Update(msg tea.Msg) tea.Cmd {
 	case sort.Msg[*dbmodels.Command, string]:
 		return m.reloadCommandsAfterSort(msg.State, msg.InfoMsg)
 	case table.ReloadMsg[*dbmodels.Command]:
-		return m.loadCommandsForCurrentCategory(msg.RowID)
+		return m.reloadCommands(msg.RowID, msg.InfoMsg)
 	case tea.WindowSizeMsg:
 		return m.handleWindowSize(msg)
 	case tea.BlurMsg:
@@ -328,6 +421,10 @@ func (m *commandsList) Update(msg tea.Msg) tea.Cmd {
 		cmds = append(cmds, cmd)
 	case table.RowDeleteActionMsg[*dbmodels.Command]:
 		return m.handleDeleteRows()
+	case structure.FolderSelectedMsg:
+		m.folderPath = msg.Path
+		m.folderIDs = msg.FolderIDs
+		return m.loadCommandsForCurrentCategory(-1)
 	case pkgTabs.CategoryTabChangedMsg[
 		*dbmodels.Command,
 		dbmodels.CommandStatus,
@@ -371,6 +468,29 @@ func (m *commandsList) reloadCommandsAfterSort(
 	return reload
 }
 
+// reloadCommands reloads the commands of the current category,
+// the info message, if any, replaces the message giving the number of loaded commands
+func (m *commandsList) reloadCommands(selectRowID resource.ID, infoMsg *tui.InfoMsg) tea.Cmd {
+	if selectRowID <= 0 {
+		// keep the current command selected even if the reloaded commands are inserted before it
+		if row, ok := m.Model.CurrentRow(); ok {
+			selectRowID = row.GetID()
+		}
+	}
+	load := m.loadCommandsForCurrentCategory(selectRowID)
+	if infoMsg == nil {
+		return load
+	}
+	return func() tea.Msg {
+		msg := load()
+		if bulkInsertMsg, ok := msg.(table.BulkInsertMsg[*dbmodels.Command]); ok {
+			bulkInsertMsg.InfoMsg = string(*infoMsg)
+			return bulkInsertMsg
+		}
+		return msg
+	}
+}
+
 // loadCommandsForCurrentCategory loads commands for the current category
 func (m *commandsList) loadCommandsForCurrentCategory(selectRowID resource.ID) tea.Cmd {
 	return func() tea.Msg {
@@ -382,25 +502,13 @@ func (m *commandsList) loadCommandsForCurrentCategory(selectRowID resource.ID) t
 			"category", m.categoryTabs.GetActiveCategory(),
 			"statuses", statuses)
 
-		// Load commands for those statuses
-		rows, err := m.HistoryService.GetCommandsByStatus(statuses...)
+		// Load commands for those statuses matching the filter and the selected folder
+		rows, err := m.getFilteredCommands(statuses)
 		if err != nil {
 			slog.Error("Error getting commands for category", "error", err)
 			return nil
 		}
 
-		// filter commands using filter
-		if m.categoryTabs.GetActiveFilter() != "" {
-			filteredRows := make([]*dbmodels.Command, 0, len(rows))
-			for _, cmd := range rows {
-				if match, score := matchFilter(m.categoryTabs.GetActiveFilter(), cmd); match {
-					cmd.FilterScore = score
-					filteredRows = append(filteredRows, cmd)
-				}
-			}
-			rows = filteredRows
-		}
-
 		// Update category counts
 		m.updateCategoryCounts()
 
@@ -415,6 +523,9 @@ func (m *commandsList) loadCommandsForCurrentCategory(selectRowID resource.ID) t
 			len(rows),
 			m.categoryTabs.GetActiveTabTitle(),
 		)
+		if m.folderPath != "" {
+			info += fmt.Sprintf(" (folder: %s)", m.folderPath)
+		}
 		if m.categoryTabs.GetActiveFilter() != "" {
 			info += fmt.Sprintf(" (filter: %s)", m.categoryTabs.GetActiveFilter())
 		}
@@ -426,6 +537,54 @@ func (m *commandsList) loadCommandsForCurrentCategory(selectRowID resource.ID) t
 	}
 }
 
+// getFilteredCommands returns the commands of the selected folder having one of the statuses
+// and matching the active filter.
+// The full text index is used first, fuzzy matching is only used when it does not give any result.
+func (m *commandsList) getFilteredCommands(statuses []dbmodels.CommandStatus) ([]*dbmodels.Command, error) {
+	filter := m.categoryTabs.GetActiveFilter()
+	tags, text := parseFilter(filter)
+	if text != "" {
+		rows, err := m.HistoryService.SearchCommands(text, statuses...)
+		if err != nil {
+			slog.Warn("Full text search failed, using fuzzy matching", "filter", filter, "error", err)
+		}
+		rows = slices.DeleteFunc(rows, func(cmd *dbmodels.Command) bool {
+			return !m.isInSelectedFolder(cmd) || !m.isInActiveCategory(cmd) || !hasTags(cmd, tags)
+		})
+		if len(rows) > 0 {
+			return rows, nil
+		}
+	}
+
+	rows, err := m.HistoryService.GetCommandsByStatus(statuses...)
+	if err != nil {
+		return nil, err
+	}
+	filteredRows := make([]*dbmodels.Command, 0, len(rows))
+	for _, cmd := range rows {
+		if !m.isInSelectedFolder(cmd) || !m.isInActiveCategory(cmd) {
+			continue
+		}
+		if match, score := matchFilter(filter, cmd); match {
+			cmd.FilterScore = score
+			filteredRows = append(filteredRows, cmd)
+		}
+	}
+	return filteredRows, nil
+}
+
+// isInSelectedFolder returns true if the command is in the folder selected in the folder tree
+// or in one of its sub folders
+func (m *commandsList) isInSelectedFolder(cmd *dbmodels.Command) bool {
+	return len(m.folderIDs) == 0 || slices.Contains(m.folderIDs, cmd.FolderID)
+}
+
+// isInActiveCategory returns false if the active category restricts the commands
+// having one of its statuses, the suggestions being only the imported commands run often
+func (m *commandsList) isInActiveCategory(cmd *dbmodels.Command) bool {
+	return m.categoryTabs.GetActiveCategory() != tabs.SuggestedCommands || cmd.IsSuggestion()
+}
+
 // updateCategoryCounts updates the count of commands in each category
 func (m *commandsList) updateCategoryCounts() {
 	// Using the CategoryTabs adapter to update counts directly from the HistoryService
@@ -581,6 +740,27 @@ func (m *commandsList) handleKeyMsg(msg tea.KeyMsg) (cmd tea.Cmd, forward bool)
 	case tui.CheckKey(msg, customK.SelectForShell):
 		forward = false
 		cmds = append(cmds, m.handleSelectForShell())
+	case tui.CheckKey(msg, customK.EditTags):
+		forward = false
+		cmds = append(cmds, m.handleEditTags())
+	case tui.CheckKey(msg, customK.MoveToFolder):
+		forward = false
+		cmds = append(cmds, m.handleMoveToFolder())
+	case tui.CheckKey(msg, customK.ShowRevisions):
+		forward = false
+		cmds = append(cmds, m.handleShowRevisions())
+	case tui.CheckKey(msg, customK.ImportSnippets):
+		forward = false
+		cmds = append(cmds, m.handleImportSnippets())
+	case tui.CheckKey(msg, customK.ExportFunctions):
+		forward = false
+		cmds = append(cmds, m.handleExportFunctions())
+	case tui.CheckKey(msg, customK.SaveSuggestion):
+		forward = false
+		cmds = append(cmds, m.handleSaveSuggestion())
+	case tui.CheckKey(msg, customK.RunCommand):
+		forward = false
+		cmds = append(cmds, m.handleRunCommand())
 	}
 	return tea.Batch(cmds...), forward
 }
@@ -616,8 +796,154 @@ func (m *commandsList) handleRestoreCommand() tea.Cmd {
 	}
 }
 
+// handleSaveSuggestion promotes the selected imported commands into the saved commands
+func (m *commandsList) handleSaveSuggestion() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
+		return func() tea.Msg {
+			return tui.ErrorMsg(&ErrNoCommandsSelected{})
+		}
+	}
+	for _, row := range rows {
+		if row.Status != dbmodels.CommandStatusImported {
+			return func() tea.Msg {
+				return tui.ErrorMsg(&ErrSelectionMismatch{})
+			}
+		}
+	}
+	nextRowID := m.Model.GetNextRowIDRelativeToCurrentRow()
+	if err := m.HistoryService.SaveSuggestedCommands(rows); err != nil {
+		return func() tea.Msg {
+			return tui.ErrorMsg(&ErrSaveSuggestion{Err: err})
+		}
+	}
+	m.Model.DeselectAll()
+
+	infoMsg := tui.InfoMsg(fmt.Sprintf("Saved %d command(s)", len(rows)))
+	return func() tea.Msg {
+		return table.ReloadMsg[*dbmodels.Command]{
+			RowID:   nextRowID,
+			InfoMsg: &infoMsg,
+		}
+	}
+}
+
+// handleEditTags prompts for the tags to add or remove on the selected commands.
+// Tags prefixed by '-' are removed, the other ones are added.
+func (m *commandsList) handleEditTags() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
+		return func() tea.Msg {
+			return tui.ErrorMsg(&ErrNoCommandsSelected{})
+		}
+	}
+	for _, row := range rows {
+		if !row.IsEditable() {
+			return func() tea.Msg {
+				return tui.ErrorMsg(&ErrSelectionMismatch{})
+			}
+		}
+	}
+	tagTitles := m.HistoryService.GetTagTitles()
+	return tui.InputPrompt(
+		fmt.Sprintf("Tags to add/remove (-tag) on %d command(s):", len(rows)),
+		"tag1, tag2, -tagToRemove",
+		func(value string) []string {
+			return dbmodels.TagSuggestions(value, tagTitles)
+		},
+		keys.GetFormKeyMap(),
+		func(value string) tea.Cmd {
+			return m.editTags(rows, value)
+		},
+	)
+}
+
+func (m *commandsList) editTags(rows []*dbmodels.Command, value string) tea.Cmd {
+	var addedTags, removedTags []string
+	for _, tag := range dbmodels.ParseTags(value) {
+		if removedTag, found := strings.CutPrefix(tag, "-"); found {
+			if removedTag != "" {
+				removedTags = append(removedTags, removedTag)
+			}
+			continue
+		}
+		addedTags = append(addedTags, tag)
+	}
+	if err := m.HistoryService.AddTagsToCommands(rows, addedTags); err != nil {
+		return tui.ReportError(&ErrEditTags{Err: err})
+	}
+	if err := m.HistoryService.RemoveTagsFromCommands(rows, removedTags); err != nil {
+		return tui.ReportError(&ErrEditTags{Err: err})
+	}
+	m.Model.DeselectAll()
+
+	infoMsg := tui.InfoMsg(fmt.Sprintf(
+		"%d tag(s) added, %d tag(s) removed on %d command(s)",
+		len(addedTags), len(removedTags), len(rows),
+	))
+	return tui.CmdHandler(table.ReloadMsg[*dbmodels.Command]{
+		RowID:   rows[0].GetID(),
+		InfoMsg: &infoMsg,
+	})
+}
+
+// handleMoveToFolder prompts for the folder path where the selected commands are moved,
+// missing folders are created and an empty path removes the commands from their folder
+func (m *commandsList) handleMoveToFolder() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
+		return func() tea.Msg {
+			return tui.ErrorMsg(&ErrNoCommandsSelected{})
+		}
+	}
+	for _, row := range rows {
+		if !row.IsEditable() {
+			return func() tea.Msg {
+				return tui.ErrorMsg(&ErrSelectionMismatch{})
+			}
+		}
+	}
+	folders, err := m.HistoryService.GetFolders()
+	if err != nil {
+		return tui.ReportError(&ErrMoveToFolder{Err: err})
+	}
+	return tui.InputPrompt(
+		fmt.Sprintf("Move %d command(s) to folder (empty to remove from folder):", len(rows)),
+		"folder/sub-folder",
+		func(value string) []string {
+			return dbmodels.FolderPathSuggestions(value, folders)
+		},
+		keys.GetFormKeyMap(),
+		func(value string) tea.Cmd {
+			return m.moveToFolder(rows, value)
+		},
+	)
+}
+
+func (m *commandsList) moveToFolder(rows []*dbmodels.Command, path string) tea.Cmd {
+	folder, err := m.HistoryService.MoveCommandsToFolderPath(rows, path)
+	if err != nil {
+		return tui.ReportError(&ErrMoveToFolder{Err: err})
+	}
+	m.Model.DeselectAll()
+
+	info := fmt.Sprintf("%d command(s) removed from their folder", len(rows))
+	if folder != nil {
+		info = fmt.Sprintf("%d command(s) moved to folder '%s'", len(rows), folder.Path)
+	}
+	infoMsg := tui.InfoMsg(info)
+	return tui.CmdHandler(table.ReloadMsg[*dbmodels.Command]{
+		RowID:   rows[0].GetID(),
+		InfoMsg: &infoMsg,
+	})
+}
+
 func (m *commandsList) handleComposeCommand() tea.Cmd {
 	rows := m.Model.SelectedOrCurrent()
+	return m.withFilledPlaceholders(rows, m.composeCommand)
+}
+
+func (m *commandsList) composeCommand(rows []*dbmodels.Command) tea.Cmd {
 	newCmd, err := m.HistoryService.ComposeCommand(rows)
 	if err != nil {
 		return func() tea.Msg {
@@ -649,7 +975,10 @@ func (m *commandsList) handleCopyToClipboard() tea.Cmd {
 			return tui.ErrorMsg(&ErrNoCommandsSelected{})
 		}
 	}
+	return m.withFilledPlaceholders(rows, m.withRiskConfirmation("copy", m.copyToClipboard))
+}
 
+func (m *commandsList) copyToClipboard(rows []*dbmodels.Command) tea.Cmd {
 	commandsString := m.HistoryService.CreateCommandsString(rows)
 	err := clipboard.WriteAll(commandsString)
 	if err != nil {
@@ -657,6 +986,7 @@ func (m *commandsList) handleCopyToClipboard() tea.Cmd {
 			return tui.ErrorMsg(&ErrClipboardCopyFailed{Err: err})
 		}
 	}
+	m.recordUsage(rows)
 
 	m.Model.DeselectAll()
 	return func() tea.Msg {
@@ -673,10 +1003,62 @@ func (m *commandsList) handleSelectForShell() tea.Cmd {
 	}
 
 	// We only want the first command for shell pasting
-	commandString := m.HistoryService.CreateCommandsString(rows[:1])
+	return m.withFilledPlaceholders(rows[:1], m.withRiskConfirmation("paste", func(rows []*dbmodels.Command) tea.Cmd {
+		commandString := m.HistoryService.CreateCommandsString(rows)
+		m.recordUsage(rows)
 
-	return func() tea.Msg {
-		return structure.CommandSelectedForShellMsg{Command: commandString}
+		return func() tea.Msg {
+			return structure.CommandSelectedForShellMsg{Command: commandString}
+		}
+	}))
+}
+
+// handleShowRevisions opens the revisions of the current command in the bottom pane
+func (m *commandsList) handleShowRevisions() tea.Cmd {
+	row, ok := m.Model.CurrentRow()
+	if !ok {
+		return tui.ReportError(&ErrNoCommandsSelected{})
+	}
+	return tui.CmdHandler(structure.NavigationMsg{
+		Page: structure.Page{
+			Kind: structure.RevisionKind,
+			ID:   row.GetID(),
+		},
+		Position:     structure.BottomPane,
+		DisableFocus: false,
+	})
+}
+
+// handleRunCommand runs the selected commands or the current one in the background,
+// their output is displayed in the task pane opened at the bottom
+func (m *commandsList) handleRunCommand() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
+		return tui.ReportError(&ErrNoCommandsSelected{})
+	}
+	return m.withFilledPlaceholders(rows, m.withRiskConfirmation("run", func(rows []*dbmodels.Command) tea.Cmd {
+		tasks := m.TaskService.RunCommands(rows)
+		m.recordUsage(rows)
+		m.Model.DeselectAll()
+		return tea.Batch(
+			tui.CmdHandler(structure.NavigationMsg{
+				Page: structure.Page{
+					Kind: structure.TaskKind,
+					ID:   0,
+				},
+				Position:     structure.BottomPane,
+				DisableFocus: true,
+			}),
+			tui.ReportInfo("%d command(s) queued to run", len(tasks)),
+		)
+	}))
+}
+
+// recordUsage updates the use count and last used date of the commands,
+// a failure is only logged as it should not prevent using the commands
+func (m *commandsList) recordUsage(rows []*dbmodels.Command) {
+	if err := m.HistoryService.RecordCommandsUsage(rows); err != nil {
+		slog.Warn("Unable to record commands usage", "count", len(rows), "error", err)
 	}
 }
 
@@ -0,0 +1,61 @@
+package command
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatScript(t *testing.T) {
+	highlightStyle := lipgloss.NewStyle()
+	//nolint:exhaustruct // only fields used by formatScript are relevant
+	cmd := &dbmodels.Command{Script: "docker compose up -d"}
+	assert.Equal(t, "docker compose up -d", formatScript(cmd, &highlightStyle))
+
+	cmd.FilterSnippet = dbmodels.SearchHighlightStart + "docker" + dbmodels.SearchHighlightEnd +
+		" compose " + dbmodels.SearchHighlightStart + "up" + dbmodels.SearchHighlightEnd + " -d"
+	highlightStyle = lipgloss.NewStyle().Transform(func(s string) string { return "[" + s + "]" })
+	assert.Equal(t, "[docker] compose [up] -d", formatScript(cmd, &highlightStyle))
+}
+
+func TestRiskConfirmMessage(t *testing.T) {
+	//nolint:exhaustruct // only fields used by riskConfirmMessage are relevant
+	cmd := &dbmodels.Command{
+		ID: 12, Script: "rm -rf build", RiskLevel: dbmodels.RiskLevelHigh, RiskReason: "rm_recursive_force",
+	}
+	assert.Equal(t,
+		"Command #12 is dangerous (rm_recursive_force): rm -rf build\nRun it anyway?",
+		riskConfirmMessage("run", 1, []*dbmodels.Command{cmd}),
+	)
+	assert.Equal(t,
+		"1 of the 3 commands are dangerous (#12)\nCopy them anyway?",
+		riskConfirmMessage("copy", 3, []*dbmodels.Command{cmd}),
+	)
+}
+
+func TestWithRiskConfirmationAborted(t *testing.T) {
+	//nolint:exhaustruct // only fields used by withRiskConfirmation are relevant
+	cmd := &dbmodels.Command{
+		ID: 12, Script: "rm -rf build", RiskLevel: dbmodels.RiskLevelHigh, RiskReason: "rm_recursive_force",
+	}
+	actionCalled := false
+	//nolint:exhaustruct // withRiskConfirmation does not use the list
+	list := &commandsList{}
+	promptCmd := list.withRiskConfirmation("run", func(_ []*dbmodels.Command) tea.Cmd {
+		actionCalled = true
+		return nil
+	})([]*dbmodels.Command{cmd})
+	require.NotNil(t, promptCmd)
+	prompt, ok := promptCmd().(tui.YesNoPromptMsg)
+	require.True(t, ok)
+	prompt.Init()
+
+	prompt.Update(tea.KeyMsg{Type: tea.KeyCtrlC})
+	assert.True(t, prompt.IsCompleted())
+	assert.False(t, actionCalled)
+}
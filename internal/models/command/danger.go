@@ -0,0 +1,108 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+// riskBadge is displayed before the script of the risky commands
+const riskBadge = "[!]"
+
+// withRiskConfirmation asks the user to confirm the action if one of the commands has a high risk level,
+// verb describes the action in the question (eg: run)
+func (m *commandsList) withRiskConfirmation(
+	verb string,
+	action func(rows []*dbmodels.Command) tea.Cmd,
+) func(rows []*dbmodels.Command) tea.Cmd {
+	return func(rows []*dbmodels.Command) tea.Cmd {
+		risky := getCommandsRequiringConfirmation(rows)
+		if len(risky) == 0 {
+			return action(rows)
+		}
+		return tui.ConfirmPrompt(
+			riskConfirmMessage(verb, len(rows), risky),
+			keys.GetFormKeyMap(),
+			func() tea.Cmd {
+				return action(rows)
+			},
+		)
+	}
+}
+
+// getCommandsRequiringConfirmation returns the commands whose risk level requires a confirmation
+func getCommandsRequiringConfirmation(rows []*dbmodels.Command) []*dbmodels.Command {
+	risky := []*dbmodels.Command{}
+	for _, row := range rows {
+		if row.RiskLevel.RequiresConfirmation() {
+			risky = append(risky, row)
+		}
+	}
+	return risky
+}
+
+// riskConfirmMessage asks to confirm the action on the risky commands among count commands
+func riskConfirmMessage(verb string, count int, risky []*dbmodels.Command) string {
+	if count == 1 {
+		const maxCmdDetailsLength = 50
+		return fmt.Sprintf(
+			"Command #%d is dangerous (%s): %s\n%s it anyway?",
+			risky[0].GetID(),
+			risky[0].RiskReason,
+			risky[0].GetSingleLineDescription(maxCmdDetailsLength),
+			capitalize(verb),
+		)
+	}
+	ids := make([]string, 0, len(risky))
+	for _, row := range risky {
+		ids = append(ids, fmt.Sprintf("#%d", row.GetID()))
+	}
+	return fmt.Sprintf(
+		"%d of the %d commands are dangerous (%s)\n%s them anyway?",
+		len(risky), count, strings.Join(ids, ", "), capitalize(verb),
+	)
+}
+
+func capitalize(text string) string {
+	if text == "" {
+		return text
+	}
+	return strings.ToUpper(text[:1]) + text[1:]
+}
+
+// formatRiskBadge returns the badge displayed before the script of the risky commands,
+// empty if the command is not risky
+func formatRiskBadge(level dbmodels.RiskLevel, editorStyle *styles.EditorStyle) string {
+	switch level {
+	case dbmodels.RiskLevelHigh:
+		return editorStyle.StatusError.Render(riskBadge) + " "
+	case dbmodels.RiskLevelMedium:
+		return editorStyle.StatusWarning.Render(riskBadge) + " "
+	case dbmodels.RiskLevelLow:
+		return editorStyle.StatusDisabled.Render(riskBadge) + " "
+	case dbmodels.RiskLevelNone:
+		return ""
+	default:
+		return ""
+	}
+}
+
+// formatRiskLevel returns the styled risk level and the rules giving it, displayed in the editor
+func formatRiskLevel(command *dbmodels.Command, editorStyle *styles.EditorStyle) string {
+	text := fmt.Sprintf("%s (%s)", command.RiskLevel, command.RiskReason)
+	switch command.RiskLevel {
+	case dbmodels.RiskLevelHigh:
+		return editorStyle.StatusError.Render(text)
+	case dbmodels.RiskLevelMedium:
+		return editorStyle.StatusWarning.Render(text)
+	case dbmodels.RiskLevelLow, dbmodels.RiskLevelNone:
+		return editorStyle.ReadonlyValue.Render(text)
+	default:
+		return editorStyle.ReadonlyValue.Render(text)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
@@ -38,13 +39,16 @@ type EditorMaker struct {
 
 // Number of input fields
 const (
-	numInputFields           = 3    // Title, Description, Script
+	numInputFields           = 5    // Title, Description, Script, Tags, Placeholders
 	titleInputMaxSize        = 50   // Max size for title input
 	descriptionInputMaxSize  = 1000 // Max size for description input
 	descriptionInputHeight   = 5    // Height for description input
 	scriptInputHeight        = 5    // Height for script input
+	placeholdersInputHeight  = 3    // Height for placeholders input
 	descriptionWordwrapWidth = 80   // Word wrap width for description input
 	inputFieldPadding        = 2
+	runOutputMaxLines        = 5 // Number of output lines of the last run displayed
+	runDurationPrecision     = 100 * time.Millisecond
 )
 
 // Make creates a new command editor model based on the command ID
@@ -55,6 +59,8 @@ func (mm *EditorMaker) Make(id resource.ID, width, height int) (structure.ChildM
 			AppService:    mm.App.Self(),
 			styles:        mm.Styles,
 			command:       nil,
+			historySource: nil,
+			recentRuns:    []*dbmodels.CommandRun{},
 			width:         width,
 			height:        height,
 			inputs:        make([]inputs.Input, numInputFields),
@@ -63,6 +69,7 @@ func (mm *EditorMaker) Make(id resource.ID, width, height int) (structure.ChildM
 			pagePosition:  0,
 			contentHeight: 0,
 			initialized:   false,
+			tagTitles:     []string{},
 		}
 		mm.commandEditor.Init()
 	}
@@ -80,8 +87,12 @@ type commandEditor struct {
 	*services.AppService
 	styles        *styles.Styles
 	command       *dbmodels.Command
+	historySource *dbmodels.HistorySource
 	EditorKeyMap  *keys.EditorKeyMap
+	// recentRuns are the last runs of the command, most recent first
+	recentRuns    []*dbmodels.CommandRun
 	inputs        []inputs.Input
+	tagTitles     []string
 	width         int
 	height        int
 	focused       int
@@ -113,9 +124,20 @@ func (m *commandEditor) setCommand(command *dbmodels.Command) {
 		return
 	}
 	m.command = command
+	m.historySource = m.HistoryService.GetHistorySource(command)
+	recentRuns, err := m.HistoryService.GetRecentCommandRuns(command.ID)
+	if err != nil {
+		slog.Warn("Unable to load the recent runs of the command", "id", command.ID, "error", err)
+		recentRuns = []*dbmodels.CommandRun{}
+	}
+	m.recentRuns = recentRuns
 	m.inputs[0].SetValue(m.command.Title)
 	m.inputs[1].SetValue(m.command.Description)
 	m.inputs[2].SetValue(m.command.Script)
+	m.inputs[3].SetValue(dbmodels.FormatTags(m.command.Tags))
+	m.inputs[4].SetValue(dbmodels.FormatPlaceholderDefinitions(m.command.Placeholders))
+	// refresh existing tags used for autocompletion
+	m.tagTitles = m.HistoryService.GetTagTitles()
 	m.initInputs()
 }
 
@@ -142,7 +164,21 @@ func (m *commandEditor) Init() tea.Cmd {
 		m.styles.EditorStyle,
 	)
 
-	m.inputs = []inputs.Input{titleInput, descriptionInput, scriptInput}
+	tagsInput := inputs.NewInputWrapper(
+		"Enter tags separated by commas (→ to accept suggestion)",
+		m.styles.EditorStyle,
+		inputs.WithSuggestions(func(value string) []string {
+			return dbmodels.TagSuggestions(value, m.tagTitles)
+		}),
+	)
+
+	placeholdersInput := inputs.NewTextAreaWrapper(
+		placeholdersInputHeight,
+		"One placeholder (<name> or {{name:default}}) per line: name | description | value1, value2",
+		m.styles.EditorStyle,
+	)
+
+	m.inputs = []inputs.Input{titleInput, descriptionInput, scriptInput, tagsInput, placeholdersInput}
 	m.focused = -1
 	m.initialized = true
 
@@ -320,7 +356,7 @@ func (m *commandEditor) addCommonElements(content *strings.Builder) {
 	content.WriteString(helpText + "\n\n")
 
 	// Labels for our fields
-	labels := []string{"Title:", "Description(markdown):", "Script:"}
+	labels := []string{"Title:", "Description(markdown):", "Script:", "Tags:", "Placeholders:"}
 
 	// Render each field with its label
 	for i, label := range labels {
@@ -357,9 +393,67 @@ func (m *commandEditor) addReadonlySection(content *strings.Builder) {
 	// Add the formatted readonly information
 	fmt.Fprintf(content, "%s %s\n", createLabel, createValue)
 	fmt.Fprintf(content, "%s %s\n", modifyLabel, modifyValue)
+	if m.historySource != nil {
+		sourceLabel := m.styles.EditorStyle.ReadonlyLabel.Render("Source:")
+		sourceValue := m.styles.EditorStyle.ReadonlyValue.Render(
+			fmt.Sprintf("%s history (%s)", m.historySource.Format, m.historySource.Path))
+		fmt.Fprintf(content, "%s %s\n", sourceLabel, sourceValue)
+	}
+	if m.command.OccurrenceCount > 0 {
+		occurrencesLabel := m.styles.EditorStyle.ReadonlyLabel.Render("History occurrences:")
+		occurrencesValue := m.styles.EditorStyle.ReadonlyValue.Render(fmt.Sprintf(
+			"%d (first seen %s, last seen %s)",
+			m.command.OccurrenceCount,
+			m.command.FirstSeenDatetime.Format(time.DateTime),
+			m.command.LastSeenDatetime.Format(time.DateTime),
+		))
+		fmt.Fprintf(content, "%s %s\n", occurrencesLabel, occurrencesValue)
+	}
+	if m.command.SecretReason != "" {
+		secretLabel := m.styles.EditorStyle.ReadonlyLabel.Render("Secrets:")
+		secretValue := m.styles.EditorStyle.StatusWarning.Render(m.command.SecretReason)
+		fmt.Fprintf(content, "%s %s\n", secretLabel, secretValue)
+	}
+	if m.command.RiskLevel != dbmodels.RiskLevelNone {
+		riskLabel := m.styles.EditorStyle.ReadonlyLabel.Render("Risk:")
+		fmt.Fprintf(content, "%s %s\n", riskLabel, formatRiskLevel(m.command, m.styles.EditorStyle))
+	}
 	fmt.Fprintf(content, "%s %s\n", lintStatusLabel, m.formatLintStatus())
 
 	m.addLintIssues(content, lintIssuesLabel)
+	m.addRecentRuns(content)
+}
+
+// addRecentRuns adds the last runs of the command to the content,
+// the end of the output of the most recent run is displayed below it
+func (m *commandEditor) addRecentRuns(content *strings.Builder) {
+	editorStyle := m.styles.EditorStyle
+	recentRunsLabel := editorStyle.ReadonlyLabel.Render("Recent Runs:")
+	if len(m.recentRuns) == 0 {
+		fmt.Fprintf(content, "%s %s\n\n", recentRunsLabel, editorStyle.ReadonlyValue.Render("Never run"))
+		return
+	}
+	content.WriteString(recentRunsLabel + "\n")
+	for i, run := range m.recentRuns {
+		fmt.Fprintf(content, "   %s %s %s\n",
+			editorStyle.ReadonlyValue.Render(run.StartDatetime.Format(time.DateTime)),
+			formatRunStatus(run.Status, run.ExitCode, editorStyle),
+			editorStyle.ReadonlyValue.Render(fmt.Sprintf(
+				"in %s (%s)", run.Duration.Round(runDurationPrecision), run.WorkingDirectory,
+			)),
+		)
+		if i > 0 || run.Output == "" {
+			continue
+		}
+		outputLines := strings.Split(strings.TrimSuffix(run.Output, "\n"), "\n")
+		if len(outputLines) > runOutputMaxLines {
+			outputLines = outputLines[len(outputLines)-runOutputMaxLines:]
+		}
+		for _, line := range outputLines {
+			content.WriteString("     " + editorStyle.ReadonlyValue.Render(utils.RemoveAnsiCodes(line)) + "\n")
+		}
+	}
+	content.WriteString("\n")
 }
 
 // addLintIssues adds the lint issues section to the content
@@ -506,7 +600,10 @@ func (m *commandEditor) prevPage() {
 func (m *commandEditor) EditionInProgress() bool {
 	return m.command.Title != m.inputs[0].Value() ||
 		m.command.Description != m.inputs[1].Value() ||
-		m.command.Script != m.inputs[2].Value()
+		m.command.Script != m.inputs[2].Value() ||
+		!slices.Equal(m.command.Tags, dbmodels.ParseTags(m.inputs[3].Value())) ||
+		dbmodels.FormatPlaceholderDefinitions(m.command.Placeholders) !=
+			dbmodels.FormatPlaceholderDefinitions(dbmodels.ParsePlaceholderDefinitions(m.inputs[4].Value()))
 }
 
 // save saves the current command
@@ -515,15 +612,21 @@ func (m *commandEditor) save() tea.Cmd {
 	oldTitle := m.command.Title
 	oldDescription := m.command.Description
 	oldScript := m.command.Script
+	oldTags := m.command.Tags
+	oldPlaceholders := dbmodels.FormatPlaceholderDefinitions(m.command.Placeholders)
 
 	m.command.Title = m.inputs[0].Value()
 	m.command.Description = m.inputs[1].Value()
 	m.command.Script = m.inputs[2].Value()
+	m.command.Tags = dbmodels.ParseTags(m.inputs[3].Value())
+	m.command.Placeholders = dbmodels.ParsePlaceholderDefinitions(m.inputs[4].Value())
 
 	// Only update if there are actual changes
 	if oldTitle != m.command.Title ||
 		oldDescription != m.command.Description ||
-		oldScript != m.command.Script {
+		oldScript != m.command.Script ||
+		!slices.Equal(oldTags, m.command.Tags) ||
+		oldPlaceholders != dbmodels.FormatPlaceholderDefinitions(m.command.Placeholders) {
 		// Update command in database using HistoryService
 		newCommand, err := m.HistoryService.UpdateCommand(m.command)
 		if err != nil {
@@ -566,6 +669,8 @@ func (m *commandEditor) revertChanges() {
 	m.inputs[0].SetValue(m.command.Title)
 	m.inputs[1].SetValue(m.command.Description)
 	m.inputs[2].SetValue(m.command.Script)
+	m.inputs[3].SetValue(dbmodels.FormatTags(m.command.Tags))
+	m.inputs[4].SetValue(dbmodels.FormatPlaceholderDefinitions(m.command.Placeholders))
 }
 
 // BorderText returns text to display in the border
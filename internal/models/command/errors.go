@@ -24,6 +24,33 @@ func (e *ErrRestoreCommand) Error() string {
 	return fmt.Sprintf("failed to restore command: %v", e.Err)
 }
 
+// ErrSaveSuggestion represents an error when saving imported commands fails
+type ErrSaveSuggestion struct {
+	Err error
+}
+
+func (e *ErrSaveSuggestion) Error() string {
+	return fmt.Sprintf("failed to save command: %v", e.Err)
+}
+
+// ErrEditTags represents an error when adding or removing tags fails
+type ErrEditTags struct {
+	Err error
+}
+
+func (e *ErrEditTags) Error() string {
+	return fmt.Sprintf("failed to edit tags: %v", e.Err)
+}
+
+// ErrMoveToFolder represents an error when moving commands to a folder fails
+type ErrMoveToFolder struct {
+	Err error
+}
+
+func (e *ErrMoveToFolder) Error() string {
+	return fmt.Sprintf("failed to move commands to folder: %v", e.Err)
+}
+
 // ErrSelectionMismatch is returned when selection is not compatible with the operation
 type ErrSelectionMismatch struct{}
 
@@ -55,3 +82,30 @@ type ErrCommandLoadingFailure struct {
 func (e *ErrCommandLoadingFailure) Error() string {
 	return fmt.Sprintf("failed to load command with ID %d: %v", e.CommandID, e.Err)
 }
+
+// ErrFillPlaceholders represents an error when retrieving or filling the placeholders of commands
+type ErrFillPlaceholders struct {
+	Err error
+}
+
+func (e *ErrFillPlaceholders) Error() string {
+	return fmt.Sprintf("failed to fill placeholders: %v", e.Err)
+}
+
+// ErrImportSnippets represents an error when importing navi or pet snippets fails
+type ErrImportSnippets struct {
+	Err error
+}
+
+func (e *ErrImportSnippets) Error() string {
+	return fmt.Sprintf("failed to import snippets: %v", e.Err)
+}
+
+// ErrExportFunctions represents an error when generating the shell functions file fails
+type ErrExportFunctions struct {
+	Err error
+}
+
+func (e *ErrExportFunctions) Error() string {
+	return fmt.Sprintf("failed to export shell functions: %v", e.Err)
+}
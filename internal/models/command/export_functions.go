@@ -0,0 +1,45 @@
+package command
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+// defaultFunctionsFilePath is the file proposed to write the shell functions to
+const defaultFunctionsFilePath = "~/.shell-command-bookmarker-functions.sh"
+
+// handleExportFunctions prompts for the file where the selected commands are
+// written as shell functions, the file is overwritten if it exists
+func (m *commandsList) handleExportFunctions() tea.Cmd {
+	rows := m.Model.SelectedOrCurrent()
+	if len(rows) == 0 {
+		return tui.ReportError(&ErrNoCommandsSelected{})
+	}
+	return tui.InputPrompt(
+		fmt.Sprintf("Export %d command(s) as shell functions to file (.zsh extension for zsh):", len(rows)),
+		defaultFunctionsFilePath,
+		func(string) []string {
+			return []string{defaultFunctionsFilePath}
+		},
+		keys.GetFormKeyMap(),
+		func(value string) tea.Cmd {
+			return m.exportFunctions(rows, value)
+		},
+	)
+}
+
+func (m *commandsList) exportFunctions(rows []*dbmodels.Command, filePath string) tea.Cmd {
+	if filePath == "" {
+		filePath = defaultFunctionsFilePath
+	}
+	shellType, err := m.AppService.WriteFunctionsFile(filePath, "auto", "", rows)
+	if err != nil {
+		return tui.ReportError(&ErrExportFunctions{Err: err})
+	}
+	m.Model.DeselectAll()
+	return tui.ReportInfo("%d command(s) exported as %s functions to %s", len(rows), shellType, filePath)
+}
@@ -8,13 +8,26 @@ import (
 	pkgSearch "github.com/fchastanet/shell-command-bookmarker/pkg/search"
 )
 
+// tagFilterPrefix introduces a tag constraint in the filter value (eg: tag:docker)
+const tagFilterPrefix = "tag:"
+
 // matchFilter returns true if the item with the given ID matches the filter
 // value using fuzzy matching.
+// Words of the filter prefixed by "tag:" restrict the match to the commands
+// having all these tags, the remaining words are fuzzy matched.
 func matchFilter(filterValue string, cmd *dbmodels.Command) (matched bool, score int) {
 	if filterValue == "" {
 		return true, 0
 	}
 
+	tags, filterValue := parseFilter(filterValue)
+	if !hasTags(cmd, tags) {
+		return false, 0
+	}
+	if filterValue == "" {
+		return true, pkgSearch.MaxScore
+	}
+
 	// Try exact match first (fastest)
 	// We check the ID as a string, title, description, and script.
 	if cmd.Title == filterValue ||
@@ -25,7 +38,7 @@ func matchFilter(filterValue string, cmd *dbmodels.Command) (matched bool, score
 	}
 
 	// Check if the filter value is a substring of any of the fields
-	col := cmd.Title + " " + cmd.Description + " " + cmd.Script
+	col := cmd.Title + " " + cmd.Description + " " + cmd.Script + " " + strings.Join(cmd.Tags, " ")
 	if strings.Contains(strings.ToLower(col), strings.ToLower(filterValue)) {
 		return true, pkgSearch.MaxScore - 1
 	}
@@ -34,3 +47,34 @@ func matchFilter(filterValue string, cmd *dbmodels.Command) (matched bool, score
 	score = pkgSearch.FuzzyMatchScore(col, filterValue)
 	return score > pkgSearch.ScoreThreshold, score
 }
+
+// hasTags returns true if the command has all the given tags
+func hasTags(cmd *dbmodels.Command, tags []string) bool {
+	for _, tag := range tags {
+		if !cmd.HasTag(tag) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseFilter extracts the tags constraints from the filter value,
+// the remaining words are returned as text
+func parseFilter(filterValue string) (tags []string, text string) {
+	words := strings.Fields(filterValue)
+	textWords := make([]string, 0, len(words))
+	for _, word := range words {
+		if tag, found := strings.CutPrefix(word, tagFilterPrefix); found {
+			if tag != "" {
+				tags = append(tags, tag)
+			}
+			continue
+		}
+		textWords = append(textWords, word)
+	}
+	if len(tags) == 0 {
+		// keep the filter value untouched when no tag is requested
+		return nil, filterValue
+	}
+	return tags, strings.Join(textWords, " ")
+}
@@ -0,0 +1,40 @@
+package command
+
+import (
+	"testing"
+
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	pkgSearch "github.com/fchastanet/shell-command-bookmarker/pkg/search"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchFilter_Tags(t *testing.T) {
+	//nolint:exhaustruct // only fields used by the filter are relevant
+	cmd := &dbmodels.Command{
+		ID:     1,
+		Title:  "list containers",
+		Script: "docker ps -a",
+		Tags:   []string{"docker", "ops"},
+	}
+	tests := []struct {
+		name        string
+		filter      string
+		wantMatched bool
+		wantScore   int
+	}{
+		{name: "single tag", filter: "tag:docker", wantMatched: true, wantScore: pkgSearch.MaxScore},
+		{name: "all tags required", filter: "tag:docker tag:k8s", wantMatched: false, wantScore: 0},
+		{name: "tag and text", filter: "tag:ops containers", wantMatched: true, wantScore: pkgSearch.MaxScore - 1},
+		{name: "tag and unmatched text", filter: "tag:ops zzzzzz", wantMatched: false, wantScore: 0},
+		{name: "tag as text", filter: "ops", wantMatched: true, wantScore: pkgSearch.MaxScore - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matched, score := matchFilter(tt.filter, cmd)
+			assert.Equal(t, tt.wantMatched, matched)
+			if tt.wantMatched {
+				assert.Equal(t, tt.wantScore, score)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui/table"
+)
+
+// handleImportSnippets prompts for a navi cheat file, a directory of cheat files
+// or a pet snippets file whose commands are imported as saved commands
+func (m *commandsList) handleImportSnippets() tea.Cmd {
+	defaultPaths := services.GetDefaultSnippetFilePaths()
+	return tui.InputPrompt(
+		"Import navi cheats (.cheat file or directory) or pet snippets (.toml file):",
+		"~/.local/share/navi/cheats",
+		func(string) []string {
+			return defaultPaths
+		},
+		keys.GetFormKeyMap(),
+		func(value string) tea.Cmd {
+			return m.importSnippets(value)
+		},
+	)
+}
+
+// importSnippets imports the snippets in background, commands already existing are skipped.
+// The details of each imported file are logged.
+func (m *commandsList) importSnippets(path string) tea.Cmd {
+	format, err := services.GetSnippetFileFormat(path)
+	if err != nil {
+		return tui.ReportError(&ErrImportSnippets{Err: err})
+	}
+	bookmarkFileService := m.AppService.BookmarkFileService
+	var selectRowID resource.ID = -1
+	if row, ok := m.Model.CurrentRow(); ok {
+		selectRowID = row.GetID()
+	}
+	return func() tea.Msg {
+		reports, err := bookmarkFileService.ImportSnippetFiles(path, format, services.ImportOptions{
+			OnConflict: services.ImportConflictSkip,
+			DryRun:     false,
+			Lint:       true,
+		})
+		if err != nil {
+			return tui.ErrorMsg(&ErrImportSnippets{Err: err})
+		}
+		counts := map[services.ImportAction]int{}
+		for _, report := range reports {
+			for _, result := range report.Results {
+				counts[result.Action]++
+			}
+		}
+		infoMsg := tui.InfoMsg(fmt.Sprintf(
+			"%d command(s) imported from %d file(s), %d already existing, %d skipped, %d invalid",
+			counts[services.ImportActionCreated], len(reports), counts[services.ImportActionAlreadyExists],
+			counts[services.ImportActionSkipped], counts[services.ImportActionInvalid],
+		))
+		return table.ReloadMsg[*dbmodels.Command]{
+			RowID:   selectRowID,
+			InfoMsg: &infoMsg,
+		}
+	}
+}
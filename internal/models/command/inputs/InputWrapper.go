@@ -2,6 +2,7 @@ package inputs
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/lipgloss"
@@ -11,11 +12,14 @@ import (
 
 // InputWrapper wraps textinput.Model to implement the Input interface
 type InputWrapper struct {
-	Model    *textinput.Model
-	style    InputWrapperStyle
-	readOnly bool
+	Model           *textinput.Model
+	style           InputWrapperStyle
+	suggestionsFunc func(value string) []string
+	readOnly        bool
 }
 
+type InputWrapperOption func(*InputWrapper)
+
 type InputWrapperStyle interface {
 	GetInputWrapperWarningStyle() *lipgloss.Style
 }
@@ -23,13 +27,37 @@ type InputWrapperStyle interface {
 func NewInputWrapper(
 	placeHolder string,
 	style InputWrapperStyle,
+	options ...InputWrapperOption,
 ) *InputWrapper {
 	textInput := textinput.New()
 	textInput.Placeholder = placeHolder
-	return &InputWrapper{
-		Model:    &textInput,
-		readOnly: false,
-		style:    style,
+	wrapper := &InputWrapper{
+		Model:           &textInput,
+		readOnly:        false,
+		style:           style,
+		suggestionsFunc: nil,
+	}
+	for _, opt := range options {
+		opt(wrapper)
+	}
+	return wrapper
+}
+
+// WithSuggestions enables autocompletion, suggestionsFunc computes the
+// suggestions from the current value.
+// As tab is used to navigate between fields, suggestions are accepted using
+// the right arrow key.
+func WithSuggestions(suggestionsFunc func(value string) []string) InputWrapperOption {
+	return func(w *InputWrapper) {
+		w.suggestionsFunc = suggestionsFunc
+		w.Model.ShowSuggestions = true
+		w.Model.KeyMap.AcceptSuggestion.SetKeys("right")
+	}
+}
+
+func (w *InputWrapper) updateSuggestions() {
+	if w.suggestionsFunc != nil {
+		w.Model.SetSuggestions(w.suggestionsFunc(w.Model.Value()))
 	}
 }
 
@@ -44,6 +72,7 @@ func (w *InputWrapper) Update(msg tea.Msg) (Input, tea.Cmd) {
 	}
 	newModel, cmd := w.Model.Update(msg)
 	w.Model = &newModel
+	w.updateSuggestions()
 	return w, cmd
 }
 
@@ -64,6 +93,7 @@ func (w *InputWrapper) Blur() {
 }
 
 func (w *InputWrapper) Focus() tea.Cmd {
+	w.updateSuggestions()
 	return w.Model.Focus()
 }
 
@@ -81,7 +111,7 @@ func (w *InputWrapper) View() string {
 	}
 	txt := w.Model.View()
 	if !w.readOnly && w.Model.CharLimit > 0 {
-		length := len(w.Model.Value())
+		length := utf8.RuneCountInString(w.Model.Value())
 		availSpace := w.Model.CharLimit - length
 		if availSpace <= 0 {
 			warningMsg := w.style.GetInputWrapperWarningStyle().Render("No more characters can be added, limit reached.")
@@ -0,0 +1,62 @@
+package command
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+// withFilledPlaceholders asks the user for the values of the placeholders of the commands,
+// the action receives copies of the commands with their placeholders replaced by the values.
+// The action is directly invoked if the commands do not have any placeholder.
+func (m *commandsList) withFilledPlaceholders(
+	rows []*dbmodels.Command,
+	action func(rows []*dbmodels.Command) tea.Cmd,
+) tea.Cmd {
+	placeholders, err := m.HistoryService.GetPlaceholders(rows)
+	if err != nil {
+		return tui.ReportError(&ErrFillPlaceholders{Err: err})
+	}
+	if len(placeholders) == 0 {
+		return action(rows)
+	}
+
+	values := make(map[string]*string, len(placeholders))
+	fields := make([]huh.Field, 0, len(placeholders))
+	for _, placeholder := range placeholders {
+		value := placeholder.GetInitialValue()
+		values[placeholder.Name] = &value
+		fields = append(fields, newPlaceholderField(placeholder, &value))
+	}
+	form := huh.NewForm(huh.NewGroup(fields...))
+	return tui.FormPrompt(form, keys.GetFormKeyMap(), func() tea.Cmd {
+		filledValues := make(map[string]string, len(values))
+		for name, value := range values {
+			filledValues[name] = *value
+		}
+		filledRows, err := m.HistoryService.FillPlaceholders(rows, filledValues)
+		if err != nil {
+			return tui.ReportError(&ErrFillPlaceholders{Err: err})
+		}
+		return action(filledRows)
+	})
+}
+
+// newPlaceholderField creates a select field when the placeholder has allowed values,
+// an input field otherwise
+func newPlaceholderField(placeholder *dbmodels.Placeholder, value *string) huh.Field {
+	if len(placeholder.AllowedValues) > 0 {
+		return huh.NewSelect[string]().
+			Title(placeholder.Name).
+			Description(placeholder.Description).
+			Options(huh.NewOptions(placeholder.AllowedValues...)...).
+			Value(value)
+	}
+	return huh.NewInput().
+		Title(placeholder.Name).
+		Description(placeholder.Description).
+		Placeholder(placeholder.DefaultValue).
+		Value(value)
+}
@@ -2,7 +2,9 @@ package command
 
 import (
 	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
@@ -16,6 +18,8 @@ func compareBySortField(i, j *models.Command, field structure.Field) int {
 		return sort.CompareID(i, j)
 	case structure.FieldTitle:
 		return strings.Compare(i.Title, j.Title)
+	case structure.FieldTags:
+		return slices.Compare(i.Tags, j.Tags)
 	case structure.FieldFilterScore:
 		return sort.CompareInt(i.FilterScore, j.FilterScore)
 	case structure.FieldScript:
@@ -28,6 +32,17 @@ func compareBySortField(i, j *models.Command, field structure.Field) int {
 		return sort.CompareTime(i.CreationDatetime, j.CreationDatetime)
 	case structure.FieldModificationDate:
 		return sort.CompareTime(i.ModificationDatetime, j.ModificationDatetime)
+	case structure.FieldFrecency:
+		return sort.CompareFrecency(i.UseCount, i.LastUsedDatetime, j.UseCount, j.LastUsedDatetime, time.Now())
+	case structure.FieldOccurrences:
+		// the history occurrences are ranked like the uses
+		return sort.CompareFrecency(
+			i.OccurrenceCount, i.LastSeenDatetime, j.OccurrenceCount, j.LastSeenDatetime, time.Now(),
+		)
+	case structure.FieldLastRunStatus:
+		return strings.Compare(string(i.LastRunStatus), string(j.LastRunStatus))
+	case structure.FieldLastRun:
+		return sort.CompareTime(i.LastRunDatetime, j.LastRunDatetime)
 	default:
 		slog.Warn("Unknown sort field", "field", field)
 		return 0
@@ -0,0 +1,14 @@
+package folder
+
+import "fmt"
+
+// ErrFolderAction represents an error when creating, renaming, moving or deleting a folder
+type ErrFolderAction struct {
+	Err    error
+	Action string
+	Path   string
+}
+
+func (e *ErrFolderAction) Error() string {
+	return fmt.Sprintf("failed to %s folder '%s': %v", e.Action, e.Path, e.Err)
+}
@@ -0,0 +1,400 @@
+package folder
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+)
+
+const (
+	rootNodeTitle  = "All commands"
+	indentation    = "  "
+	expandedIcon   = "▾ "
+	collapsedIcon  = "▸ "
+	leafIcon       = "  "
+	rootFolderPath = ""
+)
+
+type TreeMaker struct {
+	App    services.AppServiceInterface
+	Styles *styles.Styles
+	KeyMap *keys.FolderTreeKeyMap
+}
+
+func (mm *TreeMaker) Make(_ resource.ID, width, height int) (structure.ChildModel, error) {
+	return &tree{
+		historyService: mm.App.GetHistoryService(),
+		styles:         mm.Styles,
+		keyMap:         mm.KeyMap,
+		folders:        []*dbmodels.Folder{},
+		commandCounts:  map[resource.ID]int{},
+		collapsed:      map[resource.ID]bool{},
+		current:        dbmodels.RootFolderID,
+		selected:       dbmodels.RootFolderID,
+		offset:         0,
+		width:          width,
+		height:         height,
+	}, nil
+}
+
+// tree displays the folders as a collapsible tree, the root node
+// allows to display the commands of all the folders
+type tree struct {
+	historyService *services.HistoryService
+	styles         *styles.Styles
+	keyMap         *keys.FolderTreeKeyMap
+	// folders sorted depth first
+	folders []*dbmodels.Folder
+	// commandCounts is the number of commands of each folder including its sub folders
+	commandCounts map[resource.ID]int
+	collapsed     map[resource.ID]bool
+	// current is the folder under the cursor
+	current resource.ID
+	// selected is the folder used to filter the command list
+	selected resource.ID
+	// offset is the index of the first visible node
+	offset int
+	width  int
+	height int
+}
+
+func (m *tree) Init() tea.Cmd {
+	m.loadFolders()
+	return nil
+}
+
+func (*tree) BeforeSwitchPane() tea.Cmd {
+	return nil
+}
+
+func (m *tree) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.scrollToCurrent()
+	case structure.FocusedPaneChangedMsg:
+		if msg.To == structure.LeftPane {
+			// commands could have been moved from the command list
+			m.loadFolders()
+		}
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	}
+	return nil
+}
+
+//nolint:cyclop // not really complex
+func (m *tree) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	keyMap := m.keyMap
+	keys.UpdateFolderTreeBindings(keyMap, m.current == dbmodels.RootFolderID)
+	switch {
+	case tui.CheckKey(msg, keyMap.Up):
+		m.moveCursor(-1)
+	case tui.CheckKey(msg, keyMap.Down):
+		m.moveCursor(1)
+	case tui.CheckKey(msg, keyMap.Expand):
+		delete(m.collapsed, m.current)
+	case tui.CheckKey(msg, keyMap.Collapse):
+		m.handleCollapse()
+	case tui.CheckKey(msg, keyMap.Select):
+		return m.selectFolder(m.current)
+	case tui.CheckKey(msg, keyMap.Create):
+		return m.handleCreate()
+	case tui.CheckKey(msg, keyMap.Rename):
+		return m.handleRename()
+	case tui.CheckKey(msg, keyMap.Move):
+		return m.handleMove()
+	case tui.CheckKey(msg, keyMap.Delete):
+		return m.handleDelete()
+	default:
+		return nil
+	}
+	m.scrollToCurrent()
+	return tui.GetDummyCmd()
+}
+
+// loadFolders retrieves the folders from the database,
+// current and selected folders are reset to the root node if they do not exist anymore
+func (m *tree) loadFolders() {
+	folders, err := m.historyService.GetFolders()
+	if err != nil {
+		slog.Error("Error loading folders", "error", err)
+		return
+	}
+	m.folders = folders
+	m.commandCounts = make(map[resource.ID]int, len(folders))
+	// folders are sorted depth first, so children are counted before their parent
+	for i := len(folders) - 1; i >= 0; i-- {
+		folder := folders[i]
+		m.commandCounts[folder.ID] += folder.CommandCount
+		if folder.ParentID != dbmodels.RootFolderID {
+			m.commandCounts[folder.ParentID] += m.commandCounts[folder.ID]
+		}
+	}
+	if m.findFolder(m.current) == nil {
+		m.current = dbmodels.RootFolderID
+	}
+	if m.findFolder(m.selected) == nil {
+		m.selected = dbmodels.RootFolderID
+	}
+	m.scrollToCurrent()
+}
+
+func (m *tree) findFolder(folderID resource.ID) *dbmodels.Folder {
+	for _, folder := range m.folders {
+		if folder.ID == folderID {
+			return folder
+		}
+	}
+	return nil
+}
+
+func (m *tree) hasChildren(folderID resource.ID) bool {
+	for _, folder := range m.folders {
+		if folder.ParentID == folderID {
+			return true
+		}
+	}
+	return false
+}
+
+// visibleNodes returns the ids of the nodes that are not hidden by a collapsed parent,
+// the first node is the root node
+func (m *tree) visibleNodes() []resource.ID {
+	nodes := []resource.ID{dbmodels.RootFolderID}
+	hiddenBelowDepth := -1
+	for _, folder := range m.folders {
+		if hiddenBelowDepth >= 0 && folder.Depth > hiddenBelowDepth {
+			continue
+		}
+		hiddenBelowDepth = -1
+		nodes = append(nodes, folder.ID)
+		if m.collapsed[folder.ID] {
+			hiddenBelowDepth = folder.Depth
+		}
+	}
+	return nodes
+}
+
+func (m *tree) currentIndex(nodes []resource.ID) int {
+	for i, id := range nodes {
+		if id == m.current {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *tree) moveCursor(delta int) {
+	nodes := m.visibleNodes()
+	index := max(0, min(len(nodes)-1, m.currentIndex(nodes)+delta))
+	m.current = nodes[index]
+}
+
+// handleCollapse collapses the current folder or moves to its parent
+// if the folder is already collapsed or has no sub folder
+func (m *tree) handleCollapse() {
+	folder := m.findFolder(m.current)
+	if folder == nil {
+		return
+	}
+	if !m.collapsed[folder.ID] && m.hasChildren(folder.ID) {
+		m.collapsed[folder.ID] = true
+		return
+	}
+	m.current = folder.ParentID
+}
+
+func (m *tree) scrollToCurrent() {
+	if m.height <= 0 {
+		return
+	}
+	index := m.currentIndex(m.visibleNodes())
+	if index < m.offset {
+		m.offset = index
+	} else if index >= m.offset+m.height {
+		m.offset = index - m.height + 1
+	}
+}
+
+func (m *tree) getFolderPath(folderID resource.ID) string {
+	if folder := m.findFolder(folderID); folder != nil {
+		return folder.Path
+	}
+	return rootFolderPath
+}
+
+// selectFolder filters the command list using the folder and its sub folders
+func (m *tree) selectFolder(folderID resource.ID) tea.Cmd {
+	m.selected = folderID
+	msg := structure.FolderSelectedMsg{
+		Path:      rootFolderPath,
+		FolderIDs: []resource.ID{},
+	}
+	if folder := m.findFolder(folderID); folder != nil {
+		msg.Path = folder.Path
+		msg.FolderIDs = dbmodels.GetFolderSubtreeIDs(m.folders, folder.ID)
+	}
+	return tui.CmdHandler(msg)
+}
+
+// reloadAfterChange reloads the folders and refreshes the command list
+// as the selected folder could have been changed
+func (m *tree) reloadAfterChange(info string) tea.Cmd {
+	m.loadFolders()
+	return tea.Batch(
+		m.selectFolder(m.selected),
+		tui.CmdHandler(tui.InfoMsg(info)),
+	)
+}
+
+func (m *tree) pathSuggestions(value string) []string {
+	return dbmodels.FolderPathSuggestions(value, m.folders)
+}
+
+func (m *tree) handleCreate() tea.Cmd {
+	parentPath := m.getFolderPath(m.current)
+	prompt := "New folder (use / to create sub folders):"
+	if parentPath != rootFolderPath {
+		prompt = fmt.Sprintf("New folder in '%s' (use / to create sub folders):", parentPath)
+	}
+	return tui.InputPrompt(
+		prompt,
+		"folder/sub-folder",
+		nil,
+		keys.GetFormKeyMap(),
+		func(value string) tea.Cmd {
+			path := value
+			if parentPath != rootFolderPath {
+				path = parentPath + dbmodels.FolderPathSeparator + value
+			}
+			folder, err := m.historyService.CreateFolderPath(path)
+			if err != nil {
+				return tui.ReportError(&ErrFolderAction{Action: "create", Path: path, Err: err})
+			}
+			if folder == nil {
+				return nil
+			}
+			m.current = folder.ID
+			return m.reloadAfterChange(fmt.Sprintf("Folder '%s' created", folder.Path))
+		},
+	)
+}
+
+func (m *tree) handleRename() tea.Cmd {
+	folder := m.findFolder(m.current)
+	if folder == nil {
+		return nil
+	}
+	return tui.InputPrompt(
+		fmt.Sprintf("Rename folder '%s' to:", folder.Path),
+		folder.Title,
+		nil,
+		keys.GetFormKeyMap(),
+		func(value string) tea.Cmd {
+			if err := m.historyService.RenameFolder(folder.ID, value); err != nil {
+				return tui.ReportError(&ErrFolderAction{Action: "rename", Path: folder.Path, Err: err})
+			}
+			return m.reloadAfterChange(fmt.Sprintf("Folder '%s' renamed", folder.Path))
+		},
+	)
+}
+
+func (m *tree) handleMove() tea.Cmd {
+	folder := m.findFolder(m.current)
+	if folder == nil {
+		return nil
+	}
+	return tui.InputPrompt(
+		fmt.Sprintf("Move folder '%s' into (empty for top level):", folder.Path),
+		"folder/sub-folder",
+		m.pathSuggestions,
+		keys.GetFormKeyMap(),
+		func(value string) tea.Cmd {
+			if err := m.historyService.MoveFolderToPath(folder.ID, value); err != nil {
+				return tui.ReportError(&ErrFolderAction{Action: "move", Path: folder.Path, Err: err})
+			}
+			return m.reloadAfterChange(fmt.Sprintf("Folder '%s' moved", folder.Path))
+		},
+	)
+}
+
+func (m *tree) handleDelete() tea.Cmd {
+	folder := m.findFolder(m.current)
+	if folder == nil {
+		return nil
+	}
+	return tui.YesNoPrompt(
+		fmt.Sprintf(
+			"Delete folder '%s' and its sub folders? Their commands will be moved to the parent folder",
+			folder.Path,
+		),
+		keys.GetFormKeyMap(),
+		func() tea.Cmd {
+			if err := m.historyService.DeleteFolder(folder.ID); err != nil {
+				return tui.ReportError(&ErrFolderAction{Action: "delete", Path: folder.Path, Err: err})
+			}
+			m.current = folder.ParentID
+			return m.reloadAfterChange(fmt.Sprintf("Folder '%s' deleted", folder.Path))
+		},
+	)
+}
+
+func (m *tree) View() string {
+	nodes := m.visibleNodes()
+	end := len(nodes)
+	if m.height > 0 {
+		end = min(end, m.offset+m.height)
+	}
+	lines := make([]string, 0, end-m.offset)
+	for _, id := range nodes[m.offset:end] {
+		lines = append(lines, m.renderNode(id))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m *tree) renderNode(folderID resource.ID) string {
+	var line string
+	if folder := m.findFolder(folderID); folder != nil {
+		icon := leafIcon
+		if m.hasChildren(folder.ID) {
+			icon = expandedIcon
+			if m.collapsed[folder.ID] {
+				icon = collapsedIcon
+			}
+		}
+		line = strings.Repeat(indentation, folder.Depth+1) + icon + folder.Title +
+			m.styles.EditorStyle.ReadonlyValue.Render(fmt.Sprintf(" (%d)", m.commandCounts[folder.ID]))
+	} else {
+		line = rootNodeTitle
+	}
+
+	tableStyle := m.styles.TableStyle
+	style := tableStyle.GetTableCellStyle()
+	switch {
+	case folderID == m.current && folderID == m.selected:
+		style = tableStyle.GetTableCurrentAndSelectedRowStyle()
+	case folderID == m.current:
+		style = tableStyle.GetTableCurrentRowStyle()
+	case folderID == m.selected:
+		style = tableStyle.GetTableSelectedRowStyle()
+	}
+	return style.Width(max(m.width, 0)).MaxWidth(max(m.width, 0)).Render(line)
+}
+
+func (m *tree) HelpBindings() []*key.Binding {
+	keys.UpdateFolderTreeBindings(m.keyMap, m.current == dbmodels.RootFolderID)
+	return keys.KeyMapToSlice(*m.keyMap)
+}
@@ -0,0 +1,76 @@
+package keys
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type FolderTreeKeyMap struct {
+	Up       *key.Binding
+	Down     *key.Binding
+	Expand   *key.Binding
+	Collapse *key.Binding
+	Select   *key.Binding
+	Create   *key.Binding
+	Rename   *key.Binding
+	Move     *key.Binding
+	Delete   *key.Binding
+}
+
+func GetFolderTreeKeyMap() *FolderTreeKeyMap {
+	up := key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("⬆/k", "previous folder"),
+	)
+	down := key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("⬇/j", "next folder"),
+	)
+	expand := key.NewBinding(
+		key.WithKeys("right", "l"),
+		key.WithHelp("⮕/l", "expand folder"),
+	)
+	collapse := key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("⬅", "collapse folder"),
+	)
+	selectFolder := key.NewBinding(
+		key.WithKeys("enter", " "),
+		key.WithHelp("⏎/space", "filter commands by folder"),
+	)
+	create := key.NewBinding(
+		key.WithKeys("n"),
+		key.WithHelp("n", "new sub folder"),
+	)
+	rename := key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "rename folder"),
+	)
+	move := key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "move folder"),
+	)
+	deleteFolder := key.NewBinding(
+		key.WithKeys("delete", "d"),
+		key.WithHelp("Del/d", "delete folder"),
+	)
+
+	return &FolderTreeKeyMap{
+		Up:       &up,
+		Down:     &down,
+		Expand:   &expand,
+		Collapse: &collapse,
+		Select:   &selectFolder,
+		Create:   &create,
+		Rename:   &rename,
+		Move:     &move,
+		Delete:   &deleteFolder,
+	}
+}
+
+// UpdateFolderTreeBindings enables the actions that need a folder
+// when the current node is not the root node
+func UpdateFolderTreeBindings(folderKeyMap *FolderTreeKeyMap, isRootNode bool) {
+	folderKeyMap.Rename.SetEnabled(!isRootNode)
+	folderKeyMap.Move.SetEnabled(!isRootNode)
+	folderKeyMap.Delete.SetEnabled(!isRootNode)
+}
@@ -0,0 +1,38 @@
+package keys
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type RevisionKeyMap struct {
+	Up     *key.Binding
+	Down   *key.Binding
+	Revert *key.Binding
+}
+
+func GetRevisionKeyMap() *RevisionKeyMap {
+	up := key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("⬆/k", "newer revision"),
+	)
+	down := key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("⬇/j", "older revision"),
+	)
+	revert := key.NewBinding(
+		key.WithKeys("r"),
+		key.WithHelp("r", "revert to revision"),
+	)
+
+	return &RevisionKeyMap{
+		Up:     &up,
+		Down:   &down,
+		Revert: &revert,
+	}
+}
+
+// UpdateRevisionBindings enables the revert action when a revision
+// of an editable command is under the cursor
+func UpdateRevisionBindings(revisionKeyMap *RevisionKeyMap, hasRevision bool, isEditable bool) {
+	revisionKeyMap.Revert.SetEnabled(hasRevision && isEditable)
+}
@@ -11,6 +11,13 @@ type TableCustomActionKeyMap struct {
 	CopyToClipboard *key.Binding
 	SelectForShell  *key.Binding
 	RestoreCommand  *key.Binding
+	EditTags        *key.Binding
+	MoveToFolder    *key.Binding
+	ShowRevisions   *key.Binding
+	ImportSnippets  *key.Binding
+	ExportFunctions *key.Binding
+	SaveSuggestion  *key.Binding
+	RunCommand      *key.Binding
 }
 
 func GetTableCustomActionKeyMap() *TableCustomActionKeyMap {
@@ -30,12 +37,53 @@ func GetTableCustomActionKeyMap() *TableCustomActionKeyMap {
 		key.WithKeys("r"),
 		key.WithHelp("r", "restore command"),
 	)
+	editTags := key.NewBinding(
+		key.WithKeys("t"),
+		key.WithHelp("t", "add/remove tags"),
+	)
+
+	moveToFolder := key.NewBinding(
+		key.WithKeys("m"),
+		key.WithHelp("m", "move to folder"),
+	)
+
+	showRevisions := key.NewBinding(
+		key.WithKeys("v"),
+		key.WithHelp("v", "show revisions"),
+	)
+
+	importSnippets := key.NewBinding(
+		key.WithKeys("i"),
+		key.WithHelp("i", "import navi/pet snippets"),
+	)
+
+	exportFunctions := key.NewBinding(
+		key.WithKeys("f"),
+		key.WithHelp("f", "export as shell functions"),
+	)
+
+	saveSuggestion := key.NewBinding(
+		key.WithKeys("b"),
+		key.WithHelp("b", "bookmark imported command"),
+	)
+
+	runCommand := key.NewBinding(
+		key.WithKeys("x"),
+		key.WithHelp("x", "run command"),
+	)
 
 	return &TableCustomActionKeyMap{
 		ComposeCommand:  &composeCommand,
 		CopyToClipboard: &copyToClipboard,
 		SelectForShell:  &selectForShell,
 		RestoreCommand:  &restoreCommand,
+		EditTags:        &editTags,
+		MoveToFolder:    &moveToFolder,
+		ShowRevisions:   &showRevisions,
+		ImportSnippets:  &importSnippets,
+		ExportFunctions: &exportFunctions,
+		SaveSuggestion:  &saveSuggestion,
+		RunCommand:      &runCommand,
 	}
 }
 
@@ -70,6 +118,29 @@ func UpdateBindings(
 			selectedCommand != nil &&
 			selectedCommand.Status == dbmodels.CommandStatusDeleted,
 	)
+	tableCustomActions.EditTags.SetEnabled(
+		!shellSelectionMode && selectedCommand != nil && selectedCommand.IsEditable(),
+	)
+	tableCustomActions.MoveToFolder.SetEnabled(
+		!shellSelectionMode && selectedCommand != nil && selectedCommand.IsEditable(),
+	)
+	tableCustomActions.ShowRevisions.SetEnabled(
+		!shellSelectionMode && selectedCommand != nil,
+	)
+	tableCustomActions.ImportSnippets.SetEnabled(!shellSelectionMode)
+	tableCustomActions.ExportFunctions.SetEnabled(
+		!shellSelectionMode && selectedCommand != nil,
+	)
+	tableCustomActions.SaveSuggestion.SetEnabled(
+		!shellSelectionMode &&
+			selectedCommand != nil &&
+			selectedCommand.Status == dbmodels.CommandStatusImported,
+	)
+	tableCustomActions.RunCommand.SetEnabled(
+		!shellSelectionMode &&
+			selectedCommand != nil &&
+			selectedCommand.Status != dbmodels.CommandStatusDeleted,
+	)
 	tableActions.Delete.SetEnabled(
 		!shellSelectionMode &&
 			selectedCommand != nil &&
@@ -0,0 +1,38 @@
+package keys
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+)
+
+type TaskKeyMap struct {
+	Up     *key.Binding
+	Down   *key.Binding
+	Cancel *key.Binding
+}
+
+func GetTaskKeyMap() *TaskKeyMap {
+	up := key.NewBinding(
+		key.WithKeys("up", "k"),
+		key.WithHelp("⬆/k", "previous task"),
+	)
+	down := key.NewBinding(
+		key.WithKeys("down", "j"),
+		key.WithHelp("⬇/j", "next task"),
+	)
+	cancel := key.NewBinding(
+		key.WithKeys("c"),
+		key.WithHelp("c", "cancel task"),
+	)
+
+	return &TaskKeyMap{
+		Up:     &up,
+		Down:   &down,
+		Cancel: &cancel,
+	}
+}
+
+// UpdateTaskBindings enables the cancel action when the task under the cursor
+// is queued or running
+func UpdateTaskBindings(taskKeyMap *TaskKeyMap, isCancelable bool) {
+	taskKeyMap.Cancel.SetEnabled(isCancelable)
+}
@@ -131,11 +131,19 @@ func (p *PaneManager) SetMakerFactory(makerFactory func(kind resource.Kind) Make
 }
 
 func (p *PaneManager) Init() tea.Cmd {
-	return p.setPane(structure.NavigationMsg{
-		Position:     structure.TopPane,
-		Page:         structure.Page{Kind: structure.CommandListKind, ID: 0},
-		DisableFocus: false,
-	})
+	return tea.Batch(
+		p.setPane(structure.NavigationMsg{
+			Position:     structure.TopPane,
+			Page:         structure.Page{Kind: structure.CommandListKind, ID: 0},
+			DisableFocus: false,
+		}),
+		// The folder tree is displayed in the left pane without taking the focus
+		p.setPane(structure.NavigationMsg{
+			Position:     structure.LeftPane,
+			Page:         structure.Page{Kind: structure.FolderKind, ID: 0},
+			DisableFocus: true,
+		}),
+	)
 }
 
 func (p *PaneManager) Update(msg tea.Msg) tea.Cmd {
@@ -186,10 +194,14 @@ func (p *PaneManager) handleSpecialMessages(msg tea.Msg) (tea.Cmd, bool) {
 	case structure.NavigationMsg:
 		return p.setPane(msg), true
 	case table.RowDefaultActionMsg[*models.Command]:
-		return p.setBottomPane(msg.RowID, true), true
+		return p.setBottomPane(structure.CommandEditorKind, msg.RowID, true), true
+	case structure.TasksUpdatedMsg:
+		return p.updateTaskPanes(msg), true
 	case table.RowSelectedActionMsg[*models.Command]:
-		if _, ok := p.panes[structure.BottomPane]; ok {
-			cmd := p.setBottomPane(msg.RowID, false)
+		if bottomPane, ok := p.panes[structure.BottomPane]; ok && bottomPane.page.Kind != structure.TaskKind {
+			// the bottom pane keeps displaying the same kind of page (editor or revisions),
+			// the tasks do not depend on the selected row
+			cmd := p.setBottomPane(bottomPane.page.Kind, msg.RowID, false)
 			return cmd, cmd != nil
 		}
 	case command.EditorCancelledMsg:
@@ -201,6 +213,17 @@ func (p *PaneManager) handleSpecialMessages(msg tea.Msg) (tea.Cmd, bool) {
 	return nil, false
 }
 
+// updateTaskPanes sends the message to the panes displaying the tasks, whether they are focused or not
+func (p *PaneManager) updateTaskPanes(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+	for position, pane := range p.panes {
+		if pane.page.Kind == structure.TaskKind {
+			cmds = append(cmds, p.updateModel(position, msg))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
 // updateUnfocusedPanes sends messages to all panes except the focused one
 func (p *PaneManager) updateUnfocusedPanes(msg tea.Msg) []tea.Cmd {
 	var cmds []tea.Cmd
@@ -297,12 +320,12 @@ func (p *PaneManager) handleFocusPane(position structure.Position) tea.Cmd {
 	return p.focusPane(position)
 }
 
-func (p *PaneManager) setBottomPane(rowID resource.ID, focusIfSameRowID bool) tea.Cmd {
-	// Handle row default action by opening the editor in the bottom right pane
+func (p *PaneManager) setBottomPane(kind resource.Kind, rowID resource.ID, focusIfSameRowID bool) tea.Cmd {
+	// Open the page of the given kind (editor or revisions) for the row in the bottom right pane
 	bottomPane := p.panes[structure.BottomPane]
-	if bottomPane.page.ID == rowID {
+	if bottomPane.page.Kind == kind && bottomPane.page.ID == rowID {
 		var cmd tea.Cmd
-		// The bottom right pane is already showing the editor for this command
+		// The bottom right pane is already showing this page for this command
 		// so just bring it into focus.
 		if focusIfSameRowID {
 			cmd = p.focusPane(structure.BottomPane)
@@ -312,7 +335,7 @@ func (p *PaneManager) setBottomPane(rowID resource.ID, focusIfSameRowID bool) te
 	return p.setPane(
 		structure.NavigationMsg{
 			Page: structure.Page{
-				Kind: structure.CommandEditorKind,
+				Kind: kind,
 				ID:   rowID,
 			},
 			Position:     structure.BottomPane,
@@ -0,0 +1,28 @@
+package revision
+
+import (
+	"fmt"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// ErrLoadRevisions represents an error when loading the revisions of a command
+type ErrLoadRevisions struct {
+	Err       error
+	CommandID resource.ID
+}
+
+func (e *ErrLoadRevisions) Error() string {
+	return fmt.Sprintf("failed to load revisions of command %d: %v", e.CommandID, e.Err)
+}
+
+// ErrRevertCommand represents an error when reverting a command to one of its revisions
+type ErrRevertCommand struct {
+	Err        error
+	CommandID  resource.ID
+	RevisionID resource.ID
+}
+
+func (e *ErrRevertCommand) Error() string {
+	return fmt.Sprintf("failed to revert command %d to revision %d: %v", e.CommandID, e.RevisionID, e.Err)
+}
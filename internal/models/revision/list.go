@@ -0,0 +1,267 @@
+package revision
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/diff"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui/table"
+)
+
+const (
+	descriptionMaxChars = 50
+	// the revisions list uses at most a third of the pane, the diff uses the rest
+	listHeightDivisor  = 3
+	minListHeight      = 3
+	headerHeight       = 2 // title and separator
+	moreLinesIndicator = "…"
+)
+
+type ListMaker struct {
+	App    services.AppServiceInterface
+	Styles *styles.Styles
+	KeyMap *keys.RevisionKeyMap
+}
+
+func (mm *ListMaker) Make(id resource.ID, width, height int) (structure.ChildModel, error) {
+	return &list{
+		historyService: mm.App.GetHistoryService(),
+		styles:         mm.Styles,
+		keyMap:         mm.KeyMap,
+		commandID:      id,
+		command:        nil,
+		revisions:      []*dbmodels.CommandRevision{},
+		current:        0,
+		offset:         0,
+		width:          width,
+		height:         height,
+	}, nil
+}
+
+// list displays the revisions of a command, the changes made since the
+// revision under the cursor are displayed below the list
+type list struct {
+	historyService *services.HistoryService
+	styles         *styles.Styles
+	keyMap         *keys.RevisionKeyMap
+	command        *dbmodels.Command
+	// revisions sorted from the most recent one
+	revisions []*dbmodels.CommandRevision
+	commandID resource.ID
+	// current is the index of the revision under the cursor
+	current int
+	// offset is the index of the first visible revision
+	offset int
+	width  int
+	height int
+}
+
+func (m *list) Init() tea.Cmd {
+	return m.loadRevisions(m.commandID)
+}
+
+func (*list) BeforeSwitchPane() tea.Cmd {
+	return nil
+}
+
+func (m *list) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.scrollToCurrent()
+	case structure.NavigationMsg:
+		return m.loadRevisions(msg.Page.ID)
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	}
+	return nil
+}
+
+func (m *list) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	m.updateBindings()
+	switch {
+	case tui.CheckKey(msg, m.keyMap.Up):
+		m.current = max(0, m.current-1)
+	case tui.CheckKey(msg, m.keyMap.Down):
+		m.current = max(0, min(len(m.revisions)-1, m.current+1))
+	case tui.CheckKey(msg, m.keyMap.Revert):
+		return m.handleRevert()
+	default:
+		return nil
+	}
+	m.scrollToCurrent()
+	return tui.GetDummyCmd()
+}
+
+func (m *list) updateBindings() {
+	keys.UpdateRevisionBindings(
+		m.keyMap,
+		m.currentRevision() != nil,
+		m.command != nil && m.command.IsEditable(),
+	)
+}
+
+// loadRevisions retrieves the command and its revisions,
+// the command replacing an obsolete command is loaded instead of it
+func (m *list) loadRevisions(commandID resource.ID) tea.Cmd {
+	command, revisions, err := m.historyService.GetCommandRevisions(commandID)
+	if err != nil {
+		slog.Error("Error loading command revisions", "id", commandID, "error", err)
+		return tui.ReportError(&ErrLoadRevisions{CommandID: commandID, Err: err})
+	}
+	if commandID != m.commandID || m.command == nil || command.ID != m.command.ID {
+		m.current = 0
+		m.offset = 0
+	}
+	m.commandID = commandID
+	m.command = command
+	m.revisions = revisions
+	m.current = max(0, min(len(m.revisions)-1, m.current))
+	m.scrollToCurrent()
+	return nil
+}
+
+func (m *list) currentRevision() *dbmodels.CommandRevision {
+	if m.current < 0 || m.current >= len(m.revisions) {
+		return nil
+	}
+	return m.revisions[m.current]
+}
+
+func (m *list) handleRevert() tea.Cmd {
+	revision := m.currentRevision()
+	if revision == nil || m.command == nil {
+		return nil
+	}
+	command, err := m.historyService.RevertCommand(m.command.ID, revision.ID)
+	if err != nil {
+		return tui.ReportError(&ErrRevertCommand{
+			CommandID:  m.command.ID,
+			RevisionID: revision.ID,
+			Err:        err,
+		})
+	}
+	if cmd := m.loadRevisions(m.commandID); cmd != nil {
+		return cmd
+	}
+	infoMsg := tui.InfoMsg(fmt.Sprintf(
+		"Command #%d reverted to its version of %s", command.ID, revision.CreationDatetime.Format(time.DateTime),
+	))
+	return tui.CmdHandler(table.ReloadMsg[*dbmodels.Command]{
+		RowID:   command.ID,
+		InfoMsg: &infoMsg,
+	})
+}
+
+// listHeight returns the number of revisions displayed at once
+func (m *list) listHeight() int {
+	return max(minListHeight, (m.height-headerHeight)/listHeightDivisor)
+}
+
+func (m *list) scrollToCurrent() {
+	listHeight := m.listHeight()
+	if m.current < m.offset {
+		m.offset = m.current
+	} else if m.current >= m.offset+listHeight {
+		m.offset = m.current - listHeight + 1
+	}
+}
+
+func (m *list) View() string {
+	if m.command == nil {
+		return ""
+	}
+	width := max(m.width, 0)
+	editorStyle := m.styles.EditorStyle
+	lines := []string{
+		editorStyle.Title.Render(fmt.Sprintf(
+			"Revisions of command #%d - %s",
+			m.command.ID, m.command.GetSingleLineDescription(descriptionMaxChars),
+		)),
+	}
+	if len(m.revisions) == 0 {
+		lines = append(lines, editorStyle.ReadonlyValue.Render(
+			"No revision yet, a revision is recorded each time the command is saved",
+		))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	end := min(len(m.revisions), m.offset+m.listHeight())
+	for i := m.offset; i < end; i++ {
+		lines = append(lines, m.renderRevision(i))
+	}
+	lines = append(lines, strings.Repeat("─", width))
+	diffHeight := max(0, m.height-len(lines))
+	lines = append(lines, m.renderDiff(diffHeight)...)
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m *list) renderRevision(index int) string {
+	revision := m.revisions[index]
+	description := revision.Title
+	if description == "" {
+		description = strings.SplitN(revision.Script, "\n", 2)[0] //nolint:mnd // first line only
+	}
+	line := fmt.Sprintf(
+		"%s  %s",
+		revision.CreationDatetime.Format(time.DateTime),
+		description,
+	)
+	style := m.styles.TableStyle.GetTableCellStyle()
+	if index == m.current {
+		style = m.styles.TableStyle.GetTableCurrentRowStyle()
+	}
+	width := max(m.width, 0)
+	return style.Width(width).MaxWidth(width).Render(line)
+}
+
+// renderDiff renders the changes made since the revision under the cursor
+func (m *list) renderDiff(height int) []string {
+	revision := m.currentRevision()
+	if revision == nil || height <= 0 {
+		return []string{}
+	}
+	editorStyle := m.styles.EditorStyle
+	diffLines := diff.Lines(revision.GetText(), m.command.GetRevisionText())
+	if !diff.HasChanges(diffLines) {
+		return []string{editorStyle.ReadonlyLabel.Render("No change since this revision")}
+	}
+	lines := []string{editorStyle.ReadonlyLabel.Render("Changes made since this revision:")}
+	width := max(m.width, 0)
+	for i, diffLine := range diffLines {
+		if len(lines) == height-1 && i < len(diffLines)-1 {
+			lines = append(lines, moreLinesIndicator)
+			break
+		}
+		text := diffLine.Prefix() + " " + diffLine.Text
+		switch diffLine.Operation {
+		case diff.OperationInsert:
+			text = editorStyle.StatusOK.MaxWidth(width).Render(text)
+		case diff.OperationDelete:
+			text = editorStyle.StatusError.MaxWidth(width).Render(text)
+		case diff.OperationEqual:
+			text = lipgloss.NewStyle().MaxWidth(width).Render(text)
+		}
+		lines = append(lines, text)
+	}
+	return lines
+}
+
+func (m *list) HelpBindings() []*key.Binding {
+	m.updateBindings()
+	return keys.KeyMapToSlice(*m.keyMap)
+}
@@ -6,10 +6,15 @@ const (
 	// Fields
 	FieldID               Field = "ID"
 	FieldTitle            Field = "Title"
+	FieldTags             Field = "Tags"
 	FieldScript           Field = "Script"
 	FieldStatus           Field = "Status"
 	FieldLintStatus       Field = "Lint Status"
 	FieldCreationDate     Field = "Creation Date"
 	FieldModificationDate Field = "Modification Date"
 	FieldFilterScore      Field = "Score"
+	FieldFrecency         Field = "Frecency"
+	FieldOccurrences      Field = "Occurrences"
+	FieldLastRunStatus    Field = "Last Status"
+	FieldLastRun          Field = "Last Run"
 )
@@ -18,4 +18,5 @@ var (
 	TaskKind          = KindType{key: "task"}
 	FolderKind        = KindType{key: "folder"}
 	SearchKind        = KindType{key: "search"}
+	RevisionKind      = KindType{key: "revision"}
 )
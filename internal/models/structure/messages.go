@@ -51,3 +51,14 @@ type FocusedPaneChangedMsg struct {
 type CommandSelectedForShellMsg struct {
 	Command string
 }
+
+// FolderSelectedMsg is sent when a folder is selected in the folder tree,
+// the command list only displays the commands of the folder and its sub folders.
+// An empty FolderIDs means all the commands.
+type FolderSelectedMsg struct {
+	Path      string
+	FolderIDs []resource.ID
+}
+
+// TasksUpdatedMsg is sent when the status or the output of the running tasks has changed
+type TasksUpdatedMsg struct{}
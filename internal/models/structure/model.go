@@ -32,6 +32,9 @@ type KeyMaps struct {
 	TableAction       *table.Action
 	TableCustomAction *keys.TableCustomActionKeyMap
 	Editor            *keys.EditorKeyMap
+	FolderTree        *keys.FolderTreeKeyMap
+	Revision          *keys.RevisionKeyMap
+	Task              *keys.TaskKeyMap
 	Form              *huh.KeyMap
 }
 
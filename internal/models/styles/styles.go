@@ -12,15 +12,17 @@ import (
 )
 
 type Styles struct {
-	TableStyle     table.StyleInterface
-	PaneStyle      *PaneStyle
-	PlaceHolder    *lipgloss.Style
-	HelpStyle      *HelpStyle
-	FooterStyle    *FooterStyle
-	HeaderStyle    *HeaderStyle
-	WindowStyle    *WindowStyle
-	EditorStyle    *EditorStyle
-	ScrollbarStyle *tui.ScrollbarStyle
+	TableStyle  table.StyleInterface
+	PaneStyle   *PaneStyle
+	PlaceHolder *lipgloss.Style
+	// SearchHighlight is the style of the terms matching the filter
+	SearchHighlight *lipgloss.Style
+	HelpStyle       *HelpStyle
+	FooterStyle     *FooterStyle
+	HeaderStyle     *HeaderStyle
+	WindowStyle     *WindowStyle
+	EditorStyle     *EditorStyle
+	ScrollbarStyle  *tui.ScrollbarStyle
 	// ColorTheme is the color theme used in the application.
 	ColorTheme        *ColorTheme
 	CategoryTabStyles tabs.CategoryTabStylesInterface
@@ -137,8 +139,10 @@ func (*EditorStyle) GetTextAreaWrapperWarningStyle() *lipgloss.Style {
 }
 
 type HeaderStyle struct {
-	Main   *lipgloss.Style
-	Title  lipgloss.Style
+	Main  *lipgloss.Style
+	Title lipgloss.Style
+	// Status displays the background tasks in progress at the right of the title
+	Status lipgloss.Style
 	Height int
 }
 
@@ -154,6 +158,7 @@ func NewStyles() *Styles {
 		ScrollbarStyle:    nil,
 		ColorTheme:        nil,
 		PlaceHolder:       nil,
+		SearchHighlight:   nil,
 		CategoryTabStyles: nil,
 		SortStyles:        nil,
 	}
@@ -271,6 +276,9 @@ func (s *Styles) initBaseStyles(colorTheme *ColorTheme) {
 	placeHolder := lipgloss.NewStyle().Faint(true)
 	s.PlaceHolder = &placeHolder
 
+	searchHighlight := lipgloss.NewStyle().Bold(true).Foreground(colors.Yellow)
+	s.SearchHighlight = &searchHighlight
+
 	// Initialize footer style
 	footerDefaultStyle := padded.Foreground(colors.Black).Background(colors.EvenLighterGrey)
 	footerErrorStyle := regular.Padding(0, PaddingSmall).
@@ -298,10 +306,16 @@ func (s *Styles) initBaseStyles(colorTheme *ColorTheme) {
 		Foreground(colors.White).
 		Background(lipgloss.Color("#000080")) // Navy blue background
 
+	statusStyle := lipgloss.NewStyle().
+		Padding(0, PaddingSmall).
+		Foreground(colors.Black).
+		Background(colors.LightGreen)
+
 	s.HeaderStyle = &HeaderStyle{
 		Height: HeightHeader,
 		Main:   &headerStyle,
 		Title:  titleStyle,
+		Status: statusStyle,
 	}
 }
 
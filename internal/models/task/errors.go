@@ -0,0 +1,16 @@
+package task
+
+import (
+	"fmt"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// ErrCancelTask represents an error when canceling a task that is already finished
+type ErrCancelTask struct {
+	TaskID resource.ID
+}
+
+func (e *ErrCancelTask) Error() string {
+	return fmt.Sprintf("failed to cancel task %d: task is not running", e.TaskID)
+}
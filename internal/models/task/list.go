@@ -0,0 +1,283 @@
+package task
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/keys"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/utils"
+)
+
+const (
+	// the tasks list uses at most a third of the pane, the output uses the rest
+	listHeightDivisor = 3
+	minListHeight     = 3
+	durationPrecision = 100 * time.Millisecond
+	tabSpaces         = "    "
+)
+
+type ListMaker struct {
+	App    services.AppServiceInterface
+	Styles *styles.Styles
+	KeyMap *keys.TaskKeyMap
+}
+
+func (mm *ListMaker) Make(_ resource.ID, width, height int) (structure.ChildModel, error) {
+	return &list{
+		taskService: mm.App.GetTaskService(),
+		styles:      mm.Styles,
+		keyMap:      mm.KeyMap,
+		tasks:       []*task.Task{},
+		currentID:   0,
+		follow:      true,
+		offset:      0,
+		width:       width,
+		height:      height,
+	}, nil
+}
+
+// list displays the tasks run since the start of the application,
+// the output of the task under the cursor is displayed below the list
+type list struct {
+	taskService *services.TaskService
+	styles      *styles.Styles
+	keyMap      *keys.TaskKeyMap
+	// tasks sorted from the oldest one
+	tasks []*task.Task
+	// currentID is the ID of the task under the cursor
+	currentID resource.ID
+	// follow moves the cursor to the newest task each time a task is added
+	follow bool
+	// offset is the index of the first visible task
+	offset int
+	width  int
+	height int
+}
+
+func (m *list) Init() tea.Cmd {
+	m.loadTasks()
+	return nil
+}
+
+func (*list) BeforeSwitchPane() tea.Cmd {
+	return nil
+}
+
+func (m *list) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		m.scrollToCurrent()
+	case structure.NavigationMsg:
+		// new tasks have been run, the newest one is displayed
+		m.follow = true
+		m.loadTasks()
+	case structure.TasksUpdatedMsg:
+		m.loadTasks()
+	case tea.KeyMsg:
+		return m.handleKeyMsg(msg)
+	}
+	return nil
+}
+
+func (m *list) handleKeyMsg(msg tea.KeyMsg) tea.Cmd {
+	m.updateBindings()
+	current := m.currentIndex()
+	switch {
+	case tui.CheckKey(msg, m.keyMap.Up):
+		m.setCurrent(current - 1)
+	case tui.CheckKey(msg, m.keyMap.Down):
+		m.setCurrent(current + 1)
+	case tui.CheckKey(msg, m.keyMap.Cancel):
+		return m.handleCancel()
+	default:
+		return nil
+	}
+	m.scrollToCurrent()
+	return tui.GetDummyCmd()
+}
+
+func (m *list) updateBindings() {
+	currentTask := m.currentTask()
+	keys.UpdateTaskBindings(m.keyMap, currentTask != nil && !currentTask.Status().IsFinal())
+}
+
+// loadTasks retrieves the tasks, the cursor stays on the same task unless the newest one is followed
+func (m *list) loadTasks() {
+	m.tasks = m.taskService.GetTasks()
+	if m.follow || m.currentIndex() < 0 {
+		m.setCurrent(len(m.tasks) - 1)
+	}
+	m.scrollToCurrent()
+}
+
+// setCurrent moves the cursor to the task at the given index,
+// the newest task is followed when the cursor is on it
+func (m *list) setCurrent(index int) {
+	if len(m.tasks) == 0 {
+		m.currentID = 0
+		return
+	}
+	index = max(0, min(len(m.tasks)-1, index))
+	m.currentID = m.tasks[index].ID
+	m.follow = index == len(m.tasks)-1
+}
+
+func (m *list) currentIndex() int {
+	for i, t := range m.tasks {
+		if t.ID == m.currentID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m *list) currentTask() *task.Task {
+	if index := m.currentIndex(); index >= 0 {
+		return m.tasks[index]
+	}
+	return nil
+}
+
+func (m *list) handleCancel() tea.Cmd {
+	currentTask := m.currentTask()
+	if currentTask == nil {
+		return nil
+	}
+	if !m.taskService.CancelTask(currentTask.ID) {
+		return tui.ReportError(&ErrCancelTask{TaskID: currentTask.ID})
+	}
+	return tui.ReportInfo("Task #%d canceled", currentTask.ID)
+}
+
+// listHeight returns the number of tasks displayed at once
+func (m *list) listHeight() int {
+	return max(minListHeight, m.height/listHeightDivisor)
+}
+
+func (m *list) scrollToCurrent() {
+	current := max(0, m.currentIndex())
+	listHeight := m.listHeight()
+	if current < m.offset {
+		m.offset = current
+	} else if current >= m.offset+listHeight {
+		m.offset = current - listHeight + 1
+	}
+}
+
+func (m *list) View() string {
+	editorStyle := m.styles.EditorStyle
+	lines := []string{editorStyle.Title.Render("Tasks")}
+	if len(m.tasks) == 0 {
+		lines = append(lines, editorStyle.ReadonlyValue.Render(
+			"No task yet, the commands run from the command list are displayed here",
+		))
+		return lipgloss.JoinVertical(lipgloss.Left, lines...)
+	}
+
+	current := m.currentIndex()
+	end := min(len(m.tasks), m.offset+m.listHeight())
+	for i := m.offset; i < end; i++ {
+		lines = append(lines, m.renderTask(m.tasks[i], i == current))
+	}
+	lines = append(lines, strings.Repeat("─", max(m.width, 0)))
+	outputHeight := max(0, m.height-len(lines))
+	lines = append(lines, m.renderOutput(m.currentTask(), outputHeight)...)
+
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (m *list) renderTask(t *task.Task, isCurrent bool) string {
+	exitCode := "-"
+	if t.Status() == task.Exited {
+		exitCode = strconv.Itoa(t.ExitCode())
+	}
+	line := fmt.Sprintf(
+		"#%-4d %-8s %4s %9s  %s",
+		t.ID, t.Status(), exitCode, t.Duration().Round(durationPrecision), t.Name,
+	)
+	style := m.styles.TableStyle.GetTableCellStyle()
+	if isCurrent {
+		style = m.styles.TableStyle.GetTableCurrentRowStyle()
+	}
+	width := max(m.width, 0)
+	return style.Width(width).MaxWidth(width).Render(sanitizeLine(line))
+}
+
+// renderOutput renders the last lines of the output of the task
+func (m *list) renderOutput(t *task.Task, height int) []string {
+	if t == nil || height <= 0 {
+		return []string{}
+	}
+	editorStyle := m.styles.EditorStyle
+	output, truncated := t.Output()
+	label := fmt.Sprintf("Output of task #%d:", t.ID)
+	if truncated {
+		label = fmt.Sprintf("Output of task #%d (beginning dropped):", t.ID)
+	}
+	lines := []string{editorStyle.ReadonlyLabel.Render(label)}
+	if err := t.Err(); err != nil {
+		lines = append(lines, editorStyle.StatusError.Render(sanitizeLine(err.Error())))
+	}
+	width := max(m.width, 0)
+	for _, line := range lastLines(output, height-len(lines)) {
+		lines = append(lines, utils.TruncateWidth(sanitizeLine(line), width, ""))
+	}
+	return lines
+}
+
+// lastLines returns at most the n last lines of s, the final line feed is ignored
+func lastLines(s string, n int) []string {
+	s = strings.TrimSuffix(s, "\n")
+	if s == "" || n <= 0 {
+		return []string{}
+	}
+	start := len(s)
+	for range n {
+		index := strings.LastIndexByte(s[:start], '\n')
+		if index < 0 {
+			start = 0
+			break
+		}
+		start = index
+	}
+	if start > 0 {
+		start++
+	}
+	return strings.Split(s[start:], "\n")
+}
+
+// sanitizeLine keeps the text displayed by a terminal after the last carriage return (eg: progress bars),
+// the escape sequences and the control characters that would break the layout are removed
+func sanitizeLine(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	if index := strings.LastIndexByte(line, '\r'); index >= 0 {
+		line = line[index+1:]
+	}
+	line = strings.ReplaceAll(ansi.Strip(line), "\t", tabSpaces)
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, line)
+}
+
+func (m *list) HelpBindings() []*key.Binding {
+	m.updateBindings()
+	return keys.KeyMapToSlice(*m.keyMap)
+}
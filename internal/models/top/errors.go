@@ -0,0 +1,18 @@
+package top
+
+import (
+	"fmt"
+)
+
+// ErrHistoryIngestion represents an error when importing the shell history files fails
+type ErrHistoryIngestion struct {
+	Err error
+}
+
+func (e *ErrHistoryIngestion) Error() string {
+	return fmt.Sprintf("failed to import history: %v", e.Err)
+}
+
+func (e *ErrHistoryIngestion) Unwrap() error {
+	return e.Err
+}
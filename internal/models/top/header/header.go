@@ -1,6 +1,7 @@
 package header
 
 import (
+	"github.com/charmbracelet/lipgloss"
 	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
 )
 
@@ -8,6 +9,8 @@ import (
 type Model struct {
 	styles *styles.Styles
 	title  string
+	// status describes the background task in progress, empty if none
+	status string
 	width  int
 }
 
@@ -17,6 +20,7 @@ func New(myStyles *styles.Styles, title string) Model {
 		width:  0,
 		styles: myStyles,
 		title:  title,
+		status: "",
 	}
 }
 
@@ -35,7 +39,20 @@ func (m *Model) SetWidth(width int) {
 	m.width = width
 }
 
+// SetStatus updates the status displayed at the right of the title, empty to hide it
+func (m *Model) SetStatus(status string) {
+	m.status = status
+}
+
 // View renders the header component
 func (m *Model) View() string {
-	return m.styles.HeaderStyle.Title.Width(m.width).Render(m.title)
+	if m.status == "" {
+		return m.styles.HeaderStyle.Title.Width(m.width).Render(m.title)
+	}
+	status := m.styles.HeaderStyle.Status.MaxWidth(m.width).Render(m.status)
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		m.styles.HeaderStyle.Title.Width(max(0, m.width-lipgloss.Width(status))).Render(m.title),
+		status,
+	)
 }
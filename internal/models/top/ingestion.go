@@ -0,0 +1,80 @@
+package top
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui/table"
+)
+
+// HistoryIngestionMsg is a report of the history ingestion running in background
+type HistoryIngestionMsg struct {
+	reports <-chan services.HistoryIngestionReport
+	Report  services.HistoryIngestionReport
+}
+
+// waitForHistoryIngestion returns a command waiting for the next report of the history ingestion,
+// nil if no ingestion is running
+func waitForHistoryIngestion(reports <-chan services.HistoryIngestionReport) tea.Cmd {
+	if reports == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		report, ok := <-reports
+		if !ok {
+			return nil
+		}
+		return HistoryIngestionMsg{Report: report, reports: reports}
+	}
+}
+
+// handleHistoryIngestion displays the progress of the history ingestion in the header,
+// once finished the summary is reported and the commands and category counts are reloaded.
+// The next reports are the ones of the commands appended to the watched history files,
+// the reload keeps the current row and filter of the list.
+func (m *Model) handleHistoryIngestion(msg HistoryIngestionMsg) tea.Cmd {
+	if !msg.Report.Finished {
+		m.ingestionProgress = msg.Report.Progress()
+		m.updateHeaderStatus()
+		cmds := []tea.Cmd{waitForHistoryIngestion(msg.reports)}
+		if !m.spinning {
+			m.spinning = true
+			cmds = append(cmds, m.spinner.Tick)
+		}
+		return tea.Batch(cmds...)
+	}
+
+	m.ingestionProgress = ""
+	m.spinning = false
+	m.updateHeaderStatus()
+	cmds := []tea.Cmd{waitForHistoryIngestion(msg.reports)}
+	summary := tui.InfoMsg(msg.Report.String())
+	if msg.Report.ImportedCount > 0 {
+		cmds = append(cmds, tui.CmdHandler(table.ReloadMsg[*dbmodels.Command]{
+			RowID:   -1,
+			InfoMsg: &summary,
+		}))
+	} else {
+		cmds = append(cmds, tui.CmdHandler(summary))
+	}
+	if msg.Report.Err != nil {
+		// the error replaces the summary once it has been displayed
+		err := &ErrHistoryIngestion{Err: msg.Report.Err}
+		cmds = append(cmds, tea.Tick(messageDisplayDuration, func(time.Time) tea.Msg {
+			return tui.ErrorMsg(err)
+		}))
+	}
+	return tea.Batch(cmds...)
+}
+
+// updateHeaderStatus displays the spinner and the progress of the history ingestion in the header
+func (m *Model) updateHeaderStatus() {
+	if m.ingestionProgress == "" {
+		m.headerModel.SetStatus("")
+		return
+	}
+	m.headerModel.SetStatus(m.spinner.View() + " " + m.ingestionProgress)
+}
@@ -5,8 +5,11 @@ import (
 
 	"github.com/fchastanet/shell-command-bookmarker/internal/models"
 	"github.com/fchastanet/shell-command-bookmarker/internal/models/command"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/folder"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/revision"
 	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
 	"github.com/fchastanet/shell-command-bookmarker/internal/models/styles"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/task"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/tui/table"
@@ -47,6 +50,21 @@ func NewMakerFactory(
 		Styles:       myStyles,
 		EditorKeyMap: keyMaps.Editor,
 	}
+	makers[structure.FolderKind] = &folder.TreeMaker{
+		App:    app.Self(),
+		Styles: myStyles,
+		KeyMap: keyMaps.FolderTree,
+	}
+	makers[structure.RevisionKind] = &revision.ListMaker{
+		App:    app.Self(),
+		Styles: myStyles,
+		KeyMap: keyMaps.Revision,
+	}
+	makers[structure.TaskKind] = &task.ListMaker{
+		App:    app.Self(),
+		Styles: myStyles,
+		KeyMap: keyMaps.Task,
+	}
 	return func(kind resource.Kind) models.Maker {
 		maker, ok := makers[kind]
 		if !ok {
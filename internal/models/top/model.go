@@ -75,9 +75,18 @@ type Model struct {
 	height int
 	mode   structure.Mode
 
+	// ingestionProgress describes the history ingestion in progress, empty if none
+	ingestionProgress string
+
 	// Whether the spinner is currently active
 	spinning bool
 
+	// Whether the task pane is refreshed periodically as tasks are running
+	refreshingTasks bool
+
+	// recordedRuns is the number of task runs recorded when the commands have been reloaded
+	recordedRuns int64
+
 	// Performance monitoring state
 	perfMonitorActive bool
 
@@ -102,6 +111,9 @@ func NewModel(
 		TableNavigation:   keys.GetTableNavigationKeyMap(),
 		TableAction:       keys.GetTableActionKeyMap(),
 		TableCustomAction: keys.GetTableCustomActionKeyMap(),
+		FolderTree:        keys.GetFolderTreeKeyMap(),
+		Revision:          keys.GetRevisionKeyMap(),
+		Task:              keys.GetTaskKeyMap(),
 		Form:              keys.GetFormKeyMap(),
 	}
 
@@ -133,6 +145,9 @@ func NewModel(
 		height:            0,
 		mode:              structure.NormalMode,
 		spinning:          false,
+		refreshingTasks:   false,
+		recordedRuns:      0,
+		ingestionProgress: "",
 		prompt:            nil,
 		messageClearTime:  time.Time{},
 		perfMonitorActive: false,
@@ -149,6 +164,8 @@ func (m *Model) Init() tea.Cmd {
 	return models.SafeCmd(tea.Batch(
 		m.helpModel.Init(),
 		m.PaneManager.Init(),
+		waitForHistoryIngestion(m.appService.GetHistoryIngestionReports()),
+		waitForTaskUpdates(m.getTaskUpdates()),
 	))
 }
 
@@ -186,6 +203,12 @@ func (m *Model) handleCommandMsg(msg tea.Msg) (tea.Cmd, bool) {
 		return m.handleQuitClearScreenMsg(), true
 	case spinner.TickMsg:
 		return m.handleSpinnerTick(msg), true
+	case HistoryIngestionMsg:
+		return m.handleHistoryIngestion(msg), true
+	case TaskUpdateMsg:
+		return m.handleTaskUpdate(msg), true
+	case taskRefreshTickMsg:
+		return m.handleTaskRefreshTick(), true
 	case tui.YesNoPromptMsg:
 		return m.handleYesNoPrompt(msg), true
 	case tui.ErrorMsg, tui.InfoMsg, MessageClearTickMsg:
@@ -224,7 +247,8 @@ func (m *Model) handleQuitClearScreenMsg() tea.Cmd {
 func (m *Model) handlePromptMode(msg tea.Msg) []tea.Cmd {
 	gKeys := m.keyMaps.Global
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		if tui.CheckKey(keyMsg, gKeys.Help) {
+		// printable keys (eg: h) are typed in the prompt inputs
+		if (keyMsg.Type != tea.KeyRunes || keyMsg.Alt) && tui.CheckKey(keyMsg, gKeys.Help) {
 			return m.displayHelp()
 		}
 	}
@@ -287,6 +311,7 @@ func (m *Model) handlePerformanceMessages(msg tea.Msg) tea.Cmd {
 func (m *Model) handleSpinnerTick(msg spinner.TickMsg) tea.Cmd {
 	var cmd tea.Cmd
 	*m.spinner, cmd = m.spinner.Update(msg)
+	m.updateHeaderStatus()
 	if m.spinning {
 		// Continue spinning spinner.
 		return cmd
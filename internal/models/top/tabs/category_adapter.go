@@ -10,12 +10,16 @@ import (
 )
 
 const (
+	// FrequentCommands represents available commands, most frequently and recently used first
+	FrequentCommands category.Type = iota
 	// AvailableCommands represents commands that are available for use
-	AvailableCommands category.Type = iota
+	AvailableCommands
 	// SavedCommands represents commands that have been saved
 	SavedCommands
 	// NewCommands represents commands that have been imported but not yet saved
 	NewCommands
+	// SuggestedCommands represents imported commands run often, suggested to be saved
+	SuggestedCommands
 	// DeletedCommands represents commands that have been marked as deleted
 	DeletedCommands
 	// AllCommands represents all commands regardless of status
@@ -52,12 +56,17 @@ func (ca *CategoryAdapter) GetCategoryTabs(
 	sortFields := []string{
 		structure.FieldID,
 		structure.FieldTitle,
+		structure.FieldTags,
 		structure.FieldScript,
 		structure.FieldStatus,
 		structure.FieldLintStatus,
 		structure.FieldCreationDate,
 		structure.FieldModificationDate,
 		structure.FieldFilterScore,
+		structure.FieldFrecency,
+		structure.FieldOccurrences,
+		structure.FieldLastRunStatus,
+		structure.FieldLastRun,
 	}
 
 	// Create a function that returns a new sort state for each tab
@@ -77,11 +86,36 @@ func (ca *CategoryAdapter) GetCategoryTabs(
 		return sortState
 	}
 
+	// Most used commands first, then the best matches of the filter if any
+	createFrecencySortState := func() *sort.State[*dbmodels.Command, string] {
+		sortState := createNewSortState()
+		sortState.PrimarySort.Field = structure.FieldFrecency
+		sortState.SecondarySort.Field = structure.FieldFilterScore
+		sortState.SecondarySort.Direction = sort.DirectionDesc
+		return sortState
+	}
+
+	// Commands found the most often and recently in the history files first
+	createOccurrencesSortState := func() *sort.State[*dbmodels.Command, string] {
+		sortState := createFrecencySortState()
+		sortState.PrimarySort.Field = structure.FieldOccurrences
+		return sortState
+	}
+
 	return []pkgTabs.CategoryTab[
 		*dbmodels.Command,
 		dbmodels.CommandStatus,
 		string,
 	]{
+		newCategoryTab(
+			"Frequent",
+			createFrecencySortState(),
+			FrequentCommands,
+			[]dbmodels.CommandStatus{
+				dbmodels.CommandStatusSaved,
+				dbmodels.CommandStatusImported,
+			},
+		),
 		newCategoryTab(
 			"Available",
 			createNewSortState(),
@@ -107,6 +141,14 @@ func (ca *CategoryAdapter) GetCategoryTabs(
 				dbmodels.CommandStatusImported,
 			},
 		),
+		newCategoryTab(
+			"Suggestions",
+			createOccurrencesSortState(),
+			SuggestedCommands,
+			[]dbmodels.CommandStatus{
+				dbmodels.CommandStatusImported,
+			},
+		),
 		newCategoryTab(
 			"Deleted",
 			createNewSortState(),
@@ -173,9 +215,11 @@ func (ca *CategoryAdapter) GetCategoryCounts() (map[category.Type]int, error) {
 
 	// Map service categories to UI categories
 	uiCounts := make(map[category.Type]int)
+	uiCounts[FrequentCommands] = serviceCounts[services.CommandCategoryFrequent]
 	uiCounts[AvailableCommands] = serviceCounts[services.CommandCategoryAvailable]
 	uiCounts[SavedCommands] = serviceCounts[services.CommandCategorySaved]
 	uiCounts[NewCommands] = serviceCounts[services.CommandCategoryNew]
+	uiCounts[SuggestedCommands] = serviceCounts[services.CommandCategorySuggested]
 	uiCounts[DeletedCommands] = serviceCounts[services.CommandCategoryDeleted]
 	uiCounts[AllCommands] = serviceCounts[services.CommandCategoryAll]
 
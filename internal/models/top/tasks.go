@@ -0,0 +1,83 @@
+package top
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/fchastanet/shell-command-bookmarker/internal/models/structure"
+	dbmodels "github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/tui/table"
+)
+
+// taskRefreshInterval is the interval the duration of the running tasks is refreshed at
+const taskRefreshInterval = time.Second
+
+// TaskUpdateMsg signals that the status or the output of a task has changed
+type TaskUpdateMsg struct {
+	updates <-chan struct{}
+}
+
+// taskRefreshTickMsg refreshes the task pane while tasks are running, even if they do not output anything
+type taskRefreshTickMsg struct{}
+
+// waitForTaskUpdates returns a command waiting for the next change of the tasks,
+// nil if no task can be run
+func waitForTaskUpdates(updates <-chan struct{}) tea.Cmd {
+	if updates == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		if _, ok := <-updates; !ok {
+			return nil
+		}
+		return TaskUpdateMsg{updates: updates}
+	}
+}
+
+// getTaskUpdates returns the channel signaled when the tasks change, nil if there is no task service
+func (m *Model) getTaskUpdates() <-chan struct{} {
+	if m.appService.TaskService == nil {
+		return nil
+	}
+	return m.appService.TaskService.GetTaskUpdates()
+}
+
+// handleTaskUpdate refreshes the task pane and waits for the next change,
+// the refresh is repeated while tasks are running to update their duration.
+// The commands are reloaded when runs have been recorded to display their last run.
+func (m *Model) handleTaskUpdate(msg TaskUpdateMsg) tea.Cmd {
+	cmds := []tea.Cmd{
+		waitForTaskUpdates(msg.updates),
+		m.PaneManager.Update(structure.TasksUpdatedMsg{}),
+	}
+	if recordedRuns := m.appService.TaskService.GetRecordedRunCount(); recordedRuns != m.recordedRuns {
+		m.recordedRuns = recordedRuns
+		cmds = append(cmds, tui.CmdHandler(table.ReloadMsg[*dbmodels.Command]{
+			RowID:   -1,
+			InfoMsg: nil,
+		}))
+	}
+	if !m.refreshingTasks {
+		m.refreshingTasks = true
+		cmds = append(cmds, scheduleTaskRefresh())
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m *Model) handleTaskRefreshTick() tea.Cmd {
+	if !m.appService.TaskService.HasRunningTasks() {
+		m.refreshingTasks = false
+		return nil
+	}
+	return tea.Batch(
+		m.PaneManager.Update(structure.TasksUpdatedMsg{}),
+		scheduleTaskRefresh(),
+	)
+}
+
+func scheduleTaskRefresh() tea.Cmd {
+	return tea.Tick(taskRefreshInterval, func(time.Time) tea.Msg {
+		return taskRefreshTickMsg{}
+	})
+}
@@ -0,0 +1,115 @@
+package processors
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// fishCommandPrefix starts an entry of the fish history, followed by the escaped command
+	fishCommandPrefix = "- cmd:"
+	// fishWhenPrefix gives the unix timestamp of the entry
+	fishWhenPrefix = "  when:"
+)
+
+// ParseFishHistory reads and parses the fish history file (fish_history).
+// It is a YAML-like file where each entry is made of a `- cmd: command` line
+// followed by a `  when: timestamp` line and optionally by the paths used by the command.
+// New lines and backslashes of the command are escaped.
+// The checkpoint is moved after an entry once the next entry or the end of the file is reached.
+func (h *HistoryIngestor) ParseFishHistory(
+	historyFilePath string,
+	checkpoint *HistoryCheckpoint,
+	callback func(HistoryCommand) (CommandImportedStatus, error),
+) (*HistoryCheckpoint, error) {
+	file, err := h.OpenHistoryFile(historyFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader, err := newHistoryFileReader(file, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+	var currentCommand *HistoryCommand
+	commandLineNumber := 0
+	handleCurrentCommand := func() error {
+		if currentCommand == nil {
+			return nil
+		}
+		currentCommand.ParseFinished = true
+		importStatus, err := h.handleCommand(
+			historyFilePath, commandLineNumber, reader, currentCommand, callback,
+		)
+		h.updateStats(importStatus)
+		currentCommand = nil
+		return err
+	}
+
+	lineNumber := 0
+	for reader.Scan() {
+		line := string(reader.Line())
+		lineNumber++
+		if command, found := strings.CutPrefix(line, fishCommandPrefix); found {
+			if err := handleCurrentCommand(); err != nil {
+				return reader.Checkpoint(), err // Propagate callback error
+			}
+			reader.commitPreviousLine()
+			commandLineNumber = lineNumber
+			currentCommand = &HistoryCommand{
+				Timestamp:          time.Now().UTC(), // replaced by the when line
+				SyntheticTimestamp: true,
+				Elapsed:            0,
+				Command:            cleanCommand(unescapeFishCommand(strings.TrimPrefix(command, " "))),
+				ParseFinished:      false,
+				Checkpoint:         HistoryCheckpoint{LastLineHash: "", Inode: 0, Offset: 0}, // set once parsed
+				Reread:             false,
+			}
+			continue
+		}
+		if when, found := strings.CutPrefix(line, fishWhenPrefix); found && currentCommand != nil {
+			if timestamp, err := strconv.ParseInt(strings.TrimSpace(when), 10, 64); err == nil {
+				currentCommand.Timestamp = convertUnixToUTC(timestamp)
+				currentCommand.SyntheticTimestamp = false
+			}
+		}
+		// paths of the entry are ignored
+	}
+	if err := handleCurrentCommand(); err != nil {
+		return reader.Checkpoint(), err
+	}
+	if err := reader.Err(); err != nil {
+		return reader.Checkpoint(), err // Propagate scanner error
+	}
+	reader.commit()
+	h.logStats(historyFilePath, HistoryFormatFish, checkpoint)
+
+	return reader.Checkpoint(), nil
+}
+
+// unescapeFishCommand decodes the \n and \\ sequences written by fish
+func unescapeFishCommand(command string) string {
+	if !strings.Contains(command, "\\") {
+		return command
+	}
+	var result strings.Builder
+	for i := 0; i < len(command); i++ {
+		if command[i] != '\\' || i+1 >= len(command) {
+			result.WriteByte(command[i])
+			continue
+		}
+		switch command[i+1] {
+		case 'n':
+			result.WriteByte('\n')
+			i++
+		case '\\':
+			result.WriteByte('\\')
+			i++
+		default:
+			result.WriteByte(command[i])
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,51 @@
+package processors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnescapeFishCommand(t *testing.T) {
+	assert.Equal(t, "echo a\necho b", unescapeFishCommand(`echo a\necho b`))
+	assert.Equal(t, `printf '\n'`, unescapeFishCommand(`printf '\\n'`))
+	assert.Equal(t, `echo \t`, unescapeFishCommand(`echo \t`))
+}
+
+func TestParseFishHistory(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "fish_history")
+	content := `- cmd: git status
+  when: 1712345678
+- cmd: for f in *.go\n  gofmt -l $f\nend
+  when: 1712345690
+  paths:
+    - main.go
+- cmd: make build
+  when: 1712345700
+`
+	require.NoError(t, os.WriteFile(historyFile, []byte(content), FileMode))
+
+	var commands []HistoryCommand
+	checkpoint, err := NewHistoryIngestor().ParseHistory(
+		&HistorySource{Format: HistoryFormatFish, Path: historyFile},
+		nil,
+		func(cmd HistoryCommand) (CommandImportedStatus, error) {
+			commands = append(commands, cmd)
+			return CommandImportedStatusNew, nil
+		},
+	)
+
+	require.NoError(t, err)
+	require.Len(t, commands, 3)
+	assert.Equal(t, "git status", commands[0].Command)
+	assert.Equal(t, "for f in *.go\n  gofmt -l $f\nend", commands[1].Command)
+	assert.Equal(t, time.Unix(1712345690, 0).UTC(), commands[1].Timestamp)
+	assert.False(t, commands[1].SyntheticTimestamp)
+	assert.Equal(t, "make build", commands[2].Command)
+	assert.True(t, commands[2].ParseFinished)
+	assert.Equal(t, int64(len(content)), checkpoint.Offset)
+}
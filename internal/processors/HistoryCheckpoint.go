@@ -0,0 +1,180 @@
+package processors
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"os"
+)
+
+// maxHistoryLineLength is the maximum length of a line read from a history file
+const maxHistoryLineLength = bufio.MaxScanTokenSize
+
+// HistoryCheckpoint is the position in a history file up to which the commands have been ingested
+type HistoryCheckpoint struct {
+	// LastLineHash is the sha256 of the line ending at Offset,
+	// it allows to detect a history file rewritten in place
+	LastLineHash string
+	// Inode of the history file, it allows to detect a rotated history file
+	Inode uint64
+	// Offset is the byte offset following the last ingested line
+	Offset int64
+}
+
+// historyFileReader reads the lines of a history file from a checkpoint,
+// the checkpoint is moved forward using commit once the commands read are ingested
+type historyFileReader struct {
+	scanner    *bufio.Scanner
+	checkpoint *HistoryCheckpoint
+	// line is the raw content of the current line, without the line ending
+	line []byte
+	// previousLine is the raw content of the line preceding the current line
+	previousLine []byte
+	// offset is the byte offset following the current line
+	offset int64
+	// previousOffset is the byte offset following the previous line
+	previousOffset int64
+	// terminated is false if the current line is the last line of the file
+	// without line ending, it may be incomplete
+	terminated         bool
+	previousTerminated bool
+	// reread is true when the checkpoint given is no longer valid and the file is read
+	// from the beginning, its commands may have been ingested already
+	reread bool
+}
+
+// newHistoryFileReader positions the file at the checkpoint if the file is still the one
+// of the checkpoint and has not been truncated or rewritten, at the beginning otherwise
+func newHistoryFileReader(file *os.File, checkpoint *HistoryCheckpoint) (*historyFileReader, error) {
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	reader := &historyFileReader{
+		scanner: nil,
+		checkpoint: &HistoryCheckpoint{
+			LastLineHash: "",
+			Inode:        getInode(info),
+			Offset:       0,
+		},
+		line:               nil,
+		previousLine:       nil,
+		offset:             0,
+		previousOffset:     0,
+		terminated:         true,
+		previousTerminated: true,
+		reread:             false,
+	}
+	if !isCheckpointValid(file, info.Size(), reader.checkpoint.Inode, checkpoint) {
+		reader.reread = checkpoint != nil
+	} else {
+		if _, err := file.Seek(checkpoint.Offset, io.SeekStart); err != nil {
+			return nil, err
+		}
+		reader.checkpoint.Offset = checkpoint.Offset
+		reader.checkpoint.LastLineHash = checkpoint.LastLineHash
+		reader.offset = checkpoint.Offset
+		reader.previousOffset = checkpoint.Offset
+	}
+	reader.scanner = bufio.NewScanner(file)
+	reader.scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if advance > 0 {
+			reader.previousOffset = reader.offset
+			reader.offset += int64(advance)
+			reader.previousTerminated = reader.terminated
+			reader.terminated = advance > len(token) && data[advance-1] == '\n'
+		}
+		return advance, token, err
+	})
+	return reader, nil
+}
+
+// isCheckpointValid checks that the history file can be read from the checkpoint
+func isCheckpointValid(file *os.File, size int64, inode uint64, checkpoint *HistoryCheckpoint) bool {
+	if checkpoint == nil || checkpoint.Offset <= 0 {
+		return false
+	}
+	if checkpoint.Inode != inode {
+		slog.Info("History file rotated, reading it from the beginning",
+			"file", file.Name(), "inode", inode, "checkpointInode", checkpoint.Inode)
+		return false
+	}
+	if size < checkpoint.Offset {
+		slog.Info("History file truncated, reading it from the beginning",
+			"file", file.Name(), "size", size, "checkpointOffset", checkpoint.Offset)
+		return false
+	}
+	lastLine, err := readLineEndingAt(file, checkpoint.Offset)
+	if err != nil || hashHistoryLine(lastLine) != checkpoint.LastLineHash {
+		slog.Info("History file rewritten, reading it from the beginning",
+			"file", file.Name(), "checkpointOffset", checkpoint.Offset, "error", err)
+		return false
+	}
+	return true
+}
+
+// readLineEndingAt returns the line ending at the offset, without its line ending
+func readLineEndingAt(file *os.File, offset int64) ([]byte, error) {
+	start := max(0, offset-maxHistoryLineLength)
+	buffer := make([]byte, offset-start)
+	if _, err := file.ReadAt(buffer, start); err != nil {
+		return nil, err
+	}
+	buffer = bytes.TrimSuffix(buffer, []byte("\n"))
+	buffer = bytes.TrimSuffix(buffer, []byte("\r"))
+	return buffer[bytes.LastIndexByte(buffer, '\n')+1:], nil
+}
+
+func hashHistoryLine(line []byte) string {
+	hash := sha256.Sum256(line)
+	return hex.EncodeToString(hash[:])
+}
+
+// Scan reads the next line
+func (r *historyFileReader) Scan() bool {
+	r.previousLine = r.line
+	if !r.scanner.Scan() {
+		return false
+	}
+	r.line = bytes.Clone(r.scanner.Bytes())
+	return true
+}
+
+// Err returns the error of the scanner
+func (r *historyFileReader) Err() error {
+	return r.scanner.Err()
+}
+
+// Line returns the raw content of the current line
+func (r *historyFileReader) Line() []byte {
+	return r.line
+}
+
+// commit moves the checkpoint after the current line, a line without line ending
+// is not committed as it may be completed later
+func (r *historyFileReader) commit() {
+	if r.line == nil || !r.terminated {
+		return
+	}
+	r.checkpoint.Offset = r.offset
+	r.checkpoint.LastLineHash = hashHistoryLine(r.line)
+}
+
+// commitPreviousLine moves the checkpoint after the line preceding the current line
+func (r *historyFileReader) commitPreviousLine() {
+	if r.previousLine == nil || !r.previousTerminated {
+		return
+	}
+	r.checkpoint.Offset = r.previousOffset
+	r.checkpoint.LastLineHash = hashHistoryLine(r.previousLine)
+}
+
+// Checkpoint returns the position following the last committed line
+func (r *historyFileReader) Checkpoint() *HistoryCheckpoint {
+	checkpoint := *r.checkpoint
+	return &checkpoint
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package processors
+
+import (
+	"os"
+)
+
+// getInode returns 0 as inodes are not available, rotation is detected by truncation only
+func getInode(_ os.FileInfo) uint64 {
+	return 0
+}
@@ -0,0 +1,148 @@
+package processors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parseBashHistoryFrom(
+	t *testing.T, historyFile string, checkpoint *HistoryCheckpoint,
+) ([]string, *HistoryCheckpoint) {
+	t.Helper()
+	var commands []string
+	newCheckpoint, err := NewHistoryIngestor().ParseBashHistory(historyFile, checkpoint,
+		func(cmd HistoryCommand) (CommandImportedStatus, error) {
+			commands = append(commands, cmd.Command)
+			return CommandImportedStatusNew, nil
+		})
+	require.NoError(t, err)
+	require.NotNil(t, newCheckpoint)
+	return commands, newCheckpoint
+}
+
+func appendToFile(t *testing.T, path string, content string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, FileMode) // #nosec G304
+	require.NoError(t, err)
+	_, err = file.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+}
+
+func TestParseBashHistory_Checkpoint(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "bash_history")
+	require.NoError(t, os.WriteFile(historyFile, []byte("git status\nmake build\n"), FileMode))
+
+	commands, checkpoint := parseBashHistoryFrom(t, historyFile, nil)
+	assert.Equal(t, []string{"git status", "make build"}, commands)
+	assert.Equal(t, int64(len("git status\nmake build\n")), checkpoint.Offset)
+	assert.Equal(t, hashHistoryLine([]byte("make build")), checkpoint.LastLineHash)
+
+	// only the new lines are read, the pending timestamp line and
+	// the last line without line ending are read again next time
+	appendToFile(t, historyFile, "ls -la\n#1712345600\necho incomplete")
+	commands, nextCheckpoint := parseBashHistoryFrom(t, historyFile, checkpoint)
+	assert.Equal(t, []string{"ls -la", "echo incomplete"}, commands)
+	assert.Equal(t, checkpoint.Offset+int64(len("ls -la\n")), nextCheckpoint.Offset)
+
+	appendToFile(t, historyFile, " done\n")
+	commands, checkpoint = parseBashHistoryFrom(t, historyFile, nextCheckpoint)
+	assert.Equal(t, []string{"echo incomplete done"}, commands)
+	info, err := os.Stat(historyFile)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), checkpoint.Offset)
+
+	// nothing new
+	commands, _ = parseBashHistoryFrom(t, historyFile, checkpoint)
+	assert.Empty(t, commands)
+}
+
+func TestParseBashHistory_CheckpointInvalidated(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "bash_history")
+	require.NoError(t, os.WriteFile(historyFile, []byte("git status\nmake build\n"), FileMode))
+	_, checkpoint := parseBashHistoryFrom(t, historyFile, nil)
+
+	// truncated file
+	require.NoError(t, os.WriteFile(historyFile, []byte("ls -la\n"), FileMode))
+	commands, _ := parseBashHistoryFrom(t, historyFile, checkpoint)
+	assert.Equal(t, []string{"ls -la"}, commands)
+
+	// file rewritten in place with the same size
+	require.NoError(t, os.WriteFile(historyFile, []byte("git statu\nmake build2\n"), FileMode))
+	commands, _ = parseBashHistoryFrom(t, historyFile, checkpoint)
+	assert.Equal(t, []string{"git statu", "make build2"}, commands)
+
+	// rotated file, replaced by a new file
+	rotatedFile := filepath.Join(filepath.Dir(historyFile), "new_history")
+	require.NoError(t, os.WriteFile(historyFile, []byte("git status\nmake build\n"), FileMode))
+	_, checkpoint = parseBashHistoryFrom(t, historyFile, nil)
+	require.NoError(t, os.WriteFile(rotatedFile, []byte("git status\nmake build\nls -la\n"), FileMode))
+	require.NoError(t, os.Rename(rotatedFile, historyFile))
+	commands, _ = parseBashHistoryFrom(t, historyFile, checkpoint)
+	if checkpoint.Inode == 0 {
+		// inodes not available, the rotation is not detected
+		assert.Equal(t, []string{"ls -la"}, commands)
+	} else {
+		assert.Equal(t, []string{"git status", "make build", "ls -la"}, commands)
+	}
+}
+
+func TestParseBashHistory_CommandCheckpoint(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "bash_history")
+	require.NoError(t, os.WriteFile(historyFile, []byte("git status\nmake build\n"), FileMode))
+	parse := func(checkpoint *HistoryCheckpoint) []HistoryCommand {
+		var commands []HistoryCommand
+		_, err := NewHistoryIngestor().ParseBashHistory(historyFile, checkpoint,
+			func(cmd HistoryCommand) (CommandImportedStatus, error) {
+				commands = append(commands, cmd)
+				return CommandImportedStatusNew, nil
+			})
+		require.NoError(t, err)
+		return commands
+	}
+
+	commands := parse(nil)
+	require.Len(t, commands, 2)
+	assert.False(t, commands[0].Reread, "no checkpoint, the file is read for the first time")
+	assert.Equal(t, int64(0), commands[0].Checkpoint.Offset)
+	// the checkpoint of a command is the position following the previous command
+	assert.Equal(t, int64(len("git status\n")), commands[1].Checkpoint.Offset)
+	assert.Equal(t, hashHistoryLine([]byte("git status")), commands[1].Checkpoint.LastLineHash)
+
+	// the file has been rewritten, it is read again from the beginning
+	rewrittenCheckpoint := &commands[1].Checkpoint
+	require.NoError(t, os.WriteFile(historyFile, []byte("ls -la\nmake build\n"), FileMode))
+	commands = parse(rewrittenCheckpoint)
+	require.Len(t, commands, 2)
+	assert.True(t, commands[0].Reread)
+	assert.True(t, commands[1].Reread)
+}
+
+func TestParseFishHistory_Checkpoint(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "fish_history")
+	content := "- cmd: git status\n  when: 1712345678\n"
+	require.NoError(t, os.WriteFile(historyFile, []byte(content), FileMode))
+	source := &HistorySource{Format: HistoryFormatFish, Path: historyFile}
+	parse := func(checkpoint *HistoryCheckpoint) ([]string, *HistoryCheckpoint) {
+		var commands []string
+		newCheckpoint, err := NewHistoryIngestor().ParseHistory(source, checkpoint,
+			func(cmd HistoryCommand) (CommandImportedStatus, error) {
+				commands = append(commands, cmd.Command)
+				return CommandImportedStatusNew, nil
+			})
+		require.NoError(t, err)
+		return commands, newCheckpoint
+	}
+
+	commands, checkpoint := parse(nil)
+	assert.Equal(t, []string{"git status"}, commands)
+	assert.Equal(t, int64(len(content)), checkpoint.Offset)
+
+	appendToFile(t, historyFile, "- cmd: make build\n  when: 1712345690\n  paths:\n    - Makefile\n")
+	commands, _ = parse(checkpoint)
+	assert.Equal(t, []string{"make build"}, commands)
+}
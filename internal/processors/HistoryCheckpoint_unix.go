@@ -0,0 +1,16 @@
+//go:build unix
+
+package processors
+
+import (
+	"os"
+	"syscall"
+)
+
+// getInode returns the inode of the file
+func getInode(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Ino) //nolint:unconvert // not uint64 on all platforms
+	}
+	return 0
+}
@@ -1,7 +1,6 @@
 package processors
 
 import (
-	"bufio"
 	"errors"
 	"log/slog"
 	"os"
@@ -14,8 +13,8 @@ import (
 
 const ExtendedCommandPrefixLen = 2
 
-// controlCharsRegexp is a regex to match control characters
-var controlCharsRegexp = regexp.MustCompile(`[^\x00-\x7F]`)
+// controlCharsRegexp matches the C0 and C1 control characters except the tab and the line feed
+var controlCharsRegexp = regexp.MustCompile(`[\x00-\x08\x0B-\x1F\x7F-\x9F]`)
 
 type HistoryIngestor struct {
 	// parsedCmdCount is the number of commands parsed from the history file
@@ -50,10 +49,18 @@ func NewHistoryIngestor() *HistoryIngestor {
 
 // HistoryCommand represents a single command entry from bash history
 type HistoryCommand struct {
-	Timestamp     time.Time
-	Command       string
-	Elapsed       int // elapsed time in seconds
-	ParseFinished bool
+	Timestamp time.Time
+	// SyntheticTimestamp is true when the history file does not give the time of the command,
+	// Timestamp is then the time of the ingestion
+	SyntheticTimestamp bool
+	Command            string
+	Elapsed            int // elapsed time in seconds
+	ParseFinished      bool
+	// Checkpoint is the position following the commands read before this one
+	Checkpoint HistoryCheckpoint
+	// Reread is true when the history file is read from the beginning as the checkpoint
+	// is no longer valid, the command may have been ingested by a previous ingestion
+	Reread bool
 }
 
 // timestampFieldsCount is the number of fields in the extended format
@@ -77,43 +84,108 @@ func (*HistoryIngestor) OpenHistoryFile(historyFilePath string) (*os.File, error
 	return file, nil
 }
 
+// ParseHistory reads and parses the history file of the source using the parser of its format.
+// The file is read from the checkpoint of the previous ingestion (nil to read the whole file),
+// the checkpoint following the last ingested line is returned, also when the callback fails.
+func (h *HistoryIngestor) ParseHistory(
+	source *HistorySource,
+	checkpoint *HistoryCheckpoint,
+	callback func(HistoryCommand) (CommandImportedStatus, error),
+) (*HistoryCheckpoint, error) {
+	switch source.Format {
+	case HistoryFormatZsh:
+		return h.ParseZshHistory(source.Path, checkpoint, callback)
+	case HistoryFormatFish:
+		return h.ParseFishHistory(source.Path, checkpoint, callback)
+	case HistoryFormatBash:
+		return h.ParseBashHistory(source.Path, checkpoint, callback)
+	default:
+		return h.ParseBashHistory(source.Path, checkpoint, callback)
+	}
+}
+
 // ParseBashHistory reads and parses the bash history file
 // It supports both simple format (just commands) and extended format (`: start:elapsed;command`)
+// The `#<unix_timestamp>` lines written when HISTTIMEFORMAT is set give the timestamp of the next command.
 // It handles multi-line commands indicated by a trailing backslash '\'.
 func (h *HistoryIngestor) ParseBashHistory(
 	historyFilePath string,
-	fromTimestamp time.Time,
+	checkpoint *HistoryCheckpoint,
+	callback func(HistoryCommand) (CommandImportedStatus, error),
+) (*HistoryCheckpoint, error) {
+	return h.parseLineHistory(historyFilePath, HistoryFormatBash, checkpoint, callback, nil)
+}
+
+// ParseZshHistory reads and parses the zsh history file
+// It supports both simple format and extended history format (`: start:elapsed;command`),
+// the metafied bytes written by zsh for non ascii characters are decoded.
+func (h *HistoryIngestor) ParseZshHistory(
+	historyFilePath string,
+	checkpoint *HistoryCheckpoint,
+	callback func(HistoryCommand) (CommandImportedStatus, error),
+) (*HistoryCheckpoint, error) {
+	return h.parseLineHistory(historyFilePath, HistoryFormatZsh, checkpoint, callback, unmetafy)
+}
+
+// parseLineHistory parses the history files having one command per line,
+// decodeLine converts the raw bytes of a line if needed.
+// The checkpoint is moved after a line only when no command or timestamp line is pending,
+// so that a command being written is read again by the next ingestion.
+func (h *HistoryIngestor) parseLineHistory(
+	historyFilePath string,
+	format HistoryFormat,
+	checkpoint *HistoryCheckpoint,
 	callback func(HistoryCommand) (CommandImportedStatus, error),
-) error {
+	decodeLine func([]byte) []byte,
+) (*HistoryCheckpoint, error) {
 	file, err := h.OpenHistoryFile(historyFilePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	reader, err := newHistoryFileReader(file, checkpoint)
+	if err != nil {
+		return nil, err
+	}
 	var commandBuilder strings.Builder
 	var importStatus CommandImportedStatus
 	var currentCommand *HistoryCommand // Pointer to track the command being built
+	// historyTimestamp is the timestamp written by bash (HISTTIMEFORMAT) for the next command
+	var historyTimestamp time.Time
 
 	lineNumber := 0
-	for scanner.Scan() {
-		line := scanner.Text()
+	for reader.Scan() {
+		lineBytes := reader.Line()
+		if decodeLine != nil {
+			lineBytes = decodeLine(lineBytes)
+		}
+		line := string(lineBytes)
 		lineNumber++
 
 		// Skip empty lines only if not currently building a multi-line command
 		if line == "" && currentCommand == nil {
+			if historyTimestamp.IsZero() {
+				reader.commit()
+			}
 			continue
 		}
+		if currentCommand == nil {
+			if timestamp, ok := parseHistoryTimestampLine(line); ok {
+				historyTimestamp = timestamp
+				continue
+			}
+		}
 
-		h.processHistoryLine(line, &commandBuilder, &currentCommand)
+		h.processHistoryLine(line, historyTimestamp, &commandBuilder, &currentCommand)
+		historyTimestamp = time.Time{}
 
 		if importStatus, err = h.handleCommand(
-			historyFilePath, lineNumber, fromTimestamp, currentCommand, callback,
+			historyFilePath, lineNumber, reader, currentCommand, callback,
 		); err != nil {
 			// Stop processing if the callback returns an error
 			h.updateStats(importStatus)
-			return err // Propagate callback error
+			return reader.Checkpoint(), err // Propagate callback error
 		}
 		h.updateStats(importStatus)
 
@@ -121,32 +193,45 @@ func (h *HistoryIngestor) ParseBashHistory(
 			// If the command is fully parsed, we can reset the command builder
 			currentCommand = nil
 			commandBuilder.Reset()
+			reader.commit()
 		}
 	}
 
 	// Handle case where the file ends while building a multi-line command
-	// (e.g., the last command in the file is multi-line without a final newline)
+	// (e.g., the last command in the file is multi-line without a final newline),
+	// the checkpoint is not moved as the command may be completed later
 	if currentCommand != nil {
 		// Finalize the command built so far
 		currentCommand.ParseFinished = true // Mark as fully parsed
 		currentCommand.Command = commandBuilder.String()
 
 		if importStatus, err = h.handleCommand(
-			historyFilePath, lineNumber, fromTimestamp, currentCommand, callback,
+			historyFilePath, lineNumber, reader, currentCommand, callback,
 		); err != nil {
 			h.updateStats(importStatus)
-			return err // Propagate callback error
+			return reader.Checkpoint(), err // Propagate callback error
 		}
 		h.updateStats(importStatus)
 	}
 
-	if err := scanner.Err(); err != nil {
-		return err // Propagate scanner error
+	if err := reader.Err(); err != nil {
+		return reader.Checkpoint(), err // Propagate scanner error
+	}
+	h.logStats(historyFilePath, format, checkpoint)
+
+	return reader.Checkpoint(), nil
+}
+
+func (h *HistoryIngestor) logStats(historyFilePath string, format HistoryFormat, checkpoint *HistoryCheckpoint) {
+	fromOffset := int64(0)
+	if checkpoint != nil {
+		fromOffset = checkpoint.Offset
 	}
 	slog.Debug(
 		"History ingestion stats",
 		"historyFilePath", historyFilePath,
-		"fromTimestamp", fromTimestamp,
+		"format", format,
+		"fromOffset", fromOffset,
 		"parsedCmdCount", h.parsedCmdCount,
 		"importedCmdCount", h.importedCmdCount,
 		"skippedCmdCount", h.skippedCmdCount,
@@ -154,8 +239,6 @@ func (h *HistoryIngestor) ParseBashHistory(
 		"alreadyExistsCmdCount", h.alreadyExistsCmdCount,
 		"filteredOutCmdCount", h.filteredOutCmdCount,
 	)
-
-	return nil
 }
 
 // updateStats updates the statistics based on the import status
@@ -193,10 +276,29 @@ const (
 	CommandImportedStatusInProgress
 )
 
+func (s CommandImportedStatus) String() string {
+	switch s {
+	case CommandImportedStatusNew:
+		return "new"
+	case CommandImportedStatusSkipped:
+		return "skipped"
+	case CommandImportedStatusFilteredOut:
+		return "filtered out"
+	case CommandImportedStatusAlreadyExists:
+		return "already exists"
+	case CommandImportedStatusError:
+		return "error"
+	case CommandImportedStatusInProgress:
+		return "in progress"
+	default:
+		return "unknown"
+	}
+}
+
 func (*HistoryIngestor) handleCommand(
 	historyFilePath string,
 	lineNumber int,
-	fromTimestamp time.Time,
+	reader *historyFileReader,
 	cmd *HistoryCommand,
 	callback func(HistoryCommand) (CommandImportedStatus, error),
 ) (CommandImportedStatus, error) {
@@ -204,31 +306,20 @@ func (*HistoryIngestor) handleCommand(
 		return CommandImportedStatusInProgress, nil // Skip if the command is not fully parsed
 	}
 	if strings.TrimSpace(cmd.Command) == "" {
+		slog.Debug("Skipping empty command", "historyFilePath", historyFilePath, "lineNumber", lineNumber)
 		return CommandImportedStatusSkipped, nil // Skip empty commands
 	}
-	importedStatus := CommandImportedStatusSkipped
-	var err error
-	if cmd.Timestamp.IsZero() || cmd.Timestamp.After(fromTimestamp) {
-		if importedStatus, err = callback(*cmd); err != nil {
-			return importedStatus, err
-		}
-	} else {
-		slog.Debug(
-			"Skipping command due to timestamp",
-			"historyFilePath", historyFilePath,
-			"timestamp", cmd.Timestamp,
-			"fromTimestamp", fromTimestamp,
-			"lineNumber", lineNumber,
-		)
-	}
-	return importedStatus, nil
+	cmd.Checkpoint = *reader.checkpoint
+	cmd.Reread = reader.reread
+	return callback(*cmd)
 }
 
 // processHistoryLine handles the logic for a single line from the history file.
 // It updates the currentCommand being built and returns true if a command is completed.
 // The currentCommand pointer (**cmd) allows modification of the caller's currentCommand variable.
+// historyTimestamp, if not zero, is the timestamp of a new command not using the extended format.
 func (*HistoryIngestor) processHistoryLine(
-	line string, commandBuilder *strings.Builder, cmd **HistoryCommand,
+	line string, historyTimestamp time.Time, commandBuilder *strings.Builder, cmd **HistoryCommand,
 ) {
 	currentCommand := *cmd // Dereference to work with the actual *HistoryCommand
 
@@ -237,13 +328,20 @@ func (*HistoryIngestor) processHistoryLine(
 		// Start of a potential new command
 		var ts time.Time
 		var el int
-		ts, el, part, _ = parseFirstHistoryLine(line)
+		var isExtendedFormat bool
+		ts, el, part, isExtendedFormat = parseFirstHistoryLine(line)
+		if !isExtendedFormat && !historyTimestamp.IsZero() {
+			ts = historyTimestamp
+		}
 		// Initialize the command being built
 		currentCommand = &HistoryCommand{
-			Timestamp:     ts,
-			Elapsed:       el,
-			Command:       "", // Command string set later
-			ParseFinished: false,
+			Timestamp:          ts,
+			SyntheticTimestamp: !isExtendedFormat && historyTimestamp.IsZero(),
+			Elapsed:            el,
+			Command:            "", // Command string set later
+			ParseFinished:      false,
+			Checkpoint:         HistoryCheckpoint{LastLineHash: "", Inode: 0, Offset: 0}, // set once parsed
+			Reread:             false,
 		}
 		*cmd = currentCommand // Update the caller's pointer
 	}
@@ -273,9 +371,10 @@ func cleanCommand(command string) string {
 	return command
 }
 
-// removeControlCharacters removes control characters from the command string
+// removeControlCharacters removes the control characters and the invalid UTF-8 sequences
+// from the command string, the other non ASCII characters are kept
 func removeControlCharacters(command string) string {
-	return controlCharsRegexp.ReplaceAllString(command, "")
+	return controlCharsRegexp.ReplaceAllString(strings.ToValidUTF8(command, ""), "")
 }
 
 // parseFirstHistoryLine parses the first line of a command entry.
@@ -320,6 +419,25 @@ func parseFirstHistoryLine(line string) (timestamp time.Time, elapsed int, comma
 	return timestamp, elapsed, commandPart, isExtendedFormat
 }
 
+// parseHistoryTimestampLine parses the `#<unix_timestamp>` lines written by bash
+// before each command when HISTTIMEFORMAT is set
+func parseHistoryTimestampLine(line string) (time.Time, bool) {
+	digits, found := strings.CutPrefix(strings.TrimRight(line, " \t\r"), "#")
+	if !found || digits == "" {
+		return time.Time{}, false
+	}
+	for _, char := range digits {
+		if char < '0' || char > '9' {
+			return time.Time{}, false
+		}
+	}
+	timestamp, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return convertUnixToUTC(timestamp), true
+}
+
 var (
 	errInvalidTimestampFormat = errors.New("invalid timestamp format")
 	errInvalidTimestamp       = errors.New("invalid timestamp")
@@ -184,6 +184,13 @@ func TestParseBashHistory(t *testing.T) {
 		t.Fatalf("Failed to create test file: %v", err)
 	}
 
+	// Test case 5: Multibyte characters and control characters
+	unicodeFile := filepath.Join(testDir, "unicode_history")
+	unicodeContent := "git commit -m 'café ☕ 修复'\nls /home/用户/文档\necho \x07bell\x1b\r\n"
+	if err := os.WriteFile(unicodeFile, []byte(unicodeContent), FileMode); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
 	tests := []struct {
 		name         string
 		historyFile  string
@@ -214,6 +221,12 @@ func TestParseBashHistory(t *testing.T) {
 			wantCommands: []string{"ls -la", "cd /home", "pwd"},
 			wantErr:      false,
 		},
+		{
+			name:         "Unicode",
+			historyFile:  unicodeFile,
+			wantCommands: []string{"git commit -m 'café ☕ 修复'", "ls /home/用户/文档", "echo bell"},
+			wantErr:      false,
+		},
 		{
 			name:         "Non-existent file",
 			historyFile:  filepath.Join(testDir, "nonexistent"),
@@ -234,7 +247,7 @@ func TestParseBashHistory(t *testing.T) {
 				alreadyExistsCmdCount: 0,
 				filteredOutCmdCount:   0,
 			}
-			err := historyIngestor.ParseBashHistory(tt.historyFile, time.Time{},
+			_, err := historyIngestor.ParseBashHistory(tt.historyFile, nil,
 				func(cmd HistoryCommand) (CommandImportedStatus, error) {
 					commands = append(commands, cmd.Command)
 					return CommandImportedStatusNew, nil
@@ -286,7 +299,7 @@ func TestParseBashHistoryWithCallbackError(t *testing.T) {
 		alreadyExistsCmdCount: 0,
 		filteredOutCmdCount:   0,
 	}
-	err := historyIngestor.ParseBashHistory(testFile, time.Time{},
+	_, err := historyIngestor.ParseBashHistory(testFile, nil,
 		func(_ HistoryCommand) (CommandImportedStatus, error) {
 			callCount++
 			if callCount == 2 {
@@ -323,7 +336,7 @@ func TestParseBashHistoryDefaultPath(t *testing.T) {
 		alreadyExistsCmdCount: 0,
 		filteredOutCmdCount:   0,
 	}
-	err = historyIngestor.ParseBashHistory("", time.Time{}, func(_ HistoryCommand) (CommandImportedStatus, error) {
+	_, err = historyIngestor.ParseBashHistory("", nil, func(_ HistoryCommand) (CommandImportedStatus, error) {
 		// Don't process commands from actual history file
 		return CommandImportedStatusSkipped, nil
 	})
@@ -358,8 +371,8 @@ func TestParseBashHistory_MultiLineError(t *testing.T) {
 			historyContent: `echo "hello"
 ls -l`,
 			expectedCmds: []HistoryCommand{
-				{Command: `echo "hello"`},
-				{Command: `ls -l`},
+				{Command: `echo "hello"`, SyntheticTimestamp: true},
+				{Command: `ls -l`, SyntheticTimestamp: true},
 			},
 		},
 		{
@@ -371,8 +384,8 @@ ls -l`,
 			expectedCmds: []HistoryCommand{
 				{Command: `echo "line1"
 line2
-line3`},
-				{Command: `ls -l`},
+line3`, SyntheticTimestamp: true},
+				{Command: `ls -l`, SyntheticTimestamp: true},
 			},
 		},
 		{
@@ -407,12 +420,12 @@ another simple \
 multi-line
 : 1678886410:2;docker ps`,
 			expectedCmds: []HistoryCommand{
-				{ParseFinished: true, Command: `simple command`},
+				{ParseFinished: true, Command: `simple command`, SyntheticTimestamp: true},
 				{ParseFinished: true, Command: `git commit -m "multi
 line
 message"`, Timestamp: time.Unix(1678886400, 0).UTC(), Elapsed: 5},
 				{ParseFinished: true, Command: `another simple
-multi-line`},
+multi-line`, SyntheticTimestamp: true},
 				{
 					ParseFinished: true, Command: `docker ps`,
 					Timestamp: time.Unix(1678886410, 0).UTC(), Elapsed: 2,
@@ -426,7 +439,7 @@ part2 \`,
 			expectedCmds: []HistoryCommand{
 				{ParseFinished: true, Command: `echo "part1"
 part2
-`}, // Note the trailing space might occur depending on interpretation
+`, SyntheticTimestamp: true}, // Note the trailing space might occur depending on interpretation
 			},
 		},
 		{
@@ -455,14 +468,14 @@ part2
 			name:           "Invalid extended format (no semicolon)",
 			historyContent: `: 1678886400:5 git status`, // Missing semicolon
 			expectedCmds: []HistoryCommand{
-				{ParseFinished: true, Command: `: 1678886400:5 git status`}, // Treated as simple command
+				{ParseFinished: true, Command: `: 1678886400:5 git status`, SyntheticTimestamp: true}, // Treated as simple command
 			},
 		},
 		{
 			name:           "Invalid extended format (bad timestamp)",
 			historyContent: `: not_a_time:5;git status`,
 			expectedCmds: []HistoryCommand{
-				{ParseFinished: true, Command: `: not_a_time:5;git status`}, // Treated as simple command
+				{ParseFinished: true, Command: `: not_a_time:5;git status`, SyntheticTimestamp: true}, // Treated as simple command
 			},
 		},
 	}
@@ -479,6 +492,8 @@ part2
 					// Use a fixed time for comparison or skip timestamp check for simple format
 					cmd.Timestamp = time.Time{} // Zero out for comparison
 				}
+				// the checkpoint is checked by the checkpoint tests
+				cmd.Checkpoint = HistoryCheckpoint{} //nolint:exhaustruct // zero checkpoint
 				//nolint:exhaustruct // This is a test case struct for the test function
 				if cmd != (HistoryCommand{}) {
 					actualCmds = append(actualCmds, cmd)
@@ -486,7 +501,7 @@ part2
 				return CommandImportedStatusNew, nil
 			}
 
-			err := ingestor.ParseBashHistory(historyFilePath, time.Time{}, callback)
+			_, err := ingestor.ParseBashHistory(historyFilePath, nil, callback)
 
 			require.NoError(t, err)
 			// Adjust expected commands' timestamps if they are zero (simple format)
@@ -512,9 +527,9 @@ part2
 
 func TestParseBashHistory_FileNotFound(t *testing.T) {
 	ingestor := NewHistoryIngestor()
-	err := ingestor.ParseBashHistory(
+	_, err := ingestor.ParseBashHistory(
 		"/non/existent/path/to/.bash_history",
-		time.Time{},
+		nil,
 		func(_ HistoryCommand) (CommandImportedStatus, error) {
 			t.Fatal("Callback should not be called")
 			return CommandImportedStatusError, nil
@@ -523,3 +538,87 @@ func TestParseBashHistory_FileNotFound(t *testing.T) {
 	require.Error(t, err)
 	assert.ErrorIs(t, err, os.ErrNotExist) // Or the specific error type returned by os.Open
 }
+
+func TestParseHistoryTimestampLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		wantTime time.Time
+		wantOk   bool
+	}{
+		{line: "#1712345678", wantTime: time.Unix(1712345678, 0).UTC(), wantOk: true},
+		{line: "#1712345678 \r", wantTime: time.Unix(1712345678, 0).UTC(), wantOk: true},
+		{line: "#", wantTime: time.Time{}, wantOk: false},
+		{line: "# a comment", wantTime: time.Time{}, wantOk: false},
+		{line: "#123abc", wantTime: time.Time{}, wantOk: false},
+		{line: "ls #1712345678", wantTime: time.Time{}, wantOk: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			gotTime, gotOk := parseHistoryTimestampLine(tt.line)
+			assert.Equal(t, tt.wantOk, gotOk)
+			assert.Equal(t, tt.wantTime, gotTime)
+		})
+	}
+}
+
+func TestParseBashHistory_HistTimeFormat(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "bash_history")
+	content := "#1712345600\n" +
+		"git status\n" +
+		": 1712345650:2;make build\n" +
+		"#1712345700\n" +
+		"docker compose up \\\n" +
+		"  -d\n" +
+		"# a comment line\n" +
+		"#1712345800\n" +
+		"#1712345900\n" +
+		"ls -la\n"
+	require.NoError(t, os.WriteFile(historyFile, []byte(content), FileMode))
+
+	var commands []HistoryCommand
+	_, err := NewHistoryIngestor().ParseBashHistory(historyFile, nil,
+		func(cmd HistoryCommand) (CommandImportedStatus, error) {
+			commands = append(commands, cmd)
+			return CommandImportedStatusNew, nil
+		})
+	require.NoError(t, err)
+
+	require.Len(t, commands, 5)
+	assert.Equal(t, "git status", commands[0].Command)
+	assert.Equal(t, time.Unix(1712345600, 0).UTC(), commands[0].Timestamp)
+	assert.False(t, commands[0].SyntheticTimestamp)
+	assert.Equal(t, "make build", commands[1].Command)
+	assert.Equal(t, time.Unix(1712345650, 0).UTC(), commands[1].Timestamp)
+	assert.Equal(t, 2, commands[1].Elapsed)
+	assert.Equal(t, "docker compose up\n  -d", commands[2].Command)
+	assert.Equal(t, time.Unix(1712345700, 0).UTC(), commands[2].Timestamp)
+	// a comment line not being a timestamp is a command without timestamp
+	assert.Equal(t, "# a comment line", commands[3].Command)
+	assert.WithinDuration(t, time.Now(), commands[3].Timestamp, time.Minute)
+	assert.True(t, commands[3].SyntheticTimestamp)
+	// the last timestamp line before a command is used
+	assert.Equal(t, "ls -la", commands[4].Command)
+	assert.Equal(t, time.Unix(1712345900, 0).UTC(), commands[4].Timestamp)
+}
+
+func TestRemoveControlCharacters(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		want    string
+	}{
+		{"ASCII", "docker ps -a", "docker ps -a"},
+		{"Accents", "cp résumé.pdf /tmp/été", "cp résumé.pdf /tmp/été"},
+		{"CJK", "cd ~/文档/项目", "cd ~/文档/项目"},
+		{"Emoji", "git commit -m '🎉 release 👨‍👩‍👧'", "git commit -m '🎉 release 👨‍👩‍👧'"},
+		{"TabAndNewLineKept", "printf 'a\tb'\necho done", "printf 'a\tb'\necho done"},
+		{"C0Controls", "echo \x00\x07\x1b[31mred\r", "echo [31mred"},
+		{"C1Controls", "echo \u0085\u009bnext", "echo next"},
+		{"InvalidUTF8", "echo \xff\xfeok", "echo ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, removeControlCharacters(tt.command))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package processors
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// HistoryFormat is the format of a shell history file
+type HistoryFormat string
+
+const (
+	// HistoryFormatBash is a bash history file, with optional `: start:elapsed;command` lines
+	HistoryFormatBash HistoryFormat = "bash"
+	// HistoryFormatZsh is a zsh history file, with optional extended history lines
+	HistoryFormatZsh HistoryFormat = "zsh"
+	// HistoryFormatFish is the YAML-like fish_history file
+	HistoryFormatFish HistoryFormat = "fish"
+)
+
+// HistorySource is a history file to ingest
+type HistorySource struct {
+	Format HistoryFormat
+	Path   string
+}
+
+// DiscoverHistorySources returns the history files existing in the usual locations:
+// $HISTFILE, ~/.bash_history, ~/.zsh_history (or $ZDOTDIR/.zsh_history), ~/.zhistory
+// and fish_history in $XDG_DATA_HOME/fish or ~/.local/share/fish.
+// A file is returned only once, the first format found being used.
+func DiscoverHistorySources(homeDir string, getenv func(string) string) []*HistorySource {
+	candidates := []*HistorySource{}
+	if histFile := getenv("HISTFILE"); histFile != "" {
+		candidates = append(candidates, &HistorySource{
+			Format: GetHistoryFormat(histFile, getenv("SHELL")),
+			Path:   histFile,
+		})
+	}
+	zdotDir := getenv("ZDOTDIR")
+	if zdotDir == "" {
+		zdotDir = homeDir
+	}
+	fishDataDir := getenv("XDG_DATA_HOME")
+	if fishDataDir == "" {
+		fishDataDir = filepath.Join(homeDir, ".local", "share")
+	}
+	candidates = append(candidates,
+		&HistorySource{Format: HistoryFormatBash, Path: filepath.Join(homeDir, ".bash_history")},
+		&HistorySource{Format: HistoryFormatZsh, Path: filepath.Join(zdotDir, ".zsh_history")},
+		&HistorySource{Format: HistoryFormatZsh, Path: filepath.Join(homeDir, ".zsh_history")},
+		&HistorySource{Format: HistoryFormatZsh, Path: filepath.Join(homeDir, ".zhistory")},
+		&HistorySource{Format: HistoryFormatFish, Path: filepath.Join(fishDataDir, "fish", "fish_history")},
+	)
+
+	sources := []*HistorySource{}
+	discoveredPaths := map[string]bool{}
+	for _, candidate := range candidates {
+		path := filepath.Clean(candidate.Path)
+		if discoveredPaths[path] {
+			continue
+		}
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		discoveredPaths[path] = true
+		sources = append(sources, &HistorySource{Format: candidate.Format, Path: path})
+	}
+	return sources
+}
+
+// GetHistoryFormat deduces the format of a history file from its name,
+// the shell is used when the name does not reference a shell
+func GetHistoryFormat(historyFilePath string, shell string) HistoryFormat {
+	name := strings.ToLower(filepath.Base(historyFilePath))
+	switch {
+	case strings.Contains(name, "fish"):
+		return HistoryFormatFish
+	case strings.Contains(name, "zsh") || strings.Contains(name, "zhistory"):
+		return HistoryFormatZsh
+	case strings.Contains(name, "bash"):
+		return HistoryFormatBash
+	}
+	switch filepath.Base(shell) {
+	case string(HistoryFormatZsh):
+		return HistoryFormatZsh
+	case string(HistoryFormatFish):
+		return HistoryFormatFish
+	default:
+		return HistoryFormatBash
+	}
+}
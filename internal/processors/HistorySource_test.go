@@ -0,0 +1,47 @@
+package processors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHistoryFormat(t *testing.T) {
+	assert.Equal(t, HistoryFormatZsh, GetHistoryFormat("/home/user/.zsh_history", "/bin/bash"))
+	assert.Equal(t, HistoryFormatZsh, GetHistoryFormat("/home/user/.zhistory", ""))
+	assert.Equal(t, HistoryFormatFish, GetHistoryFormat("/home/user/.local/share/fish/fish_history", ""))
+	assert.Equal(t, HistoryFormatBash, GetHistoryFormat("/home/user/.bash_history", "/usr/bin/zsh"))
+	assert.Equal(t, HistoryFormatZsh, GetHistoryFormat("/home/user/.history", "/usr/bin/zsh"))
+	assert.Equal(t, HistoryFormatBash, GetHistoryFormat("/home/user/.history", ""))
+}
+
+func TestDiscoverHistorySources(t *testing.T) {
+	homeDir := t.TempDir()
+	histFile := filepath.Join(homeDir, ".history")
+	for _, path := range []string{
+		histFile,
+		filepath.Join(homeDir, ".bash_history"),
+		filepath.Join(homeDir, ".zsh_history"),
+		filepath.Join(homeDir, ".local", "share", "fish", "fish_history"),
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("ls -la\n"), FileMode))
+	}
+	env := map[string]string{
+		"HISTFILE": histFile,
+		"SHELL":    "/bin/zsh",
+		"ZDOTDIR":  homeDir + "/",
+	}
+
+	sources := DiscoverHistorySources(homeDir, func(name string) string { return env[name] })
+
+	assert.Equal(t, []*HistorySource{
+		{Format: HistoryFormatZsh, Path: histFile},
+		{Format: HistoryFormatBash, Path: filepath.Join(homeDir, ".bash_history")},
+		{Format: HistoryFormatZsh, Path: filepath.Join(homeDir, ".zsh_history")},
+		{Format: HistoryFormatFish, Path: filepath.Join(homeDir, ".local", "share", "fish", "fish_history")},
+	}, sources)
+}
@@ -0,0 +1,172 @@
+package processors
+
+import (
+	"bufio"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+const (
+	naviTagsPrefix        = "%"
+	naviDescriptionPrefix = "#"
+	naviCommentPrefix     = ";"
+	naviVariablePrefix    = "$"
+	naviExtendPrefix      = "@"
+	naviCodeFencePrefix   = "```"
+	// naviVariableOptionsSeparator separates the command giving the values
+	// of a variable from the fzf options
+	naviVariableOptionsSeparator = "---"
+	// maxSnippetLineLength is the maximum length of a line of a snippets file
+	maxSnippetLineLength = 1024 * 1024
+)
+
+// naviCheatParser keeps the state of the parsing of a navi cheat file.
+// A cheat file is made of sections starting with a "% tag1, tag2" line,
+// each command is preceded by its "# description" line and the "$ name: command"
+// lines of the section give the command listing the values of a variable.
+type naviCheatParser struct {
+	file                  *SnippetFile
+	tags                  []string
+	sectionSnippets       []*Snippet
+	sectionVariables      []*SnippetVariable
+	description           string
+	descriptionLine       int
+	commandBuilder        strings.Builder
+	commandLine           int
+	hasPendingDescription bool
+}
+
+// ParseNaviCheat parses a navi cheat file (.cheat), see https://github.com/denisidoro/navi
+func ParseNaviCheat(reader io.Reader) (*SnippetFile, error) {
+	parser := &naviCheatParser{
+		file:                  newSnippetFile(),
+		tags:                  []string{},
+		sectionSnippets:       []*Snippet{},
+		sectionVariables:      []*SnippetVariable{},
+		description:           "",
+		descriptionLine:       0,
+		commandBuilder:        strings.Builder{},
+		commandLine:           0,
+		hasPendingDescription: false,
+	}
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, maxSnippetLineLength)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		parser.parseLine(scanner.Text(), lineNumber)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	parser.endSection()
+	return parser.file, nil
+}
+
+func (p *naviCheatParser) parseLine(line string, lineNumber int) {
+	trimmedLine := strings.TrimSpace(line)
+	switch {
+	case trimmedLine == "":
+		p.endCommand()
+	case strings.HasPrefix(trimmedLine, naviTagsPrefix):
+		p.endSection()
+		p.tags = splitNaviTags(strings.TrimPrefix(trimmedLine, naviTagsPrefix))
+	case strings.HasPrefix(trimmedLine, naviDescriptionPrefix):
+		p.endCommand()
+		p.skipPendingDescription()
+		p.description = strings.TrimSpace(strings.TrimPrefix(trimmedLine, naviDescriptionPrefix))
+		p.descriptionLine = lineNumber
+		p.hasPendingDescription = true
+	case strings.HasPrefix(trimmedLine, naviVariablePrefix):
+		p.endCommand()
+		p.parseVariable(strings.TrimPrefix(trimmedLine, naviVariablePrefix), lineNumber)
+	case strings.HasPrefix(trimmedLine, naviCommentPrefix),
+		strings.HasPrefix(trimmedLine, naviCodeFencePrefix):
+		// comments and markdown code fences are ignored
+	case strings.HasPrefix(trimmedLine, naviExtendPrefix):
+		slog.Debug("navi cheat extension ignored", "lineNumber", lineNumber, "line", trimmedLine)
+	default:
+		if p.commandBuilder.Len() == 0 {
+			p.commandLine = lineNumber
+		} else {
+			p.commandBuilder.WriteString("\n")
+		}
+		p.commandBuilder.WriteString(strings.TrimRight(line, " \t"))
+	}
+}
+
+// parseVariable parses a "name: command --- fzf options" variable line
+func (p *naviCheatParser) parseVariable(definition string, lineNumber int) {
+	name, command, found := strings.Cut(definition, ":")
+	name = strings.TrimSpace(name)
+	if !found || name == "" {
+		p.file.skip(lineNumber, "invalid navi variable definition")
+		return
+	}
+	command, _, _ = strings.Cut(command, naviVariableOptionsSeparator)
+	description := ""
+	if command = strings.TrimSpace(command); command != "" {
+		description = "values of: " + command
+	}
+	p.sectionVariables = append(p.sectionVariables, &SnippetVariable{
+		Name:          name,
+		Description:   description,
+		AllowedValues: []string{},
+	})
+}
+
+// endCommand adds the command being read to the current section
+func (p *naviCheatParser) endCommand() {
+	if p.commandBuilder.Len() == 0 {
+		return
+	}
+	script := strings.TrimSpace(p.commandBuilder.String())
+	p.commandBuilder.Reset()
+	lineNumber := p.commandLine
+	if p.hasPendingDescription {
+		lineNumber = p.descriptionLine
+	}
+	p.sectionSnippets = append(p.sectionSnippets, &Snippet{
+		Title:       p.description,
+		Description: "",
+		Script:      script,
+		Tags:        p.tags,
+		Variables:   []*SnippetVariable{},
+		LineNumber:  lineNumber,
+	})
+	p.description = ""
+	p.hasPendingDescription = false
+}
+
+func (p *naviCheatParser) skipPendingDescription() {
+	if p.hasPendingDescription {
+		p.file.skip(p.descriptionLine, "description without command: "+p.description)
+	}
+	p.description = ""
+	p.hasPendingDescription = false
+}
+
+// endSection adds the commands of the section to the file,
+// the variables of the section being available to all its commands
+func (p *naviCheatParser) endSection() {
+	p.endCommand()
+	p.skipPendingDescription()
+	for _, snippet := range p.sectionSnippets {
+		snippet.Variables = p.sectionVariables
+		p.file.Snippets = append(p.file.Snippets, snippet)
+	}
+	p.sectionSnippets = []*Snippet{}
+	p.sectionVariables = []*SnippetVariable{}
+	p.tags = []string{}
+}
+
+func splitNaviTags(value string) []string {
+	tags := []string{}
+	for _, tag := range strings.Split(value, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
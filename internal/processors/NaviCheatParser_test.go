@@ -0,0 +1,56 @@
+package processors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNaviCheat(t *testing.T) {
+	cheat := `% git, code
+
+# Change branch
+git checkout <branch>
+
+$ branch: git branch | awk '{print $NF}' --- --column 1
+
+; a comment
+# Show the log
+# of a file
+git log \
+  --follow -- <file>
+
+@ other, cheat
+
+% docker
+echo "no description"
+# dangling description
+`
+	file, err := ParseNaviCheat(strings.NewReader(cheat))
+	require.NoError(t, err)
+	require.Len(t, file.Snippets, 3)
+
+	assert.Equal(t, "Change branch", file.Snippets[0].Title)
+	assert.Equal(t, "git checkout <branch>", file.Snippets[0].Script)
+	assert.Equal(t, []string{"git", "code"}, file.Snippets[0].Tags)
+	assert.Equal(t, 3, file.Snippets[0].LineNumber)
+	require.Len(t, file.Snippets[0].Variables, 1)
+	assert.Equal(t, "branch", file.Snippets[0].Variables[0].Name)
+	assert.Equal(t, "values of: git branch | awk '{print $NF}'", file.Snippets[0].Variables[0].Description)
+
+	assert.Equal(t, "of a file", file.Snippets[1].Title)
+	assert.Equal(t, "git log \\\n  --follow -- <file>", file.Snippets[1].Script)
+	assert.Len(t, file.Snippets[1].Variables, 1)
+
+	assert.Equal(t, "", file.Snippets[2].Title)
+	assert.Equal(t, `echo "no description"`, file.Snippets[2].Script)
+	assert.Equal(t, []string{"docker"}, file.Snippets[2].Tags)
+	assert.Empty(t, file.Snippets[2].Variables)
+
+	require.Len(t, file.Skipped, 2)
+	assert.Equal(t, 9, file.Skipped[0].LineNumber)
+	assert.Equal(t, "description without command: Show the log", file.Skipped[0].Reason)
+	assert.Equal(t, 18, file.Skipped[1].LineNumber)
+}
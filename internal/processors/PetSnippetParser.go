@@ -0,0 +1,116 @@
+package processors
+
+import (
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+const (
+	petMaxFileSize = 10 * 1024 * 1024
+	// petSnippetHeader starts each snippet of the pet snippets file
+	petSnippetHeader = "[[snippets]]"
+)
+
+// petParameterRegexp matches the <name=default> and <name=|_value1_||_value2_|> parameters of pet
+//
+//nolint:gochecknoglobals // compiled once
+var petParameterRegexp = regexp.MustCompile(`<([A-Za-z_][A-Za-z0-9_-]*)=([^>]*)>`)
+
+// petParameterValuesRegexp matches the values of a pet parameter with choices
+//
+//nolint:gochecknoglobals // compiled once
+var petParameterValuesRegexp = regexp.MustCompile(`\|_(.*?)_\|`)
+
+// petSnippetsFile is the content of the pet snippets file, the other tables are ignored
+type petSnippetsFile struct {
+	Snippets []petSnippet `toml:"snippets"`
+}
+
+// petSnippet is a snippet of the pet snippets file
+type petSnippet struct {
+	Description string   `toml:"description"`
+	Command     string   `toml:"command"`
+	Output      string   `toml:"output"`
+	Tag         []string `toml:"tag"`
+}
+
+// ParsePetSnippets parses a pet snippets file (snippet.toml), see https://github.com/knqyf263/pet.
+// Pet parameters with a default value are converted to {{name:default}} placeholders
+// and parameters with choices to <name> placeholders having allowed values.
+func ParsePetSnippets(reader io.Reader) (*SnippetFile, error) {
+	content, err := io.ReadAll(io.LimitReader(reader, petMaxFileSize))
+	if err != nil {
+		return nil, err
+	}
+	var petFile petSnippetsFile
+	if _, err := toml.Decode(string(content), &petFile); err != nil {
+		return nil, err
+	}
+	lineNumbers := getPetSnippetLineNumbers(string(content))
+	file := newSnippetFile()
+	for i, petSnippet := range petFile.Snippets {
+		lineNumber := 0
+		if i < len(lineNumbers) {
+			lineNumber = lineNumbers[i]
+		}
+		if strings.TrimSpace(petSnippet.Command) == "" {
+			file.skip(lineNumber, "snippet without command")
+			continue
+		}
+		snippet := &Snippet{
+			Title:       petSnippet.Description,
+			Description: "",
+			Script:      petSnippet.Command,
+			Tags:        petSnippet.Tag,
+			Variables:   []*SnippetVariable{},
+			LineNumber:  lineNumber,
+		}
+		if snippet.Tags == nil {
+			snippet.Tags = []string{}
+		}
+		if petSnippet.Output != "" {
+			snippet.Description = "Output:\n" + petSnippet.Output
+		}
+		convertPetParameters(snippet)
+		file.Snippets = append(file.Snippets, snippet)
+	}
+	return file, nil
+}
+
+// getPetSnippetLineNumbers returns the line numbers of the headers of the snippets,
+// the toml decoder does not give the position of the tables
+func getPetSnippetLineNumbers(content string) []int {
+	lineNumbers := []int{}
+	for i, line := range strings.Split(content, "\n") {
+		line, _, _ = strings.Cut(line, "#")
+		if strings.ReplaceAll(strings.TrimSpace(line), " ", "") == petSnippetHeader {
+			lineNumbers = append(lineNumbers, i+1)
+		}
+	}
+	return lineNumbers
+}
+
+// convertPetParameters converts the pet parameters of the script to placeholders
+func convertPetParameters(snippet *Snippet) {
+	snippet.Script = petParameterRegexp.ReplaceAllStringFunc(snippet.Script, func(match string) string {
+		submatches := petParameterRegexp.FindStringSubmatch(match)
+		name, defaultValue := submatches[1], submatches[2]
+		values := petParameterValuesRegexp.FindAllStringSubmatch(defaultValue, -1)
+		if len(values) == 0 {
+			return "{{" + name + ":" + defaultValue + "}}"
+		}
+		variable := &SnippetVariable{
+			Name:          name,
+			Description:   "",
+			AllowedValues: make([]string, 0, len(values)),
+		}
+		for _, value := range values {
+			variable.AllowedValues = append(variable.AllowedValues, value[1])
+		}
+		snippet.Variables = append(snippet.Variables, variable)
+		return "<" + name + ">"
+	})
+}
@@ -0,0 +1,70 @@
+package processors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/BurntSushi/toml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePetSnippets(t *testing.T) {
+	snippets := `# pet snippets
+[[snippets]]
+  description = "ping \"google\""
+  command = "ping <host=8.8.8.8> -c <count=|_1_||_5_|>"
+  tag = ["network", 'google']
+  output = ""
+
+[[snippets]]
+  description = 'multi-line'
+  command = """
+for f in *.log; do \
+    gzip "$f"
+done"""
+  tag = [
+    "files", # compress
+  ]
+  output = "compressed\tfiles"
+
+[[snippets]]
+  description = "no command"
+  tag = []
+
+[other]
+  command = "ignored"
+`
+	file, err := ParsePetSnippets(strings.NewReader(snippets))
+	require.NoError(t, err)
+	require.Len(t, file.Snippets, 2)
+
+	assert.Equal(t, `ping "google"`, file.Snippets[0].Title)
+	assert.Equal(t, "ping {{host:8.8.8.8}} -c <count>", file.Snippets[0].Script)
+	assert.Equal(t, []string{"network", "google"}, file.Snippets[0].Tags)
+	assert.Equal(t, "", file.Snippets[0].Description)
+	require.Len(t, file.Snippets[0].Variables, 1)
+	assert.Equal(t, "count", file.Snippets[0].Variables[0].Name)
+	assert.Equal(t, []string{"1", "5"}, file.Snippets[0].Variables[0].AllowedValues)
+	assert.Equal(t, 2, file.Snippets[0].LineNumber)
+
+	assert.Equal(t, "multi-line", file.Snippets[1].Title)
+	assert.Equal(t, "for f in *.log; do gzip \"$f\"\ndone", file.Snippets[1].Script)
+	assert.Equal(t, []string{"files"}, file.Snippets[1].Tags)
+	assert.Equal(t, "Output:\ncompressed\tfiles", file.Snippets[1].Description)
+
+	require.Len(t, file.Skipped, 1)
+	assert.Equal(t, 19, file.Skipped[0].LineNumber)
+	assert.Equal(t, "snippet without command", file.Skipped[0].Reason)
+}
+
+func TestParsePetSnippetsErrors(t *testing.T) {
+	var parseErr toml.ParseError
+	_, err := ParsePetSnippets(strings.NewReader("[[snippets]]\ncommand = \"ls\n"))
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 2, parseErr.Position.Line)
+
+	_, err = ParsePetSnippets(strings.NewReader("[[snippets]]\ncommand \"ls\"\n"))
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 2, parseErr.Position.Line)
+}
@@ -0,0 +1,46 @@
+package processors
+
+// Snippet is a command read from the cheatsheets or snippets file of another tool,
+// its placeholders already use the syntax of the bookmarker (<name> or {{name:default}})
+type Snippet struct {
+	Title       string
+	Description string
+	Script      string
+	Tags        []string
+	Variables   []*SnippetVariable
+	// LineNumber is the line of the file where the snippet starts
+	LineNumber int
+}
+
+// SnippetVariable describes a placeholder of a snippet
+type SnippetVariable struct {
+	Name          string
+	Description   string
+	AllowedValues []string
+}
+
+// SkippedSnippet is an entry of a snippets file that cannot be imported
+type SkippedSnippet struct {
+	Reason     string
+	LineNumber int
+}
+
+// SnippetFile is the result of the parsing of a snippets file
+type SnippetFile struct {
+	Snippets []*Snippet
+	Skipped  []*SkippedSnippet
+}
+
+func newSnippetFile() *SnippetFile {
+	return &SnippetFile{
+		Snippets: []*Snippet{},
+		Skipped:  []*SkippedSnippet{},
+	}
+}
+
+func (f *SnippetFile) skip(lineNumber int, reason string) {
+	f.Skipped = append(f.Skipped, &SkippedSnippet{
+		Reason:     reason,
+		LineNumber: lineNumber,
+	})
+}
@@ -0,0 +1,35 @@
+package processors
+
+const (
+	// zshMeta is the byte written by zsh before a metafied byte
+	zshMeta = 0x83
+	// zshMetaMask is xored with the byte following zshMeta to get the original byte
+	zshMetaMask = 0x20
+)
+
+// unmetafy decodes a line of a zsh history file. Zsh writes the null byte and
+// the bytes from 0x83 to 0xA2 (the meta byte and the zsh tokens, eg: bytes of utf-8
+// multi bytes characters) as the meta byte 0x83 followed by the original byte xored with 0x20.
+func unmetafy(line []byte) []byte {
+	metaIndex := -1
+	for i, char := range line {
+		if char == zshMeta {
+			metaIndex = i
+			break
+		}
+	}
+	if metaIndex < 0 {
+		return line
+	}
+	decoded := make([]byte, 0, len(line))
+	decoded = append(decoded, line[:metaIndex]...)
+	for i := metaIndex; i < len(line); i++ {
+		if line[i] == zshMeta && i+1 < len(line) {
+			i++
+			decoded = append(decoded, line[i]^zshMetaMask)
+			continue
+		}
+		decoded = append(decoded, line[i])
+	}
+	return decoded
+}
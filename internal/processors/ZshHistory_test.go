@@ -0,0 +1,43 @@
+package processors
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmetafy(t *testing.T) {
+	// "é" is 0xC3 0xA9 in utf-8, both bytes are metafied by zsh
+	assert.Equal(t, []byte("echo é"), unmetafy([]byte{'e', 'c', 'h', 'o', ' ', 0x83, 0xE3, 0x83, 0x89}))
+	assert.Equal(t, []byte("echo"), unmetafy([]byte("echo")))
+	// a meta byte at the end of the line is kept as is
+	assert.Equal(t, []byte{'a', 0x83}, unmetafy([]byte{'a', 0x83}))
+}
+
+func TestParseZshHistory(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), ".zsh_history")
+	content := []byte(": 1712345678:3;git commit -m \"fix \x83\xe3\x83\x89\"\n" +
+		": 1712345680:0;for i in 1 2; do\\\n  echo $i\\\ndone\n")
+	require.NoError(t, os.WriteFile(historyFile, content, FileMode))
+
+	var commands []HistoryCommand
+	_, err := NewHistoryIngestor().ParseHistory(
+		&HistorySource{Format: HistoryFormatZsh, Path: historyFile},
+		nil,
+		func(cmd HistoryCommand) (CommandImportedStatus, error) {
+			commands = append(commands, cmd)
+			return CommandImportedStatusNew, nil
+		},
+	)
+
+	require.NoError(t, err)
+	require.Len(t, commands, 2)
+	assert.Equal(t, time.Unix(1712345678, 0).UTC(), commands[0].Timestamp)
+	assert.Equal(t, 3, commands[0].Elapsed)
+	assert.Equal(t, time.Unix(1712345680, 0).UTC(), commands[1].Timestamp)
+	assert.Equal(t, "for i in 1 2; do\n  echo $i\ndone", commands[1].Command)
+}
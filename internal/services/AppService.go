@@ -1,13 +1,22 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"log/slog"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/fchastanet/shell-command-bookmarker/internal/args"
 	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 	"github.com/mattn/go-isatty"
 )
 
@@ -19,15 +28,31 @@ type AppService struct {
 	LoggerService           *LoggerService
 	ShellIntegrationService *ShellIntegrationService
 	ShellDetectionService   ShellDetectionServiceInterface
+	BookmarkFileService     *BookmarkFileService
+	TaskService             *TaskService
 	cleanupFunc             func()
+	// historyIngestionReports receives the progress and then the result of the history ingestion
+	historyIngestionReports chan HistoryIngestionReport
+	// stopHistoryWatch stops the watch of the history files started by Main
+	stopHistoryWatch context.CancelFunc
+	// historyWatch waits for the end of the history ingestion started by Main,
+	// the database must not be closed while a batch is being written
+	historyWatch sync.WaitGroup
 }
 
+// historyIngestionReportsBufferSize is the number of progress reports kept until the UI reads them
+const historyIngestionReportsBufferSize = 16
+
 type AppServiceConfig struct {
-	DBPath       string
-	SqliteSchema string
-	OutputFile   string // Flag to indicate if we're in shell selection mode
-	MaxTasks     int
-	Debug        bool
+	Migrations fs.FS
+	DBPath     string
+	OutputFile string // Flag to indicate if we're in shell selection mode
+	// ConfigFile is the path of the yaml configuration file, the default one is used if empty
+	ConfigFile string
+	MaxTasks   int
+	Debug      bool
+	// Headless is true for the sub commands of the command line, the log files are not used
+	Headless bool
 }
 
 func NewAppService() *AppService {
@@ -40,6 +65,11 @@ func NewAppService() *AppService {
 		LoggerService:           nil,
 		ShellIntegrationService: nil,
 		ShellDetectionService:   nil,
+		BookmarkFileService:     nil,
+		TaskService:             nil,
+		historyIngestionReports: nil,
+		stopHistoryWatch:        func() {},
+		historyWatch:            sync.WaitGroup{},
 	}
 }
 
@@ -51,12 +81,36 @@ func (app *AppService) Init(cfg AppServiceConfig) error {
 	app.Config = &cfg
 
 	app.LoggerService = NewLoggerService(cfg.Debug)
+	if cfg.Headless {
+		app.LoggerService = NewHeadlessLoggerService(cfg.Debug)
+	}
 	if err := app.LoggerService.Init(); err != nil {
 		slog.Error("Error initializing logger service", "error", err)
 		return err
 	}
 
-	app.DBService = NewDBService(cfg.DBPath, cfg.SqliteSchema)
+	config, err := LoadConfig(cfg.ConfigFile)
+	if err != nil {
+		slog.Error("Error loading configuration file", "error", err)
+		return err
+	}
+	ingestionFilter, err := NewIngestionFilter(config.Ingestion)
+	if err != nil {
+		slog.Error("Error loading ingestion filter", "error", err)
+		return err
+	}
+	secretScanner, err := NewSecretScanner(config.Secrets)
+	if err != nil {
+		slog.Error("Error loading secret scanner", "error", err)
+		return err
+	}
+	dangerClassifier, err := NewDangerClassifier(config.Danger)
+	if err != nil {
+		slog.Error("Error loading danger classifier", "error", err)
+		return err
+	}
+
+	app.DBService = NewDBService(cfg.DBPath, cfg.Migrations)
 
 	// cleanup function to be invoked when app is terminated.
 	cleanup := func() {
@@ -74,7 +128,7 @@ func (app *AppService) Init(cfg AppServiceConfig) error {
 		return err
 	}
 
-	app.LintService = NewLintService()
+	app.LintService = NewLintService(WithMaxTasks(cfg.MaxTasks))
 	if err := app.LintService.Init(); err != nil {
 		if errors.Is(err, ErrShellCheckNotFound) {
 			slog.Warn("shellcheck command not found in PATH. Linting will be disabled.", "error", err)
@@ -89,46 +143,94 @@ func (app *AppService) Init(cfg AppServiceConfig) error {
 		app.DBService,
 		app.LintService,
 	)
+	app.HistoryService.SetIngestionFilter(ingestionFilter)
+	app.HistoryService.SetSecretScanner(secretScanner)
+	app.HistoryService.SetDangerClassifier(dangerClassifier)
 	if err := app.HistoryService.Init(); err != nil {
 		slog.Error("Error initializing history service", "error", err)
 	}
+	if _, err := app.HistoryService.UpdateCommandRisks(); err != nil {
+		slog.Error("Error classifying the risk of the commands", "error", err)
+	}
 	slog.Info("AppService initialized successfully", "dbPath", cfg.DBPath, "debug", cfg.Debug)
 
 	app.ShellIntegrationService = NewShellIntegrationService()
 	app.ShellDetectionService = NewShellDetectionService()
+	app.BookmarkFileService = NewBookmarkFileService(app.HistoryService, app.DBService)
+	app.TaskService = NewShellTaskService(
+		app.ShellDetectionService.DetectShell(), cfg.MaxTasks, app.HistoryService,
+	)
 
 	return nil
 }
 
-func (app *AppService) Main(cli *args.Cli, sqliteSchema string) error {
+func (app *AppService) Main(cli *args.Cli, migrations fs.FS) error {
 	if err := app.IsTerminalCompatible(); err != nil {
 		slog.Error("Terminal compatibility check failed", "error", err)
 		return err
 	}
 
 	err := app.Init(AppServiceConfig{
-		SqliteSchema: sqliteSchema,
-		MaxTasks:     1,
-		DBPath:       string(cli.DBPath),
-		Debug:        cli.Debug,
-		OutputFile:   cli.OutputFile,
+		Migrations: migrations,
+		MaxTasks:   cli.MaxTasks,
+		DBPath:     string(cli.DBPath),
+		ConfigFile: cli.ConfigFile,
+		Debug:      cli.Debug,
+		OutputFile: cli.OutputFile,
+		Headless:   false,
 	})
 	if err != nil {
 		slog.Error("Error initializing AppService", "error", err)
 		return err
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	app.stopHistoryWatch = cancel
+	reports := make(chan HistoryIngestionReport, historyIngestionReportsBufferSize)
+	app.historyIngestionReports = reports
+	app.historyWatch.Add(1)
 	go func() {
-		if err := app.GetHistoryService().IngestHistory(); err != nil {
+		defer app.historyWatch.Done()
+		defer close(reports)
+		report, err := app.GetHistoryService().IngestHistory(ctx, func(progress HistoryIngestionReport) {
+			select {
+			case reports <- progress:
+			default:
+				// progress dropped as the UI is late, the next one is more accurate
+			}
+		})
+		if ctx.Err() != nil {
+			// the application is quitting
+			return
+		}
+		if err != nil {
 			slog.Error("Error ingesting history", "error", err)
-			// Depending on requirements, you might want to signal this error back
-			// to the main thread or handle it differently. For now, just logging.
 		}
+		select {
+		case reports <- *report:
+		case <-ctx.Done():
+			return
+		}
+
+		// the commands typed in other terminals are ingested while the application is running
+		app.GetHistoryService().WatchHistory(ctx, HistoryWatchInterval, func(report *HistoryIngestionReport) {
+			select {
+			case reports <- *report:
+			case <-ctx.Done():
+			}
+		})
 	}()
 
 	return nil
 }
 
+// GetHistoryIngestionReports returns the channel receiving the progress of the history ingestion
+// started by Main, then the report of each ingestion of the commands appended to the history files
+// while the application is running, nil if no ingestion has been started
+func (app *AppService) GetHistoryIngestionReports() <-chan HistoryIngestionReport {
+	return app.historyIngestionReports
+}
+
 func (*AppService) IsTerminalCompatible() error {
 	if !isatty.IsTerminal(os.Stdout.Fd()) || isatty.IsCygwinTerminal(os.Stdout.Fd()) {
 		slog.Error("This program requires a terminal to run. Please run it in a terminal emulator.")
@@ -169,13 +271,246 @@ func (app *AppService) HandleShellIntegrationScriptGeneration(cli *args.Cli) boo
 	return true
 }
 
+// HandleImportExport exports or imports the commands if requested by the command line,
+// it returns true if the application has to quit without launching the UI
+func (app *AppService) HandleImportExport(cli *args.Cli, migrations fs.FS) (bool, error) {
+	if cli.Export == "" && cli.Import == "" && cli.ImportNavi == "" && cli.ImportPet == "" &&
+		cli.ExportFunctions == "" && !cli.PreviewFilters {
+		return false, nil
+	}
+	err := app.Init(AppServiceConfig{
+		Migrations: migrations,
+		MaxTasks:   cli.MaxTasks,
+		DBPath:     string(cli.DBPath),
+		ConfigFile: cli.ConfigFile,
+		Debug:      cli.Debug,
+		OutputFile: "",
+		Headless:   true,
+	})
+	if err != nil {
+		slog.Error("Error initializing AppService", "error", err)
+		return true, err
+	}
+	switch {
+	case cli.Export != "":
+		return true, app.exportCommands(cli)
+	case cli.ExportFunctions != "":
+		return true, app.exportFunctions(cli)
+	case cli.ImportNavi != "":
+		return true, app.importSnippetFiles(cli, cli.ImportNavi, SnippetFileFormatNavi)
+	case cli.ImportPet != "":
+		return true, app.importSnippetFiles(cli, cli.ImportPet, SnippetFileFormatPet)
+	case cli.PreviewFilters:
+		return true, app.previewIngestionFilters()
+	default:
+		return true, app.importCommands(cli)
+	}
+}
+
+// HandleCommand runs the sub command of the command line without launching the UI,
+// it returns true if the application has to quit
+func (app *AppService) HandleCommand(cli *args.Cli, migrations fs.FS) (bool, error) {
+	if cli.Command == args.CommandTUI {
+		return false, nil
+	}
+	err := app.Init(AppServiceConfig{
+		Migrations: migrations,
+		MaxTasks:   cli.MaxTasks,
+		DBPath:     string(cli.DBPath),
+		ConfigFile: cli.ConfigFile,
+		Debug:      cli.Debug,
+		OutputFile: "",
+		Headless:   true,
+	})
+	if err != nil {
+		slog.Error("Error initializing AppService", "error", err)
+		return true, err
+	}
+	cliService := NewCliService(
+		app.HistoryService, app.DBService, app.TaskService, os.Stdin, os.Stdout, os.Stderr,
+	)
+	switch cli.Command {
+	case args.CommandList:
+		return true, cliService.List(
+			ParseCommandStatuses(cli.List.Status), cli.List.Tag, cli.List.Folder, CommandOutputFormat(cli.List.Output),
+		)
+	case args.CommandSearch:
+		return true, cliService.Search(
+			strings.Join(cli.Search.Query, " "), ParseCommandStatuses(cli.Search.Status),
+			CommandOutputFormat(cli.Search.Output),
+		)
+	case args.CommandShow:
+		return true, cliService.Show(cli.Show.ID, CommandOutputFormat(cli.Show.Output))
+	case args.CommandAdd:
+		return true, cliService.Add(cli.Add.Script, cli.Add.Title, cli.Add.Description, cli.Add.Tag, cli.Add.Folder)
+	case args.CommandEdit:
+		return true, cliService.Edit(cli.Edit.ID)
+	case args.CommandDelete:
+		return true, cliService.Delete(cli.Delete.IDs)
+	case args.CommandRestore:
+		return true, cliService.Restore(cli.Restore.IDs)
+	case args.CommandRun:
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		return true, cliService.Run(ctx, cli.Run.ID, cli.Run.Set, cli.Run.Yes)
+	default:
+		return true, &UnknownCommandError{Command: cli.Command}
+	}
+}
+
+func (app *AppService) importSnippetFiles(cli *args.Cli, path string, format SnippetFileFormat) error {
+	reports, err := app.BookmarkFileService.ImportSnippetFiles(path, format, ImportOptions{
+		OnConflict: ImportConflictStrategy(cli.OnConflict),
+		DryRun:     cli.DryRun,
+		Lint:       true,
+	})
+	for _, report := range reports {
+		fmt.Print(report.String())
+	}
+	return err
+}
+
+// previewIngestionFilters displays the commands of the history files that are not in the database
+// with the status the ingestion filters would give them
+func (app *AppService) previewIngestionFilters() error {
+	report, err := app.HistoryService.PreviewIngestion(
+		app.HistoryService.getIngestionFilter(),
+		func(cmd processors.HistoryCommand, status processors.CommandImportedStatus) {
+			fmt.Printf("%-12s %s\n", status, strings.ReplaceAll(cmd.Command, "\n", "\\n"))
+		},
+	)
+	fmt.Printf(
+		"%d command(s) would be imported, %d filtered out, %d skipped, %d already existing\n",
+		report.ImportedCount, report.FilteredOutCount, report.SkippedCount, report.AlreadyExistsCount,
+	)
+	return err
+}
+
+func (app *AppService) exportCommands(cli *args.Cli) error {
+	statuses := []models.CommandStatus{models.CommandStatusSaved}
+	if cli.ExportAll {
+		statuses = append(statuses, models.CommandStatusImported, models.CommandStatusDeleted)
+	}
+	format := GetBookmarkFileFormat(cli.Export, BookmarkFileFormat(cli.Format))
+	if cli.Export == "-" {
+		_, err := app.BookmarkFileService.Export(os.Stdout, format, statuses...)
+		return err
+	}
+	file, err := os.Create(cli.Export)
+	if err != nil {
+		slog.Error("Error creating export file", "file", cli.Export, "error", err)
+		return err
+	}
+	defer file.Close()
+	count, err := app.BookmarkFileService.Export(file, format, statuses...)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d command(s) exported to %s\n", count, cli.Export)
+	return nil
+}
+
+func (app *AppService) exportFunctions(cli *args.Cli) error {
+	commands, err := app.DBService.GetCommands(models.CommandStatusSaved)
+	if err != nil {
+		slog.Error("Error retrieving commands to export as functions", "error", err)
+		return err
+	}
+	if cli.ExportFunctions == "-" {
+		shellType := app.GetFunctionsShellType(cli.ExportFunctions, cli.FunctionsShell)
+		return app.ShellIntegrationService.GenerateFunctions(os.Stdout, shellType, cli.FunctionsPrefix, commands)
+	}
+	shellType, err := app.WriteFunctionsFile(cli.ExportFunctions, cli.FunctionsShell, cli.FunctionsPrefix, commands)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%d command(s) exported as %s functions to %s\n", len(commands), shellType, cli.ExportFunctions)
+	return nil
+}
+
+// GetFunctionsShellType returns the shell of the generated functions file,
+// for the auto value it is deduced from the file extension or from the current shell
+func (app *AppService) GetFunctionsShellType(filePath string, shell string) ShellType {
+	if shellType := ShellType(shell); shellType == ShellTypeBash || shellType == ShellTypeZsh {
+		return shellType
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".zsh":
+		return ShellTypeZsh
+	case ".bash":
+		return ShellTypeBash
+	}
+	if app.ShellDetectionService.DetectShell() == ShellTypeZsh {
+		return ShellTypeZsh
+	}
+	return ShellTypeBash
+}
+
+// WriteFunctionsFile writes the commands as shell functions in the file (~ is expanded),
+// the file is overwritten so it can be regenerated after the commands are edited
+func (app *AppService) WriteFunctionsFile(
+	filePath string, shell string, namePrefix string, commands []*models.Command,
+) (ShellType, error) {
+	shellType := app.GetFunctionsShellType(filePath, shell)
+	file, err := os.Create(expandHomeDir(filePath))
+	if err != nil {
+		slog.Error("Error creating functions file", "file", filePath, "error", err)
+		return shellType, err
+	}
+	defer file.Close()
+	if err := app.ShellIntegrationService.GenerateFunctions(file, shellType, namePrefix, commands); err != nil {
+		slog.Error("Error generating functions file", "file", filePath, "error", err)
+		return shellType, err
+	}
+	slog.Info("Functions file generated", "file", filePath, "shell", shellType, "count", len(commands))
+	return shellType, nil
+}
+
+func (app *AppService) importCommands(cli *args.Cli) error {
+	reader := io.Reader(os.Stdin)
+	if cli.Import != "-" {
+		file, err := os.Open(cli.Import)
+		if err != nil {
+			slog.Error("Error opening import file", "file", cli.Import, "error", err)
+			return err
+		}
+		defer file.Close()
+		reader = file
+	}
+	report, err := app.BookmarkFileService.Import(
+		reader,
+		GetBookmarkFileFormat(cli.Import, BookmarkFileFormat(cli.Format)),
+		ImportOptions{
+			OnConflict: ImportConflictStrategy(cli.OnConflict),
+			DryRun:     cli.DryRun,
+			Lint:       false,
+		},
+	)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report.String())
+	return nil
+}
+
 // GetHistoryService returns the HistoryService
 func (app *AppService) GetHistoryService() *HistoryService {
 	return app.HistoryService
 }
 
-// Cleanup executes the stored cleanup function
+// GetTaskService returns the TaskService
+func (app *AppService) GetTaskService() *TaskService {
+	return app.TaskService
+}
+
+// Cleanup stops the history watch and the tasks, then executes the stored cleanup function
 func (app *AppService) Cleanup() {
+	app.stopHistoryWatch()
+	// the ingestion in progress is finished before closing the database
+	app.historyWatch.Wait()
+	if app.TaskService != nil {
+		app.TaskService.Stop()
+	}
 	if app.cleanupFunc != nil {
 		app.cleanupFunc()
 	}
@@ -0,0 +1,562 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"gopkg.in/yaml.v3"
+)
+
+// BookmarkFileFormat is the encoding of a bookmark file
+type BookmarkFileFormat string
+
+const (
+	// BookmarkFileFormatAuto deduces the format from the file extension, JSON by default
+	BookmarkFileFormatAuto BookmarkFileFormat = "auto"
+	BookmarkFileFormatJSON BookmarkFileFormat = "json"
+	BookmarkFileFormatYAML BookmarkFileFormat = "yaml"
+)
+
+// ImportConflictStrategy defines what to do when an imported command
+// has the same script as an existing command
+type ImportConflictStrategy string
+
+const (
+	// ImportConflictSkip keeps the existing command unchanged
+	ImportConflictSkip ImportConflictStrategy = "skip"
+	// ImportConflictOverwrite replaces the existing command content, a revision is recorded
+	ImportConflictOverwrite ImportConflictStrategy = "overwrite"
+	// ImportConflictDuplicate imports the command as a new command
+	ImportConflictDuplicate ImportConflictStrategy = "duplicate"
+)
+
+// ImportAction is the action done (or planned in dry run mode) for an imported command
+type ImportAction string
+
+const (
+	ImportActionCreated     ImportAction = "created"
+	ImportActionOverwritten ImportAction = "overwritten"
+	ImportActionDuplicated  ImportAction = "duplicated"
+	// ImportActionAlreadyExists is used for the commands not imported using the skip conflict strategy
+	ImportActionAlreadyExists ImportAction = "already existing"
+	// ImportActionSkipped is used for the entries of a snippets file that are not commands
+	ImportActionSkipped ImportAction = "skipped"
+	ImportActionInvalid ImportAction = "invalid"
+)
+
+type ImportOptions struct {
+	OnConflict ImportConflictStrategy
+	// DryRun computes the import report without modifying the database
+	DryRun bool
+	// Lint replaces the lint status of the imported commands by the result of shellcheck
+	Lint bool
+}
+
+type ImportEntryResult struct {
+	Err    error // reason of an invalid or skipped entry
+	Script string
+	Action ImportAction
+}
+
+type ImportReport struct {
+	// FilePath is the imported file, empty for the standard input
+	FilePath string
+	Results  []*ImportEntryResult
+	DryRun   bool
+}
+
+// Count returns the number of entries for which the action has been done
+func (r *ImportReport) Count(action ImportAction) int {
+	count := 0
+	for _, result := range r.Results {
+		if result.Action == action {
+			count++
+		}
+	}
+	return count
+}
+
+// GetSummary returns the number of entries by action on a single line
+func (r *ImportReport) GetSummary() string {
+	return fmt.Sprintf(
+		"%d entries read: %d created, %d overwritten, %d duplicated, %d already existing, %d skipped, %d invalid",
+		len(r.Results),
+		r.Count(ImportActionCreated), r.Count(ImportActionOverwritten), r.Count(ImportActionDuplicated),
+		r.Count(ImportActionAlreadyExists), r.Count(ImportActionSkipped), r.Count(ImportActionInvalid),
+	)
+}
+
+// String returns the summary of the import followed by the invalid and skipped entries
+func (r *ImportReport) String() string {
+	var report strings.Builder
+	if r.FilePath != "" {
+		report.WriteString(r.FilePath + ":\n")
+	}
+	if r.DryRun {
+		report.WriteString("Dry run, nothing has been imported\n")
+	}
+	report.WriteString(r.GetSummary() + "\n")
+	for _, result := range r.Results {
+		if result.Err != nil {
+			report.WriteString(fmt.Sprintf("  - %s: %v\n", result.Action, result.Err))
+		}
+	}
+	return report.String()
+}
+
+// BookmarkFileService exports and imports commands as JSON or YAML bookmark files
+type BookmarkFileService struct {
+	historyService *HistoryService
+	dbService      *DBService
+}
+
+func NewBookmarkFileService(historyService *HistoryService, dbService *DBService) *BookmarkFileService {
+	return &BookmarkFileService{
+		historyService: historyService,
+		dbService:      dbService,
+	}
+}
+
+// GetBookmarkFileFormat resolves the auto format using the extension of the file path
+func GetBookmarkFileFormat(filePath string, format BookmarkFileFormat) BookmarkFileFormat {
+	if format != BookmarkFileFormatAuto && format != "" {
+		return format
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		return BookmarkFileFormatYAML
+	default:
+		return BookmarkFileFormatJSON
+	}
+}
+
+// Export writes the commands having one of the given statuses,
+// it returns the number of exported commands
+func (s *BookmarkFileService) Export(
+	writer io.Writer, format BookmarkFileFormat, statuses ...models.CommandStatus,
+) (int, error) {
+	commands, err := s.dbService.GetCommands(statuses...)
+	if err != nil {
+		slog.Error("Error retrieving commands to export", "error", err)
+		return 0, err
+	}
+	folders, err := s.historyService.GetFolders()
+	if err != nil {
+		return 0, err
+	}
+	file := &models.BookmarkFile{
+		Version:  models.BookmarkFileVersion,
+		Commands: make([]*models.BookmarkCommand, 0, len(commands)),
+	}
+	for _, command := range commands {
+		folderPath := ""
+		if folder := findFolderByID(folders, command.FolderID); folder != nil {
+			folderPath = folder.Path
+		}
+		file.Commands = append(file.Commands, models.NewBookmarkCommand(command, folderPath))
+	}
+	if err := encodeBookmarkFile(writer, format, file); err != nil {
+		slog.Error("Error encoding bookmark file", "format", format, "error", err)
+		return 0, err
+	}
+	return len(file.Commands), nil
+}
+
+func encodeBookmarkFile(writer io.Writer, format BookmarkFileFormat, file *models.BookmarkFile) error {
+	if format == BookmarkFileFormatYAML {
+		encoder := yaml.NewEncoder(writer)
+		encoder.SetIndent(2) //nolint:mnd // yaml indentation
+		if err := encoder.Encode(file); err != nil {
+			return err
+		}
+		return encoder.Close()
+	}
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	// keep shell redirections and placeholders readable
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(file)
+}
+
+// Import reads a bookmark file and imports its commands (see ImportCommands)
+func (s *BookmarkFileService) Import(
+	reader io.Reader, format BookmarkFileFormat, options ImportOptions,
+) (*ImportReport, error) {
+	var file models.BookmarkFile
+	var err error
+	if format == BookmarkFileFormatYAML {
+		err = yaml.NewDecoder(reader).Decode(&file)
+	} else {
+		err = json.NewDecoder(reader).Decode(&file)
+	}
+	// an empty file is decoded as a file without command
+	if err != nil && !errors.Is(err, io.EOF) {
+		slog.Error("Error decoding bookmark file", "format", format, "error", err)
+		return nil, err
+	}
+	if file.Version > models.BookmarkFileVersion {
+		return nil, &UnsupportedBookmarkFileVersionError{Version: file.Version}
+	}
+	return s.ImportCommands(file.Commands, options), nil
+}
+
+// ImportCommands imports the commands, a command having the same script as an existing
+// non obsolete command is handled using the conflict strategy.
+// Invalid commands are reported and do not stop the import.
+func (s *BookmarkFileService) ImportCommands(
+	bookmarks []*models.BookmarkCommand, options ImportOptions,
+) *ImportReport {
+	report := &ImportReport{
+		FilePath: "",
+		Results:  make([]*ImportEntryResult, 0, len(bookmarks)),
+		DryRun:   options.DryRun,
+	}
+	// scripts imported in dry run mode are not in the database,
+	// they are remembered to detect conflicts inside the imported file
+	importedScripts := map[string]bool{}
+	folderIDs := map[string]resource.ID{"": models.RootFolderID}
+	for i, bookmark := range bookmarks {
+		action, err := s.importCommand(i, bookmark, options, importedScripts, folderIDs)
+		if err != nil {
+			slog.Warn("Command not imported", "index", i, "error", err)
+			action = ImportActionInvalid
+		}
+		script := ""
+		if bookmark != nil {
+			script = bookmark.Script
+		}
+		if action != ImportActionInvalid && action != ImportActionAlreadyExists {
+			importedScripts[script] = true
+		}
+		report.Results = append(report.Results, &ImportEntryResult{
+			Script: script,
+			Action: action,
+			Err:    err,
+		})
+	}
+	slog.Info("Commands imported",
+		"count", len(bookmarks), "dryRun", options.DryRun, "onConflict", options.OnConflict)
+	return report
+}
+
+func (s *BookmarkFileService) importCommand(
+	index int, bookmark *models.BookmarkCommand, options ImportOptions,
+	importedScripts map[string]bool, folderIDs map[string]resource.ID,
+) (ImportAction, error) {
+	if err := validateBookmark(index, bookmark); err != nil {
+		return ImportActionInvalid, err
+	}
+	existing, err := s.dbService.GetActiveCommandByScript(bookmark.Script)
+	if err != nil {
+		return ImportActionInvalid, err
+	}
+	action := ImportActionCreated
+	if existing != nil || (options.DryRun && importedScripts[bookmark.Script]) {
+		action = getConflictAction(options.OnConflict)
+	}
+	if action == ImportActionAlreadyExists || options.DryRun {
+		return action, nil
+	}
+	command := bookmark.ToCommand()
+	if options.Lint {
+		s.historyService.lintService.LintCommand(command)
+	}
+	s.historyService.classifyRisk(command)
+	command.FolderID, err = s.getImportFolderID(bookmark.Folder, folderIDs)
+	if err != nil {
+		return ImportActionInvalid, err
+	}
+	if action == ImportActionOverwritten {
+		return action, s.overwriteCommand(existing, command)
+	}
+	if err := s.dbService.SaveCommand(command); err != nil {
+		slog.Error("Error saving imported command", "index", index, "error", err)
+		return ImportActionInvalid, err
+	}
+	return action, nil
+}
+
+func getConflictAction(strategy ImportConflictStrategy) ImportAction {
+	switch strategy {
+	case ImportConflictOverwrite:
+		return ImportActionOverwritten
+	case ImportConflictDuplicate:
+		return ImportActionDuplicated
+	case ImportConflictSkip:
+		return ImportActionAlreadyExists
+	default:
+		return ImportActionAlreadyExists
+	}
+}
+
+func validateBookmark(index int, bookmark *models.BookmarkCommand) error {
+	if bookmark == nil || strings.TrimSpace(bookmark.Script) == "" {
+		return &InvalidBookmarkError{Index: index, Reason: "script is empty"}
+	}
+	if utf8.RuneCountInString(bookmark.Title) > models.CommandTitleMaxLength {
+		return &InvalidBookmarkError{
+			Index:  index,
+			Reason: fmt.Sprintf("title is too long, maximum %d characters allowed", models.CommandTitleMaxLength),
+		}
+	}
+	if bookmark.Status != "" &&
+		(!models.IsValidCommandStatus(bookmark.Status) || bookmark.Status == models.CommandStatusObsolete) {
+		return &InvalidBookmarkError{Index: index, Reason: fmt.Sprintf("unsupported status '%s'", bookmark.Status)}
+	}
+	if bookmark.LintStatus != "" && !models.IsValidLintStatus(bookmark.LintStatus) {
+		return &InvalidBookmarkError{
+			Index: index, Reason: fmt.Sprintf("unsupported lint status '%s'", bookmark.LintStatus),
+		}
+	}
+	if err := ValidateTags(models.ParseTags(strings.Join(bookmark.Tags, models.TagsSeparator))); err != nil {
+		return &InvalidBookmarkError{Index: index, Reason: err.Error()}
+	}
+	if err := ValidateFolderTitles(models.SplitFolderPath(bookmark.Folder)); err != nil {
+		return &InvalidBookmarkError{Index: index, Reason: err.Error()}
+	}
+	return nil
+}
+
+// getImportFolderID returns the id of the folder, creating it if needed
+func (s *BookmarkFileService) getImportFolderID(
+	folderPath string, folderIDs map[string]resource.ID,
+) (resource.ID, error) {
+	folderPath = strings.Join(models.SplitFolderPath(folderPath), models.FolderPathSeparator)
+	if folderID, ok := folderIDs[folderPath]; ok {
+		return folderID, nil
+	}
+	folder, err := s.historyService.CreateFolderPath(folderPath)
+	if err != nil {
+		return models.RootFolderID, err
+	}
+	folderIDs[folderPath] = folder.ID
+	return folder.ID, nil
+}
+
+// overwriteCommand replaces the content of the existing command by the imported one,
+// the previous content is kept as a revision
+func (s *BookmarkFileService) overwriteCommand(existing *models.Command, imported *models.Command) error {
+	previous := *existing
+	imported.ID = existing.ID
+	if err := s.dbService.UpdateCommand(imported); err != nil {
+		return err
+	}
+	if err := s.dbService.SetCommandTags(imported.ID, imported.Tags); err != nil {
+		return err
+	}
+	if err := s.dbService.SetCommandPlaceholders(imported.ID, imported.Placeholders); err != nil {
+		return err
+	}
+	if err := s.dbService.MoveCommandsToFolder([]resource.ID{imported.ID}, imported.FolderID); err != nil {
+		return err
+	}
+	return s.historyService.recordRevision(&previous, imported)
+}
+
+// SnippetFileFormat is the format of the cheatsheets or snippets file of another tool
+type SnippetFileFormat string
+
+const (
+	// SnippetFileFormatNavi is a navi cheat file (.cheat) or a directory of cheat files
+	SnippetFileFormatNavi SnippetFileFormat = "navi"
+	// SnippetFileFormatPet is a pet snippets file (snippet.toml)
+	SnippetFileFormatPet SnippetFileFormat = "pet"
+
+	naviCheatFileExtension  = ".cheat"
+	petSnippetFileExtension = ".toml"
+	// truncatedTitleSuffix ends the titles too long to be imported as is
+	truncatedTitleSuffix = "..."
+)
+
+// GetSnippetFileFormat deduces the format of a snippets file from its extension,
+// a directory is considered as a directory of navi cheat files
+func GetSnippetFileFormat(filePath string) (SnippetFileFormat, error) {
+	filePath = expandHomeDir(filePath)
+	if info, err := os.Stat(filePath); err == nil && info.IsDir() {
+		return SnippetFileFormatNavi, nil
+	}
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case naviCheatFileExtension:
+		return SnippetFileFormatNavi, nil
+	case petSnippetFileExtension:
+		return SnippetFileFormatPet, nil
+	default:
+		return "", &UnknownSnippetFileFormatError{FilePath: filePath}
+	}
+}
+
+// ImportSnippetFiles imports the commands of a navi cheat file, of all the cheat files
+// of a directory or of a pet snippets file. The commands are saved and linted,
+// a report is returned for each file.
+func (s *BookmarkFileService) ImportSnippetFiles(
+	path string, format SnippetFileFormat, options ImportOptions,
+) ([]*ImportReport, error) {
+	filePaths, err := getSnippetFilePaths(expandHomeDir(path), format)
+	if err != nil {
+		slog.Error("Error listing snippet files", "path", path, "error", err)
+		return nil, err
+	}
+	options.Lint = true
+	reports := make([]*ImportReport, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		report, err := s.importSnippetFile(filePath, format, options)
+		if err != nil {
+			slog.Error("Error importing snippet file", "file", filePath, "format", format, "error", err)
+			return reports, err
+		}
+		slog.Info("Snippet file imported",
+			"file", filePath,
+			"format", format,
+			"dryRun", options.DryRun,
+			"createdCount", report.Count(ImportActionCreated),
+			"overwrittenCount", report.Count(ImportActionOverwritten),
+			"duplicatedCount", report.Count(ImportActionDuplicated),
+			"alreadyExistsCount", report.Count(ImportActionAlreadyExists),
+			"skippedCount", report.Count(ImportActionSkipped),
+			"invalidCount", report.Count(ImportActionInvalid),
+		)
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// getSnippetFilePaths returns the file itself or the cheat files of a navi directory
+func getSnippetFilePaths(path string, format SnippetFileFormat) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() || format != SnippetFileFormatNavi {
+		return []string{path}, nil
+	}
+	filePaths := []string{}
+	err = filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && strings.EqualFold(filepath.Ext(filePath), naviCheatFileExtension) {
+			filePaths = append(filePaths, filePath)
+		}
+		return nil
+	})
+	return filePaths, err
+}
+
+func (s *BookmarkFileService) importSnippetFile(
+	filePath string, format SnippetFileFormat, options ImportOptions,
+) (*ImportReport, error) {
+	file, err := os.Open(filePath) // #nosec G304
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var snippetFile *processors.SnippetFile
+	if format == SnippetFileFormatPet {
+		snippetFile, err = processors.ParsePetSnippets(file)
+	} else {
+		snippetFile, err = processors.ParseNaviCheat(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarks := make([]*models.BookmarkCommand, 0, len(snippetFile.Snippets))
+	for _, snippet := range snippetFile.Snippets {
+		bookmarks = append(bookmarks, snippetToBookmark(snippet))
+	}
+	report := s.ImportCommands(bookmarks, options)
+	report.FilePath = filePath
+	for i, result := range report.Results {
+		if result.Err != nil {
+			result.Err = fmt.Errorf("line %d: %w", snippetFile.Snippets[i].LineNumber, result.Err)
+		}
+	}
+	for _, skipped := range snippetFile.Skipped {
+		report.Results = append(report.Results, &ImportEntryResult{
+			Err:    &SkippedSnippetError{Reason: skipped.Reason, LineNumber: skipped.LineNumber},
+			Script: "",
+			Action: ImportActionSkipped,
+		})
+	}
+	return report, nil
+}
+
+// snippetToBookmark converts a snippet to a saved command, a title too long
+// is truncated and kept entirely in the description
+func snippetToBookmark(snippet *processors.Snippet) *models.BookmarkCommand {
+	title := strings.Join(strings.Fields(snippet.Title), " ")
+	description := snippet.Description
+	if titleRunes := []rune(title); len(titleRunes) > models.CommandTitleMaxLength {
+		description = strings.TrimSpace(title + "\n\n" + description)
+		title = string(titleRunes[:models.CommandTitleMaxLength-len(truncatedTitleSuffix)]) + truncatedTitleSuffix
+	}
+	usedPlaceholders := map[string]bool{}
+	for _, placeholder := range models.ParsePlaceholders(snippet.Script) {
+		usedPlaceholders[placeholder.Name] = true
+	}
+	placeholders := []*models.BookmarkPlaceholder{}
+	for _, variable := range snippet.Variables {
+		if !usedPlaceholders[variable.Name] {
+			continue
+		}
+		placeholders = append(placeholders, &models.BookmarkPlaceholder{
+			Name:          variable.Name,
+			Description:   variable.Description,
+			AllowedValues: variable.AllowedValues,
+		})
+	}
+	return &models.BookmarkCommand{
+		CreationDatetime:     time.Time{},
+		ModificationDatetime: time.Time{},
+		Title:                title,
+		Description:          description,
+		Script:               snippet.Script,
+		Status:               models.CommandStatusSaved,
+		LintStatus:           "",
+		Folder:               "",
+		LintIssues:           nil,
+		Tags:                 snippet.Tags,
+		Placeholders:         placeholders,
+		Elapsed:              0,
+	}
+}
+
+// expandHomeDir replaces the ~ prefix of the path by the home directory
+func expandHomeDir(path string) string {
+	rest, found := strings.CutPrefix(path, "~")
+	if !found || (rest != "" && !strings.HasPrefix(rest, string(filepath.Separator))) {
+		return path
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return homeDir + rest
+}
+
+// GetDefaultSnippetFilePaths returns the default locations of the navi cheats
+// and of the pet snippets file that exist, the home directory being replaced by ~
+func GetDefaultSnippetFilePaths() []string {
+	paths := []string{}
+	for _, path := range []string{
+		filepath.Join("~", ".local", "share", "navi", "cheats"),
+		filepath.Join("~", ".config", "pet", "snippet.toml"),
+	} {
+		if _, err := os.Stat(expandHomeDir(path)); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
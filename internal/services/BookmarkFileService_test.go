@@ -0,0 +1,245 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBookmarkFileService(t *testing.T) (*BookmarkFileService, *DBService) {
+	t.Helper()
+	dbService := newTestDBService(t)
+	historyService := NewHistoryService(nil, dbService, NewLintService())
+	return NewBookmarkFileService(historyService, dbService), dbService
+}
+
+func TestGetBookmarkFileFormat(t *testing.T) {
+	assert.Equal(t, BookmarkFileFormatYAML, GetBookmarkFileFormat("bookmarks.yml", BookmarkFileFormatAuto))
+	assert.Equal(t, BookmarkFileFormatYAML, GetBookmarkFileFormat("bookmarks.YAML", BookmarkFileFormatAuto))
+	assert.Equal(t, BookmarkFileFormatJSON, GetBookmarkFileFormat("bookmarks.json", BookmarkFileFormatAuto))
+	assert.Equal(t, BookmarkFileFormatJSON, GetBookmarkFileFormat("-", BookmarkFileFormatAuto))
+	assert.Equal(t, BookmarkFileFormatYAML, GetBookmarkFileFormat("-", BookmarkFileFormatYAML))
+}
+
+func TestBookmarkFileService_ExportImport(t *testing.T) {
+	for _, format := range []BookmarkFileFormat{BookmarkFileFormatJSON, BookmarkFileFormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			service, dbService := newTestBookmarkFileService(t)
+			folder, err := service.historyService.CreateFolderPath("docker/compose")
+			require.NoError(t, err)
+			saved := saveTestCommand(t, dbService, "docker compose up -d <service>", "docker")
+			saved.Status = models.CommandStatusSaved
+			saved.Description = "start a service\nin background"
+			require.NoError(t, dbService.UpdateCommand(saved))
+			require.NoError(t, dbService.MoveCommandsToFolder([]resource.ID{saved.ID}, folder.ID))
+			require.NoError(t, dbService.SetCommandPlaceholders(saved.ID, []*models.PlaceholderDefinition{
+				{Name: "service", Description: "compose service", AllowedValues: []string{"db", "web"}},
+			}))
+			saveTestCommand(t, dbService, "ls -la")
+
+			var exported bytes.Buffer
+			count, err := service.Export(&exported, format, models.CommandStatusSaved)
+			require.NoError(t, err)
+			assert.Equal(t, 1, count)
+			assert.Contains(t, exported.String(), "docker compose up -d <service>")
+
+			target, targetDB := newTestBookmarkFileService(t)
+			report, err := target.Import(&exported, format, ImportOptions{OnConflict: ImportConflictSkip, DryRun: false})
+			require.NoError(t, err)
+			assert.Equal(t, 1, report.Count(ImportActionCreated))
+
+			imported, err := targetDB.GetActiveCommandByScript("docker compose up -d <service>")
+			require.NoError(t, err)
+			require.NotNil(t, imported)
+			assert.Equal(t, models.CommandStatusSaved, imported.Status)
+			assert.Equal(t, "start a service\nin background", imported.Description)
+			assert.Equal(t, []string{"docker"}, imported.Tags)
+			assert.Equal(t, saved.CreationDatetime.Unix(), imported.CreationDatetime.Unix())
+			require.Len(t, imported.Placeholders, 1)
+			assert.Equal(t, []string{"db", "web"}, imported.Placeholders[0].AllowedValues)
+			folders, err := target.historyService.GetFolders()
+			require.NoError(t, err)
+			importedFolder := findFolderByID(folders, imported.FolderID)
+			require.NotNil(t, importedFolder)
+			assert.Equal(t, "docker/compose", importedFolder.Path)
+		})
+	}
+}
+
+func TestBookmarkFileService_ImportConflicts(t *testing.T) {
+	bookmarkFile := `{"version": 1, "commands": [
+		{"title": "list files", "script": "ls -la", "tags": ["files"]},
+		{"title": "disk usage", "script": "du -sh ."},
+		{"title": "disk usage again", "script": "du -sh ."},
+		{"title": "", "script": "  "},
+		{"title": "obsolete", "script": "pwd", "status": "OBSOLETE"}
+	]}`
+
+	tests := []struct {
+		name       string
+		onConflict ImportConflictStrategy
+		wantTitle  string
+		want       map[ImportAction]int
+		wantCount  int
+		dryRun     bool
+	}{
+		{
+			name:       "skip",
+			onConflict: ImportConflictSkip,
+			want: map[ImportAction]int{
+				ImportActionCreated: 1, ImportActionAlreadyExists: 2, ImportActionInvalid: 2,
+			},
+			wantTitle: "",
+			wantCount: 2,
+		},
+		{
+			name:       "overwrite",
+			onConflict: ImportConflictOverwrite,
+			want: map[ImportAction]int{
+				ImportActionCreated: 1, ImportActionOverwritten: 2, ImportActionInvalid: 2,
+			},
+			wantTitle: "list files",
+			wantCount: 2,
+		},
+		{
+			name:       "duplicate",
+			onConflict: ImportConflictDuplicate,
+			want: map[ImportAction]int{
+				ImportActionCreated: 1, ImportActionDuplicated: 2, ImportActionInvalid: 2,
+			},
+			wantTitle: "",
+			wantCount: 4,
+		},
+		{
+			name:       "dry run",
+			onConflict: ImportConflictDuplicate,
+			dryRun:     true,
+			want: map[ImportAction]int{
+				ImportActionCreated: 1, ImportActionDuplicated: 2, ImportActionInvalid: 2,
+			},
+			wantTitle: "",
+			wantCount: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, dbService := newTestBookmarkFileService(t)
+			saveTestCommand(t, dbService, "ls -la")
+
+			report, err := service.Import(
+				strings.NewReader(bookmarkFile), BookmarkFileFormatJSON,
+				ImportOptions{OnConflict: tt.onConflict, DryRun: tt.dryRun},
+			)
+			require.NoError(t, err)
+			for _, action := range []ImportAction{
+				ImportActionCreated, ImportActionOverwritten, ImportActionDuplicated,
+				ImportActionAlreadyExists, ImportActionSkipped, ImportActionInvalid,
+			} {
+				assert.Equal(t, tt.want[action], report.Count(action), action)
+			}
+			assert.Contains(t, report.String(), "invalid: command #4: script is empty")
+
+			commands, err := dbService.GetCommands()
+			require.NoError(t, err)
+			assert.Len(t, commands, tt.wantCount)
+			if tt.wantTitle != "" {
+				command, err := dbService.GetActiveCommandByScript("ls -la")
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantTitle, command.Title)
+				assert.Equal(t, []string{"files"}, command.Tags)
+			}
+		})
+	}
+}
+
+func TestBookmarkFileService_ImportUnsupportedVersion(t *testing.T) {
+	service, _ := newTestBookmarkFileService(t)
+	_, err := service.Import(
+		strings.NewReader("version: 2\ncommands: []\n"), BookmarkFileFormatYAML,
+		ImportOptions{OnConflict: ImportConflictSkip, DryRun: false},
+	)
+	var versionErr *UnsupportedBookmarkFileVersionError
+	assert.ErrorAs(t, err, &versionErr)
+}
+
+func TestGetSnippetFileFormat(t *testing.T) {
+	dir := t.TempDir()
+	format, err := GetSnippetFileFormat(dir)
+	require.NoError(t, err)
+	assert.Equal(t, SnippetFileFormatNavi, format)
+	format, err = GetSnippetFileFormat("git.cheat")
+	require.NoError(t, err)
+	assert.Equal(t, SnippetFileFormatNavi, format)
+	format, err = GetSnippetFileFormat("snippet.toml")
+	require.NoError(t, err)
+	assert.Equal(t, SnippetFileFormatPet, format)
+	_, err = GetSnippetFileFormat("bookmarks.json")
+	var formatErr *UnknownSnippetFileFormatError
+	assert.ErrorAs(t, err, &formatErr)
+}
+
+func TestBookmarkFileService_ImportSnippetFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "git.cheat"), []byte(`% git, vcs
+
+# checkout a branch
+git checkout <branch>
+
+# a description without command
+
+$ branch: git branch --format='%(refname:short)'
+`), 0o600))
+	petFile := filepath.Join(dir, "snippet.toml")
+	require.NoError(t, os.WriteFile(petFile, []byte(`[[snippets]]
+  description = "list the pods of a namespace, the description being longer than a title"
+  command = "kubectl get pods -n <namespace=default> -o <format=|_wide_||_yaml_|>"
+  tag = ["k8s"]
+  output = ""
+
+[[snippets]]
+  description = "checkout"
+  command = "git checkout <branch>"
+`), 0o600))
+
+	service, dbService := newTestBookmarkFileService(t)
+	options := ImportOptions{OnConflict: ImportConflictSkip, DryRun: false, Lint: false}
+	reports, err := service.ImportSnippetFiles(dir, SnippetFileFormatNavi, options)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, 1, reports[0].Count(ImportActionCreated))
+	assert.Equal(t, 1, reports[0].Count(ImportActionSkipped))
+
+	command, err := dbService.GetActiveCommandByScript("git checkout <branch>")
+	require.NoError(t, err)
+	require.NotNil(t, command)
+	assert.Equal(t, "checkout a branch", command.Title)
+	assert.Equal(t, models.CommandStatusSaved, command.Status)
+	assert.NotEmpty(t, command.LintStatus)
+	assert.Equal(t, []string{"git", "vcs"}, command.Tags)
+	require.Len(t, command.Placeholders, 1)
+	assert.Equal(t, "values of: git branch --format='%(refname:short)'", command.Placeholders[0].Description)
+
+	reports, err = service.ImportSnippetFiles(petFile, SnippetFileFormatPet, options)
+	require.NoError(t, err)
+	require.Len(t, reports, 1)
+	assert.Equal(t, 1, reports[0].Count(ImportActionCreated))
+	assert.Equal(t, 1, reports[0].Count(ImportActionAlreadyExists))
+
+	command, err = dbService.GetActiveCommandByScript("kubectl get pods -n {{namespace:default}} -o <format>")
+	require.NoError(t, err)
+	require.NotNil(t, command)
+	assert.Len(t, []rune(command.Title), models.CommandTitleMaxLength)
+	assert.True(t, strings.HasSuffix(command.Title, truncatedTitleSuffix))
+	assert.Contains(t, command.Description, "the description being longer than a title")
+	require.Len(t, command.Placeholders, 1)
+	assert.Equal(t, []string{"wide", "yaml"}, command.Placeholders[0].AllowedValues)
+}
@@ -0,0 +1,495 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+	"gopkg.in/yaml.v3"
+)
+
+// canceledExitCode is the exit code of a command run from the command line and interrupted
+const canceledExitCode = 130
+
+// CliService runs the sub commands of the command line, they do not need a terminal
+// so the bookmarks can be managed from scripts
+type CliService struct {
+	historyService *HistoryService
+	dbService      *DBService
+	taskService    *TaskService
+	in             io.Reader
+	out            io.Writer
+	// errOut receives the stderr of the commands run
+	errOut io.Writer
+	// editFile opens the file in the editor of the user and waits until it is closed
+	editFile func(path string) error
+}
+
+// NewCliService creates a CliService reading the scripts from in and writing the results to out,
+// the commands run read in and write their stdout to out and their stderr to errOut
+func NewCliService(
+	historyService *HistoryService, dbService *DBService, taskService *TaskService,
+	in io.Reader, out io.Writer, errOut io.Writer,
+) *CliService {
+	return &CliService{
+		historyService: historyService,
+		dbService:      dbService,
+		taskService:    taskService,
+		in:             in,
+		out:            out,
+		errOut:         errOut,
+		editFile:       editFileWithEditor,
+	}
+}
+
+// ParseCommandStatuses converts the lower case statuses of the command line
+func ParseCommandStatuses(statuses []string) []models.CommandStatus {
+	commandStatuses := make([]models.CommandStatus, 0, len(statuses))
+	for _, status := range statuses {
+		commandStatuses = append(commandStatuses, models.CommandStatus(strings.ToUpper(status)))
+	}
+	return commandStatuses
+}
+
+// List displays the commands having one of the statuses and all the tags,
+// restricted to the folder and its sub folders if folderPath is not empty
+func (s *CliService) List(
+	statuses []models.CommandStatus, tags []string, folderPath string, format CommandOutputFormat,
+) error {
+	commands, err := s.historyService.GetCommandsByStatus(statuses...)
+	if err != nil {
+		return err
+	}
+	if folderPath != "" {
+		folders, err := s.historyService.GetFolders()
+		if err != nil {
+			return err
+		}
+		folder := models.FindFolderByPath(folders, folderPath)
+		if folder == nil {
+			return &FolderPathNotFoundError{Path: folderPath}
+		}
+		folderIDs := models.GetFolderSubtreeIDs(folders, folder.ID)
+		commands = slices.DeleteFunc(commands, func(command *models.Command) bool {
+			return !slices.Contains(folderIDs, command.FolderID)
+		})
+	}
+	commands = slices.DeleteFunc(commands, func(command *models.Command) bool {
+		return !hasAllTags(command, tags)
+	})
+	slices.SortFunc(commands, func(a, b *models.Command) int {
+		return int(a.ID - b.ID)
+	})
+	return s.writeCommands(commands, format)
+}
+
+// Search displays the commands having one of the statuses matching the full text search query,
+// the most relevant first
+func (s *CliService) Search(query string, statuses []models.CommandStatus, format CommandOutputFormat) error {
+	commands, err := s.historyService.SearchCommands(query, statuses...)
+	if err != nil {
+		return err
+	}
+	return s.writeCommands(commands, format)
+}
+
+func hasAllTags(command *models.Command, tags []string) bool {
+	for _, tag := range tags {
+		if !slices.ContainsFunc(command.Tags, func(commandTag string) bool {
+			return strings.EqualFold(commandTag, strings.TrimSpace(tag))
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// writeCommands displays the commands in the given format
+func (s *CliService) writeCommands(commands []*models.Command, format CommandOutputFormat) error {
+	writer, err := s.newCommandOutputWriter(format)
+	if err != nil {
+		return err
+	}
+	return writer.WriteCommands(commands)
+}
+
+func (s *CliService) newCommandOutputWriter(format CommandOutputFormat) (*CommandOutputWriter, error) {
+	folderPaths := map[resource.ID]string{}
+	if format.NeedsFolderPaths() {
+		folders, err := s.historyService.GetFolders()
+		if err != nil {
+			return nil, err
+		}
+		for _, folder := range folders {
+			folderPaths[folder.ID] = folder.Path
+		}
+	}
+	return NewCommandOutputWriter(s.out, format, folderPaths), nil
+}
+
+// getCommand returns the command having the given ID, an error if it does not exist
+func (s *CliService) getCommand(id resource.ID) (*models.Command, error) {
+	command, err := s.dbService.GetCommandByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if command == nil {
+		return nil, &CommandNotFoundError{ID: id}
+	}
+	return command, nil
+}
+
+// Show displays all the information of a command, the script is displayed last as is
+// by the text format
+func (s *CliService) Show(id resource.ID, format CommandOutputFormat) error {
+	command, err := s.getCommand(id)
+	if err != nil {
+		return err
+	}
+	if format != CommandOutputFormatText {
+		writer, err := s.newCommandOutputWriter(format)
+		if err != nil {
+			return err
+		}
+		return writer.WriteCommand(command)
+	}
+	folderPath, err := s.getFolderPath(command.FolderID)
+	if err != nil {
+		return err
+	}
+
+	writer := tabwriter.NewWriter(s.out, 0, 0, 1, ' ', 0)
+	fields := [][2]string{
+		{"Id", fmt.Sprintf("%d", command.ID)},
+		{"Title", command.Title},
+		{"Description", strings.ReplaceAll(command.Description, "\n", "\n\t")},
+		{"Status", string(command.Status)},
+		{"Folder", folderPath},
+		{"Tags", models.FormatTags(command.Tags)},
+		{"Placeholders", strings.ReplaceAll(models.FormatPlaceholderDefinitions(command.Placeholders), "\n", "\n\t")},
+		{"Lint", string(command.LintStatus)},
+		{"Risk", formatCommandRisk(command)},
+		{"Secrets", command.SecretReason},
+		{"Use count", fmt.Sprintf("%d", command.UseCount)},
+		{"Last used", formatOptionalDateTime(command.LastUsedDatetime)},
+		{"Last run", strings.TrimSpace(
+			formatOptionalDateTime(command.LastRunDatetime) + " " + string(command.LastRunStatus),
+		)},
+		{"Created", command.CreationDatetime.Format(time.DateTime)},
+		{"Modified", command.ModificationDatetime.Format(time.DateTime)},
+	}
+	for _, field := range fields {
+		fmt.Fprintf(writer, "%s:\t%s\n", field[0], field[1])
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Script:\n%s\n", command.Script)
+	return err
+}
+
+func (s *CliService) getFolderPath(folderID resource.ID) (string, error) {
+	if folderID == models.RootFolderID {
+		return "", nil
+	}
+	folders, err := s.historyService.GetFolders()
+	if err != nil {
+		return "", err
+	}
+	for _, folder := range folders {
+		if folder.ID == folderID {
+			return folder.Path, nil
+		}
+	}
+	return "", nil
+}
+
+func formatCommandRisk(command *models.Command) string {
+	if command.RiskLevel == models.RiskLevelNone {
+		return ""
+	}
+	return fmt.Sprintf("%s (%s)", command.RiskLevel, command.RiskReason)
+}
+
+func formatOptionalDateTime(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.Format(time.DateTime)
+}
+
+// Add saves a new command, the script is read from the input if it is empty or -
+func (s *CliService) Add(script string, title string, description string, tags []string, folderPath string) error {
+	title = strings.TrimSpace(title)
+	if utf8.RuneCountInString(title) > models.CommandTitleMaxLength {
+		return &CommandTitleTooLongError{Title: title}
+	}
+	if script == "" || script == "-" {
+		content, err := io.ReadAll(s.in)
+		if err != nil {
+			return err
+		}
+		script = strings.TrimRight(string(content), "\n")
+	}
+	command := models.NewCommand(script, 0, time.Now().Truncate(time.Second))
+	command.Title = title
+	command.Description = description
+	command.Tags = models.ParseTags(strings.Join(tags, models.TagsSeparator))
+	command.Placeholders = []*models.PlaceholderDefinition{}
+	if folderPath != "" {
+		folder, err := s.historyService.CreateFolderPath(folderPath)
+		if err != nil {
+			return err
+		}
+		command.FolderID = folder.ID
+	}
+	if err := s.historyService.CreateCommand(command); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(s.out, "Command #%d added\n", command.ID)
+	return err
+}
+
+// editedCommand is the content of the file edited by the user
+type editedCommand struct {
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Tags        []string `yaml:"tags"`
+	// Placeholders uses the format of the command editor, one placeholder per line
+	Placeholders string `yaml:"placeholders"`
+	Script       string `yaml:"script"`
+}
+
+// Edit opens the title, the description, the tags, the placeholders and the script of the command
+// in the editor of the user ($VISUAL, $EDITOR or vi), the command is saved if they have been changed
+func (s *CliService) Edit(id resource.ID) error {
+	command, err := s.getCommand(id)
+	if err != nil {
+		return err
+	}
+	if !command.IsEditable() {
+		return &InvalidCommandStatusError{ID: id, Status: command.Status, Action: "edited"}
+	}
+	original := editedCommand{
+		Title:        command.Title,
+		Description:  command.Description,
+		Tags:         command.Tags,
+		Placeholders: models.FormatPlaceholderDefinitions(command.Placeholders),
+		Script:       command.Script,
+	}
+	edited, err := s.editInFile(&original)
+	if err != nil {
+		return err
+	}
+	if edited.Title == original.Title && edited.Description == original.Description &&
+		slices.Equal(edited.Tags, original.Tags) && edited.Placeholders == original.Placeholders &&
+		edited.Script == original.Script {
+		_, err := fmt.Fprintf(s.out, "Command #%d unchanged\n", id)
+		return err
+	}
+
+	command.Title = strings.TrimSpace(edited.Title)
+	command.Description = edited.Description
+	command.Tags = models.ParseTags(strings.Join(edited.Tags, models.TagsSeparator))
+	command.Placeholders = models.ParsePlaceholderDefinitions(edited.Placeholders)
+	command.Script = strings.TrimRight(edited.Script, "\n")
+	if strings.TrimSpace(command.Script) == "" {
+		return &EmptyScriptError{}
+	}
+	if utf8.RuneCountInString(command.Title) > models.CommandTitleMaxLength {
+		return &CommandTitleTooLongError{Title: command.Title}
+	}
+	if _, err := s.historyService.UpdateCommand(command); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Command #%d saved\n", id)
+	return err
+}
+
+// editInFile writes the command in a temporary yaml file, opens it in the editor
+// and reads it back once the editor is closed
+func (s *CliService) editInFile(command *editedCommand) (*editedCommand, error) {
+	content, err := yaml.Marshal(command)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.CreateTemp("", "shell-command-bookmarker-*.yaml")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if err := file.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := s.editFile(file.Name()); err != nil {
+		return nil, err
+	}
+	content, err = os.ReadFile(file.Name())
+	if err != nil {
+		return nil, err
+	}
+	edited := editedCommand{Title: "", Description: "", Tags: []string{}, Placeholders: "", Script: ""}
+	if err := yaml.Unmarshal(content, &edited); err != nil {
+		return nil, err
+	}
+	if edited.Tags == nil {
+		edited.Tags = []string{}
+	}
+	return &edited, nil
+}
+
+// editFileWithEditor opens the file with $VISUAL, $EDITOR or vi, the editor can have arguments
+func editFileWithEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "editor", path) //nolint:gosec // the editor is chosen by the user
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	slog.Debug("Opening editor", "editor", editor, "file", path)
+	return cmd.Run()
+}
+
+// Delete marks the commands as deleted, the commands already deleted are ignored
+func (s *CliService) Delete(ids []resource.ID) error {
+	commands, err := s.getCommandsToChange(ids, "deleted")
+	if err != nil {
+		return err
+	}
+	commands = slices.DeleteFunc(commands, func(command *models.Command) bool {
+		return command.Status == models.CommandStatusDeleted
+	})
+	if err := s.historyService.DeleteCommands(commands); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "%d command(s) marked as deleted\n", len(commands))
+	return err
+}
+
+// Restore restores the deleted commands as saved commands, the other commands are ignored
+func (s *CliService) Restore(ids []resource.ID) error {
+	commands, err := s.getCommandsToChange(ids, "restored")
+	if err != nil {
+		return err
+	}
+	commands = slices.DeleteFunc(commands, func(command *models.Command) bool {
+		return command.Status != models.CommandStatusDeleted
+	})
+	if len(commands) > 0 {
+		if err := s.historyService.RestoreCommand(commands); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(s.out, "%d command(s) restored\n", len(commands))
+	return err
+}
+
+// getCommandsToChange returns the commands having the given IDs, an error is returned if one of them
+// does not exist or is obsolete
+func (s *CliService) getCommandsToChange(ids []resource.ID, action string) ([]*models.Command, error) {
+	commands := make([]*models.Command, 0, len(ids))
+	for _, id := range ids {
+		command, err := s.getCommand(id)
+		if err != nil {
+			return nil, err
+		}
+		if command.Status == models.CommandStatusObsolete {
+			return nil, &InvalidCommandStatusError{ID: id, Status: command.Status, Action: action}
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// Run runs the command in the foreground, it reads the input and its stdout and stderr
+// are written as they are produced.
+// values give the value of the placeholders, the last value entered is used for the other ones.
+// A command having a high risk level is only run if force is true.
+// A CommandExitError is returned if the command does not exit successfully.
+func (s *CliService) Run(ctx context.Context, id resource.ID, values map[string]string, force bool) error {
+	command, err := s.getCommand(id)
+	if err != nil {
+		return err
+	}
+	if !command.IsEditable() {
+		return &InvalidCommandStatusError{ID: id, Status: command.Status, Action: "run"}
+	}
+	command, err = s.fillPlaceholders(command, values)
+	if err != nil {
+		return err
+	}
+	if command.RiskLevel.RequiresConfirmation() && !force {
+		return &DangerousCommandError{ID: id, Reason: command.RiskReason}
+	}
+	if err := s.historyService.RecordCommandsUsage([]*models.Command{command}); err != nil {
+		slog.Warn("Unable to record command usage", "id", id, "error", err)
+	}
+
+	runTask := s.taskService.RunCommand(ctx, command, task.Streams{Stdin: s.in, Stdout: s.out, Stderr: s.errOut})
+	switch runTask.Status() {
+	case task.Errored:
+		return runTask.Err()
+	case task.Canceled:
+		return &CommandExitError{ID: id, ExitCode: canceledExitCode}
+	case task.Pending, task.Queued, task.Running, task.Exited:
+	}
+	if runTask.ExitCode() != 0 {
+		return &CommandExitError{ID: id, ExitCode: runTask.ExitCode()}
+	}
+	return nil
+}
+
+// fillPlaceholders returns a copy of the command whose placeholders are replaced by their value
+func (s *CliService) fillPlaceholders(command *models.Command, values map[string]string) (*models.Command, error) {
+	placeholders, err := s.historyService.GetPlaceholders([]*models.Command{command})
+	if err != nil {
+		return nil, err
+	}
+	for name := range values {
+		if !slices.ContainsFunc(placeholders, func(placeholder *models.Placeholder) bool {
+			return placeholder.Name == name
+		}) {
+			return nil, &UnknownPlaceholderError{ID: command.ID, Name: name}
+		}
+	}
+	if len(placeholders) == 0 {
+		return command, nil
+	}
+	filledValues := make(map[string]string, len(placeholders))
+	for _, placeholder := range placeholders {
+		value, ok := values[placeholder.Name]
+		if !ok {
+			value = placeholder.GetInitialValue()
+		}
+		if !ok && value == "" {
+			return nil, &MissingPlaceholderValueError{Name: placeholder.Name}
+		}
+		filledValues[placeholder.Name] = value
+	}
+	filled, err := s.historyService.FillPlaceholders([]*models.Command{command}, filledValues)
+	if err != nil {
+		return nil, err
+	}
+	return filled[0], nil
+}
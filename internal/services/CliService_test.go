@@ -0,0 +1,177 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestCliService creates a CliService whose stdout and stderr are both written to the returned buffer
+func newTestCliService(t *testing.T, in string) (*CliService, *DBService, *bytes.Buffer) {
+	t.Helper()
+	dbService := newTestDBService(t)
+	historyService := NewHistoryService(nil, dbService, NewLintService())
+	taskService := NewTaskService(&scriptExecutor{}, 1, historyService)
+	t.Cleanup(taskService.Stop)
+	out := &bytes.Buffer{}
+	return NewCliService(historyService, dbService, taskService, strings.NewReader(in), out, out), dbService, out
+}
+
+func TestCliService_AddListShow(t *testing.T) {
+	cliService, dbService, out := newTestCliService(t, "git log \\\n  --oneline\n")
+
+	require.NoError(t, cliService.Add("docker ps -a", "containers", "", []string{"docker"}, "ops/docker"))
+	assert.Equal(t, "Command #1 added\n", out.String())
+	require.NoError(t, cliService.Add("-", "history", "", nil, ""))
+	require.ErrorIs(t, cliService.Add("  ", "", "", nil, ""), &EmptyScriptError{})
+	var titleTooLong *CommandTitleTooLongError
+	require.ErrorAs(t, cliService.Add(
+		"ls -la", strings.Repeat("a", models.CommandTitleMaxLength+1), "", nil, "",
+	), &titleTooLong)
+
+	command, err := dbService.GetCommandByID(2)
+	require.NoError(t, err)
+	assert.Equal(t, "git log \\\n  --oneline", command.Script, "the script is read from the input")
+	assert.Equal(t, models.CommandStatusSaved, command.Status)
+
+	t.Run("list", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, cliService.List(ParseCommandStatuses([]string{"saved"}), nil, "", CommandOutputFormatText))
+		assert.Equal(t, ""+
+			"ID  STATUS  TITLE       SCRIPT\n"+
+			"1   SAVED   containers  docker ps -a\n"+
+			"2   SAVED   history     git log \\\\n  --oneline\n",
+			out.String(),
+		)
+	})
+
+	t.Run("list filtered by tag and folder", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, cliService.List(
+			ParseCommandStatuses([]string{"saved"}), []string{"DOCKER"}, "ops", CommandOutputFormatText,
+		))
+		assert.Contains(t, out.String(), "containers")
+		assert.NotContains(t, out.String(), "history")
+
+		var notFound *FolderPathNotFoundError
+		require.ErrorAs(t, cliService.List(nil, nil, "unknown", CommandOutputFormatText), &notFound)
+	})
+
+	t.Run("search as json lines", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, cliService.Search("docker", nil, CommandOutputFormatJSONLines))
+		var command models.CommandOutput
+		require.NoError(t, json.Unmarshal(out.Bytes(), &command))
+		assert.Equal(t, resource.ID(1), command.ID)
+		assert.Equal(t, "ops/docker", command.Folder)
+		assert.Equal(t, []string{"docker"}, command.Tags)
+	})
+
+	t.Run("show", func(t *testing.T) {
+		out.Reset()
+		require.NoError(t, cliService.Show(1, CommandOutputFormatText))
+		assert.Contains(t, out.String(), "Folder:       ops/docker\n")
+		assert.Contains(t, out.String(), "Tags:         docker\n")
+		assert.True(t, strings.HasSuffix(out.String(), "Script:\ndocker ps -a\n"))
+
+		var notFound *CommandNotFoundError
+		require.ErrorAs(t, cliService.Show(42, CommandOutputFormatText), &notFound)
+	})
+}
+
+func TestCliService_DeleteRestore(t *testing.T) {
+	cliService, dbService, out := newTestCliService(t, "")
+	command := saveTestCommand(t, dbService, "make build")
+
+	require.NoError(t, cliService.Delete([]resource.ID{command.ID}))
+	require.NoError(t, cliService.Delete([]resource.ID{command.ID}))
+	assert.Equal(t, "1 command(s) marked as deleted\n0 command(s) marked as deleted\n", out.String())
+	deleted, err := dbService.GetCommandByID(command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommandStatusDeleted, deleted.Status)
+
+	out.Reset()
+	require.NoError(t, cliService.Restore([]resource.ID{command.ID}))
+	assert.Equal(t, "1 command(s) restored\n", out.String())
+	restored, err := dbService.GetCommandByID(command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.CommandStatusSaved, restored.Status)
+
+	var notFound *CommandNotFoundError
+	require.ErrorAs(t, cliService.Delete([]resource.ID{command.ID, 42}), &notFound)
+}
+
+func TestCliService_Edit(t *testing.T) {
+	cliService, dbService, out := newTestCliService(t, "")
+	command := saveTestCommand(t, dbService, "make build")
+
+	cliService.editFile = func(path string) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		assert.Contains(t, string(content), "script: make build")
+		return os.WriteFile(path, []byte("title: build\ntags: [make]\nscript: make build test\n"), 0o600)
+	}
+	require.NoError(t, cliService.Edit(command.ID))
+	assert.Equal(t, "Command #1 saved\n", out.String())
+	edited, err := dbService.GetCommandByID(command.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "build", edited.Title)
+	assert.Equal(t, []string{"make"}, edited.Tags)
+	assert.Equal(t, "make build test", edited.Script)
+
+	t.Run("unchanged", func(t *testing.T) {
+		out.Reset()
+		cliService.editFile = func(string) error { return nil }
+		require.NoError(t, cliService.Edit(command.ID))
+		assert.Equal(t, "Command #1 unchanged\n", out.String())
+	})
+}
+
+func TestCliService_Run(t *testing.T) {
+	cliService, dbService, out := newTestCliService(t, "")
+	// the test executor exits with the length of the script modulo 2
+	even := saveTestCommand(t, dbService, "echo {{name}}")
+	odd := saveTestCommand(t, dbService, "exit 1;")
+	dangerous := saveTestCommand(t, dbService, "rm -rf /")
+	dangerous.RiskLevel, dangerous.RiskReason = models.RiskLevelHigh, "rm_recursive"
+	require.NoError(t, dbService.UpdateCommand(dangerous))
+
+	t.Run("placeholders", func(t *testing.T) {
+		var missing *MissingPlaceholderValueError
+		require.ErrorAs(t, cliService.Run(context.Background(), even.ID, nil, false), &missing)
+		var unknown *UnknownPlaceholderError
+		require.ErrorAs(t, cliService.Run(context.Background(), even.ID, map[string]string{"other": "x"}, false), &unknown)
+
+		require.NoError(t, cliService.Run(context.Background(), even.ID, map[string]string{"name": "world"}, false))
+		assert.Equal(t, "echo world\n", out.String())
+		// the last value entered is used
+		out.Reset()
+		require.NoError(t, cliService.Run(context.Background(), even.ID, nil, false))
+		assert.Equal(t, "echo world\n", out.String())
+	})
+
+	t.Run("exit code", func(t *testing.T) {
+		var exitErr *CommandExitError
+		require.ErrorAs(t, cliService.Run(context.Background(), odd.ID, nil, false), &exitErr)
+		assert.Equal(t, 1, exitErr.ExitCode)
+	})
+
+	t.Run("dangerous command", func(t *testing.T) {
+		var dangerousErr *DangerousCommandError
+		require.ErrorAs(t, cliService.Run(context.Background(), dangerous.ID, nil, false), &dangerousErr)
+		assert.Equal(t, "rm_recursive", dangerousErr.Reason)
+		require.NoError(t, cliService.Run(context.Background(), dangerous.ID, nil, true))
+	})
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// CommandOutputFormat is the format of the commands written by the sub commands of the command line
+type CommandOutputFormat string
+
+const (
+	// CommandOutputFormatText is an aligned table for humans, its layout can change
+	CommandOutputFormatText CommandOutputFormat = "text"
+	// CommandOutputFormatJSON is an indented json array of models.CommandOutput
+	CommandOutputFormatJSON CommandOutputFormat = "json"
+	// CommandOutputFormatJSONLines is one models.CommandOutput json object per line
+	CommandOutputFormatJSONLines CommandOutputFormat = "jsonl"
+	// CommandOutputFormatTSV is a header line then one line per command,
+	// the tabs, new lines and backslashes of the values are escaped
+	CommandOutputFormatTSV CommandOutputFormat = "tsv"
+	// CommandOutputFormatScript0 is the scripts only, each one followed by a null character
+	CommandOutputFormatScript0 CommandOutputFormat = "script0"
+)
+
+// NeedsFolderPaths returns true if the format displays the path of the folders
+func (format CommandOutputFormat) NeedsFolderPaths() bool {
+	return format == CommandOutputFormatJSON || format == CommandOutputFormatJSONLines ||
+		format == CommandOutputFormatTSV
+}
+
+// tsvColumns are the columns of the tsv output format, see doc/output-formats.md
+var tsvColumns = []string{
+	"id", "status", "title", "description", "folder", "tags", "lintStatus", "lintIssueCount",
+	"riskLevel", "riskReason", "useCount", "lastUsedDatetime", "lastRunStatus", "script",
+}
+
+// tsvReplacer escapes the characters that cannot be written as is in a tsv value
+var tsvReplacer = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// CommandOutputWriter writes the commands in the given format,
+// folderPaths gives the path of the folders of the commands
+type CommandOutputWriter struct {
+	out         io.Writer
+	folderPaths map[resource.ID]string
+	format      CommandOutputFormat
+}
+
+// NewCommandOutputWriter creates a CommandOutputWriter writing to out
+func NewCommandOutputWriter(
+	out io.Writer, format CommandOutputFormat, folderPaths map[resource.ID]string,
+) *CommandOutputWriter {
+	return &CommandOutputWriter{
+		out:         out,
+		folderPaths: folderPaths,
+		format:      format,
+	}
+}
+
+// WriteCommands writes all the commands, an empty json array is written if there is no command
+func (w *CommandOutputWriter) WriteCommands(commands []*models.Command) error {
+	switch w.format {
+	case CommandOutputFormatJSON:
+		return w.writeJSON(w.getCommandOutputs(commands))
+	case CommandOutputFormatJSONLines:
+		encoder := json.NewEncoder(w.out)
+		for _, output := range w.getCommandOutputs(commands) {
+			if err := encoder.Encode(output); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CommandOutputFormatTSV:
+		return w.writeTSV(commands)
+	case CommandOutputFormatScript0:
+		for _, command := range commands {
+			if _, err := io.WriteString(w.out, command.Script+"\x00"); err != nil {
+				return err
+			}
+		}
+		return nil
+	case CommandOutputFormatText:
+		return w.writeTable(commands)
+	default:
+		return &UnknownCommandOutputFormatError{Format: string(w.format)}
+	}
+}
+
+// WriteCommand writes a single command, the json format writes an object instead of an array
+func (w *CommandOutputWriter) WriteCommand(command *models.Command) error {
+	if w.format == CommandOutputFormatJSON {
+		return w.writeJSON(w.getCommandOutputs([]*models.Command{command})[0])
+	}
+	return w.WriteCommands([]*models.Command{command})
+}
+
+func (w *CommandOutputWriter) getCommandOutputs(commands []*models.Command) []*models.CommandOutput {
+	outputs := make([]*models.CommandOutput, 0, len(commands))
+	for _, command := range commands {
+		outputs = append(outputs, models.NewCommandOutput(command, w.folderPaths[command.FolderID]))
+	}
+	return outputs
+}
+
+func (w *CommandOutputWriter) writeJSON(value any) error {
+	encoder := json.NewEncoder(w.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+func (w *CommandOutputWriter) writeTSV(commands []*models.Command) error {
+	if _, err := fmt.Fprintln(w.out, strings.Join(tsvColumns, "\t")); err != nil {
+		return err
+	}
+	for _, command := range commands {
+		values := []string{
+			strconv.FormatInt(int64(command.ID), 10),
+			string(command.Status),
+			command.Title,
+			command.Description,
+			w.folderPaths[command.FolderID],
+			strings.Join(command.Tags, models.TagsSeparator),
+			string(command.LintStatus),
+			strconv.Itoa(len(command.GetLintIssues())),
+			string(command.RiskLevel),
+			command.RiskReason,
+			strconv.Itoa(command.UseCount),
+			formatOptionalRFC3339(command.LastUsedDatetime),
+			string(command.LastRunStatus),
+			command.Script,
+		}
+		for i, value := range values {
+			values[i] = tsvReplacer.Replace(value)
+		}
+		if _, err := fmt.Fprintln(w.out, strings.Join(values, "\t")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeTable writes one command per line, the script on a single line
+func (w *CommandOutputWriter) writeTable(commands []*models.Command) error {
+	writer := tabwriter.NewWriter(w.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tSTATUS\tTITLE\tSCRIPT")
+	for _, command := range commands {
+		fmt.Fprintf(writer, "%d\t%s\t%s\t%s\n",
+			command.ID, command.Status, command.Title, strings.ReplaceAll(command.Script, "\n", "\\n"),
+		)
+	}
+	return writer.Flush()
+}
+
+func formatOptionalRFC3339(value time.Time) string {
+	if value.IsZero() {
+		return ""
+	}
+	return value.Format(time.RFC3339)
+}
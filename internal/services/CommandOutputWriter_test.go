@@ -0,0 +1,108 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestOutputCommands() []*models.Command {
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	linted := models.NewCommand("echo $1\tdone", 0, created)
+	linted.ID = 1
+	linted.Title = "echo"
+	linted.Status = models.CommandStatusSaved
+	linted.FolderID = 3
+	linted.Tags = []string{"demo", "shell"}
+	linted.LintStatus = models.LintStatusWarning
+	linted.LintIssues = `[{"code":2086,"level":"info","message":"Double quote to prevent globbing"}]`
+	linted.ModificationDatetime = created
+	linted.LastUsedDatetime = created.Add(time.Hour)
+	linted.Placeholders = []*models.PlaceholderDefinition{{Name: "env", Description: "", AllowedValues: nil}}
+
+	multiline := models.NewCommand("make build \\\n  test", 0, created)
+	multiline.ID = 2
+	multiline.ModificationDatetime = created
+	return []*models.Command{linted, multiline}
+}
+
+func writeTestOutput(t *testing.T, format CommandOutputFormat, commands ...*models.Command) string {
+	t.Helper()
+	out := &bytes.Buffer{}
+	writer := NewCommandOutputWriter(out, format, map[resource.ID]string{3: "ops/demo"})
+	require.NoError(t, writer.WriteCommands(commands))
+	return out.String()
+}
+
+func TestCommandOutputWriter_JSON(t *testing.T) {
+	commands := newTestOutputCommands()
+
+	var outputs []map[string]any
+	require.NoError(t, json.Unmarshal([]byte(writeTestOutput(t, CommandOutputFormatJSON, commands...)), &outputs))
+	require.Len(t, outputs, 2)
+	assert.InDelta(t, 1, outputs[0]["id"], 0)
+	assert.Equal(t, "ops/demo", outputs[0]["folder"])
+	assert.Equal(t, "WARNING", outputs[0]["lintStatus"])
+	assert.Equal(t, []any{map[string]any{
+		"code": float64(2086), "level": "info", "message": "Double quote to prevent globbing",
+	}}, outputs[0]["lintIssues"])
+	assert.Equal(t, []any{map[string]any{
+		"name": "env", "description": "", "allowedValues": []any{},
+	}}, outputs[0]["placeholders"])
+	assert.Equal(t, "2026-01-02T04:04:05Z", outputs[0]["lastUsedDatetime"])
+	// the fields are present even if they are not set
+	assert.Contains(t, outputs[1], "lastUsedDatetime")
+	assert.Nil(t, outputs[1]["lastUsedDatetime"])
+	assert.Equal(t, "", outputs[1]["folder"])
+	assert.Equal(t, []any{}, outputs[1]["tags"])
+	assert.Equal(t, []any{}, outputs[1]["lintIssues"])
+
+	assert.Equal(t, "[]\n", writeTestOutput(t, CommandOutputFormatJSON))
+}
+
+func TestCommandOutputWriter_JSONLines(t *testing.T) {
+	output := writeTestOutput(t, CommandOutputFormatJSONLines, newTestOutputCommands()...)
+	lines := strings.Split(strings.TrimSuffix(output, "\n"), "\n")
+	require.Len(t, lines, 2)
+	var command models.CommandOutput
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &command))
+	assert.Equal(t, resource.ID(2), command.ID)
+	assert.Equal(t, "make build \\\n  test", command.Script)
+}
+
+func TestCommandOutputWriter_TSV(t *testing.T) {
+	assert.Equal(t, ""+
+		"id\tstatus\ttitle\tdescription\tfolder\ttags\tlintStatus\tlintIssueCount\t"+
+		"riskLevel\triskReason\tuseCount\tlastUsedDatetime\tlastRunStatus\tscript\n"+
+		"1\tSAVED\techo\t\tops/demo\tdemo,shell\tWARNING\t1\t\t\t0\t2026-01-02T04:04:05Z\t\techo $1\\tdone\n"+
+		"2\tIMPORTED\t\t\t\t\tNOT_AVAILABLE\t0\t\t\t0\t\t\tmake build \\\\\\n  test\n",
+		writeTestOutput(t, CommandOutputFormatTSV, newTestOutputCommands()...),
+	)
+}
+
+func TestCommandOutputWriter_Script0(t *testing.T) {
+	assert.Equal(t,
+		"echo $1\tdone\x00make build \\\n  test\x00",
+		writeTestOutput(t, CommandOutputFormatScript0, newTestOutputCommands()...),
+	)
+}
+
+func TestCommandOutputWriter_WriteCommand(t *testing.T) {
+	out := &bytes.Buffer{}
+	writer := NewCommandOutputWriter(out, CommandOutputFormatJSON, map[resource.ID]string{})
+	require.NoError(t, writer.WriteCommand(newTestOutputCommands()[1]))
+	var output models.CommandOutput
+	require.NoError(t, json.Unmarshal(out.Bytes(), &output), "a single object is written")
+	assert.Equal(t, resource.ID(2), output.ID)
+
+	writer = NewCommandOutputWriter(out, "xml", map[resource.ID]string{})
+	var unknown *UnknownCommandOutputFormatError
+	require.ErrorAs(t, writer.WriteCommand(newTestOutputCommands()[1]), &unknown)
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the content of the configuration file, the settings it does not define keep their default value
+type Config struct {
+	Ingestion IngestionFilterConfig  `yaml:"ingestion"`
+	Secrets   SecretScannerConfig    `yaml:"secrets"`
+	Danger    DangerClassifierConfig `yaml:"danger"`
+}
+
+// DefaultConfig returns the configuration used when there is no configuration file
+func DefaultConfig() *Config {
+	return &Config{
+		Ingestion: DefaultIngestionFilterConfig(),
+		Secrets:   DefaultSecretScannerConfig(),
+		Danger:    DefaultDangerClassifierConfig(),
+	}
+}
+
+// DefaultConfigPath returns the path of the configuration file used when none is provided,
+// $XDG_CONFIG_HOME/shell-command-bookmarker/config.yaml
+func DefaultConfigPath() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "shell-command-bookmarker", "config.yaml")
+}
+
+// LoadConfig reads the yaml configuration file, the default configuration file is used if path is empty.
+// A missing default configuration file gives the default configuration.
+func LoadConfig(path string) (*Config, error) {
+	config := DefaultConfig()
+	optional := path == ""
+	if optional {
+		path = DefaultConfigPath()
+		if path == "" {
+			return config, nil
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if optional && errors.Is(err, os.ErrNotExist) {
+			return config, nil
+		}
+		return nil, &InvalidConfigFileError{FilePath: path, Err: err}
+	}
+	// the lists defined in the file replace the default ones
+	if err := yaml.Unmarshal(content, config); err != nil {
+		return nil, &InvalidConfigFileError{FilePath: path, Err: err}
+	}
+	if _, err := NewIngestionFilter(config.Ingestion); err != nil {
+		return nil, &InvalidConfigFileError{FilePath: path, Err: err}
+	}
+	if _, err := NewSecretScanner(config.Secrets); err != nil {
+		return nil, &InvalidConfigFileError{FilePath: path, Err: err}
+	}
+	if _, err := NewDangerClassifier(config.Danger); err != nil {
+		return nil, &InvalidConfigFileError{FilePath: path, Err: err}
+	}
+	return config, nil
+}
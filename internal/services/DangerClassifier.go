@@ -0,0 +1,165 @@
+package services
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+)
+
+// DangerRuleConfig is a regexp matching the scripts having the given risk level (low, medium or high)
+type DangerRuleConfig struct {
+	Name   string `yaml:"name"`
+	Regexp string `yaml:"regexp"`
+	Level  string `yaml:"level"`
+}
+
+// DangerClassifierConfig defines the rules giving the risk level of the commands
+type DangerClassifierConfig struct {
+	// Rules are added to the built-in rules, a rule having the name of a built-in rule replaces it,
+	// the level none disables it
+	Rules []DangerRuleConfig `yaml:"rules"`
+}
+
+// DefaultDangerClassifierConfig returns the configuration used when no configuration file overrides it
+func DefaultDangerClassifierConfig() DangerClassifierConfig {
+	return DangerClassifierConfig{
+		Rules: []DangerRuleConfig{},
+	}
+}
+
+// dangerArgs matches the arguments of a command up to the next pipe or command separator
+const dangerArgs = `(?:[^|;&\n]*\s)?`
+
+// builtinDangerRules detect the most common destructive commands
+func builtinDangerRules() []DangerRuleConfig {
+	high := string(models.RiskLevelHigh)
+	medium := string(models.RiskLevelMedium)
+	return []DangerRuleConfig{
+		{
+			Name: "rm_recursive_force",
+			Regexp: `\brm\s` + dangerArgs + `(?:-[a-zA-Z]*(?:[rR][a-zA-Z]*f|f[a-zA-Z]*[rR])` +
+				`|(?:-[rR]|--recursive)\s` + dangerArgs + `(?:-f|--force)\b` +
+				`|(?:-f|--force)\s` + dangerArgs + `(?:-[rR]|--recursive)\b)`,
+			Level: high,
+		},
+		{Name: "rm_recursive", Regexp: `\brm\s` + dangerArgs + `(?:-[a-zA-Z]*[rR]|--recursive)\b`, Level: medium},
+		{
+			Name:   "git_force_push",
+			Regexp: `\bgit\s` + dangerArgs + `push\s` + dangerArgs + `(?:-f|--force(?:-with-lease)?)(?:[\s=]|$)`,
+			Level:  high,
+		},
+		{Name: "git_reset_hard", Regexp: `\bgit\s` + dangerArgs + `reset\s` + dangerArgs + `--hard\b`, Level: medium},
+		{Name: "git_clean_force", Regexp: `\bgit\s` + dangerArgs + `clean\s` + dangerArgs + `-[a-zA-Z]*f`, Level: medium},
+		{Name: "kubectl_delete", Regexp: `\bkubectl\s` + dangerArgs + `delete\b`, Level: high},
+		{Name: "helm_uninstall", Regexp: `\bhelm\s` + dangerArgs + `(?:uninstall|delete)\b`, Level: medium},
+		{Name: "terraform_destroy", Regexp: `\b(?:terraform|tofu)\s` + dangerArgs + `destroy\b`, Level: high},
+		{Name: "dd_device", Regexp: `\bdd\s[^|;&\n]*\bof=/dev/`, Level: high},
+		{Name: "device_overwrite", Regexp: `>\s*/dev/(?:sd|hd|vd|xvd|nvme|mmcblk|disk)`, Level: high},
+		{Name: "mkfs", Regexp: `\b(?:mkfs(?:\.[a-z0-9]+)?|mkswap|wipefs)\s`, Level: high},
+		{Name: "sql_drop", Regexp: `(?i)\bdrop\s+(?:table|database|schema)\b`, Level: high},
+		{Name: "sql_truncate", Regexp: `(?i)\btruncate\s+table\b`, Level: high},
+		{Name: "sql_delete_all", Regexp: "(?i)\\bdelete\\s+from\\s+[\\w.`\"]+\\s*(?:;|[\"']|$)", Level: medium},
+		{Name: "fork_bomb", Regexp: `:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`, Level: high},
+		{
+			Name:   "chmod_recursive",
+			Regexp: `\b(?:chmod|chown|chgrp)\s` + dangerArgs + `(?:-[a-zA-Z]*R|--recursive)\b`,
+			Level:  medium,
+		},
+		{Name: "find_delete", Regexp: `\bfind\s[^|;&\n]*\s-delete\b`, Level: medium},
+		{
+			Name:   "docker_prune",
+			Regexp: `\bdocker\s` + dangerArgs + `(?:system|volume|image|container|network)\s+prune\b`,
+			Level:  medium,
+		},
+		{
+			Name:   "system_power",
+			Regexp: `(?m)(?:^|[;&|(]\s*|\bsudo\s+|\bsystemctl\s+)(?:shutdown|reboot|halt|poweroff)\b`,
+			Level:  medium,
+		},
+		{
+			Name:   "pipe_to_shell",
+			Regexp: `\b(?:curl|wget)\s[^|;&\n]*\|\s*(?:sudo\s+)?(?:ba|z|da|k)?sh\b`,
+			Level:  medium,
+		},
+	}
+}
+
+type dangerRule struct {
+	re    *regexp.Regexp
+	name  string
+	level models.RiskLevel
+}
+
+// DangerClassifier gives the risk level of the commands with the built-in and the configured rules
+type DangerClassifier struct {
+	rules []dangerRule
+}
+
+// NewDangerClassifier compiles the built-in rules and the rules of the configuration
+func NewDangerClassifier(config DangerClassifierConfig) (*DangerClassifier, error) {
+	ruleConfigs := builtinDangerRules()
+	for _, ruleConfig := range config.Rules {
+		index := slices.IndexFunc(ruleConfigs, func(builtin DangerRuleConfig) bool {
+			return builtin.Name == ruleConfig.Name
+		})
+		if index >= 0 {
+			ruleConfigs[index] = ruleConfig
+		} else {
+			ruleConfigs = append(ruleConfigs, ruleConfig)
+		}
+	}
+
+	rules := make([]dangerRule, 0, len(ruleConfigs))
+	for _, ruleConfig := range ruleConfigs {
+		level, ok := models.ParseRiskLevel(ruleConfig.Level)
+		if !ok {
+			return nil, &InvalidRiskLevelError{Rule: ruleConfig.Name, Level: ruleConfig.Level}
+		}
+		if level == models.RiskLevelNone {
+			// disabled rule
+			continue
+		}
+		re, err := regexp.Compile(ruleConfig.Regexp)
+		if err != nil {
+			return nil, &InvalidDangerRuleRegexpError{Rule: ruleConfig.Name, Regexp: ruleConfig.Regexp, Err: err}
+		}
+		rules = append(rules, dangerRule{re: re, name: ruleConfig.Name, level: level})
+	}
+	return &DangerClassifier{rules: rules}, nil
+}
+
+// MustNewDangerClassifier is like NewDangerClassifier but panics if the configuration is invalid
+func MustNewDangerClassifier(config DangerClassifierConfig) *DangerClassifier {
+	classifier, err := NewDangerClassifier(config)
+	if err != nil {
+		panic(err)
+	}
+	return classifier
+}
+
+// Classify returns the highest risk level of the rules matching the script
+// and the names of the rules having this level, the reason of the risk
+func (c *DangerClassifier) Classify(script string) (level models.RiskLevel, reason string) {
+	level = models.RiskLevelNone
+	names := []string{}
+	for _, rule := range c.rules {
+		if !rule.re.MatchString(script) {
+			continue
+		}
+		switch {
+		case rule.level.Rank() > level.Rank():
+			level = rule.level
+			names = []string{rule.name}
+		case rule.level == level && !slices.Contains(names, rule.name):
+			names = append(names, rule.name)
+		}
+	}
+	return level, strings.Join(names, ", ")
+}
+
+// ClassifyCommand sets the risk level and the risk reason of the command from its script
+func (c *DangerClassifier) ClassifyCommand(command *models.Command) {
+	command.RiskLevel, command.RiskReason = c.Classify(command.Script)
+}
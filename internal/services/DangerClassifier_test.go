@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDangerClassifier_Classify(t *testing.T) {
+	classifier := MustNewDangerClassifier(DefaultDangerClassifierConfig())
+
+	tests := []struct {
+		name   string
+		script string
+		level  models.RiskLevel
+		reason string
+	}{
+		{"RmRecursiveForce", "rm -rf /tmp/build", models.RiskLevelHigh, "rm_recursive_force"},
+		{"RmSeparateOptions", "sudo rm -r -f ./dist", models.RiskLevelHigh, "rm_recursive_force"},
+		{"RmLongOptions", "rm --force --recursive build", models.RiskLevelHigh, "rm_recursive_force"},
+		{"RmRecursive", "rm -r build", models.RiskLevelMedium, "rm_recursive"},
+		{"RmFile", "rm -f build.log", models.RiskLevelNone, ""},
+		{"GitForcePush", "git push --force origin main", models.RiskLevelHigh, "git_force_push"},
+		{"GitForcePushShort", "git push -f", models.RiskLevelHigh, "git_force_push"},
+		{"GitPush", "git push origin feature-force", models.RiskLevelNone, ""},
+		{"KubectlDelete", "kubectl -n prod delete pod web-1", models.RiskLevelHigh, "kubectl_delete"},
+		{"KubectlGet", "kubectl get pods | grep delete", models.RiskLevelNone, ""},
+		{"DdDevice", "dd if=image.iso of=/dev/sdb bs=4M", models.RiskLevelHigh, "dd_device"},
+		{"DdFile", "dd if=/dev/zero of=disk.img bs=1M count=10", models.RiskLevelNone, ""},
+		{"DropTable", `psql -c "DROP TABLE users"`, models.RiskLevelHigh, "sql_drop"},
+		{"DeleteAll", `mysql -e "delete from users"`, models.RiskLevelMedium, "sql_delete_all"},
+		{"DeleteWhere", `mysql -e "delete from users where id = 1"`, models.RiskLevelNone, ""},
+		{"PipeToShell", "curl -fsSL https://get.example.com | sudo bash", models.RiskLevelMedium, "pipe_to_shell"},
+		{
+			"SeveralRules",
+			"git reset --hard && git clean -fdx",
+			models.RiskLevelMedium,
+			"git_reset_hard, git_clean_force",
+		},
+		{"HighestLevel", "git reset --hard && rm -rf node_modules", models.RiskLevelHigh, "rm_recursive_force"},
+		{"Safe", "docker ps -a | grep web", models.RiskLevelNone, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, reason := classifier.Classify(tt.script)
+			assert.Equal(t, tt.level, level)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestNewDangerClassifier_UserRules(t *testing.T) {
+	classifier, err := NewDangerClassifier(DangerClassifierConfig{
+		Rules: []DangerRuleConfig{
+			{Name: "prod_database", Regexp: `--host[= ]prod-db`, Level: "high"},
+			{Name: "rm_recursive", Regexp: "", Level: "none"},
+			{Name: "kubectl_delete", Regexp: `\bkubectl\s+delete\b`, Level: "Medium"},
+		},
+	})
+	require.NoError(t, err)
+
+	level, reason := classifier.Classify("psql --host=prod-db -c 'select 1'")
+	assert.Equal(t, models.RiskLevelHigh, level)
+	assert.Equal(t, "prod_database", reason)
+
+	level, _ = classifier.Classify("rm -r build")
+	assert.Equal(t, models.RiskLevelNone, level, "built-in rule disabled")
+
+	level, _ = classifier.Classify("kubectl delete pod web-1")
+	assert.Equal(t, models.RiskLevelMedium, level, "built-in rule replaced")
+}
+
+func TestNewDangerClassifier_InvalidRules(t *testing.T) {
+	_, err := NewDangerClassifier(DangerClassifierConfig{
+		Rules: []DangerRuleConfig{{Name: "helm", Regexp: "helm", Level: "critical"}},
+	})
+	var levelErr *InvalidRiskLevelError
+	require.ErrorAs(t, err, &levelErr)
+	assert.Equal(t, "helm", levelErr.Rule)
+
+	_, err = NewDangerClassifier(DangerClassifierConfig{
+		Rules: []DangerRuleConfig{{Name: "helm", Regexp: "(helm", Level: "high"}},
+	})
+	var regexpErr *InvalidDangerRuleRegexpError
+	require.ErrorAs(t, err, &regexpErr)
+	assert.Equal(t, "helm", regexpErr.Rule)
+	assert.Contains(t, err.Error(), "danger.rules")
+}
@@ -3,29 +3,37 @@ package services
 import (
 	"database/sql"
 	"errors"
+	"io/fs"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/db"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/search"
+)
+
+// bm25 weights of the command_fts columns
+const (
+	ftsTitleWeight       = 10.0
+	ftsDescriptionWeight = 5.0
+	ftsScriptWeight      = 1.0
 )
 
 type DBService struct {
-	dbAdapter  db.Adapter
-	dbPath     string
-	schemaPath string
+	dbAdapter db.Adapter
+	dbPath    string
 }
 
 func NewDBService(
 	dbPath string,
-	schemaPath string,
+	migrationsFS fs.FS,
 ) *DBService {
 	return &DBService{
-		dbAdapter:  db.NewSQLiteAdapter(dbPath, schemaPath),
-		dbPath:     dbPath,
-		schemaPath: schemaPath,
+		dbAdapter: db.NewSQLiteAdapter(dbPath, migrationsFS),
+		dbPath:    dbPath,
 	}
 }
 
@@ -42,16 +50,89 @@ func (s *DBService) GetDBAdapter() db.Adapter {
 }
 
 func (s *DBService) SaveCommand(command *models.Command) error {
+	if err := insertCommand(s.dbAdapter.GetDB(), command); err != nil {
+		return err
+	}
+	if len(command.Tags) > 0 {
+		if err := s.SetCommandTags(command.ID, command.Tags); err != nil {
+			return err
+		}
+	}
+	if len(command.Placeholders) > 0 {
+		return s.SetCommandPlaceholders(command.ID, command.Placeholders)
+	}
+	return nil
+}
+
+// SaveCommands inserts the commands, with their tags and placeholders, in a single transaction
+func (s *DBService) SaveCommands(commands []*models.Command) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		return insertCommands(tx, commands)
+	})
+}
+
+// SaveIngestedHistory inserts the commands imported from a history file, records the occurrences
+// of the known scripts and saves the checkpoint of the history file, if not nil, in a single
+// transaction, so the commands are neither lost nor counted twice by the next ingestion
+func (s *DBService) SaveIngestedHistory(
+	commands []*models.Command, occurrences []*models.CommandOccurrences, checkpoint *models.HistoryCheckpoint,
+) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		if err := insertCommands(tx, commands); err != nil {
+			return err
+		}
+		if err := recordCommandOccurrences(tx, occurrences); err != nil {
+			return err
+		}
+		if checkpoint == nil {
+			return nil
+		}
+		return saveHistoryCheckpoint(tx, checkpoint)
+	})
+}
+
+// insertCommands inserts the commands with their tags and placeholders
+func insertCommands(tx *sql.Tx, commands []*models.Command) error {
+	for _, command := range commands {
+		if err := insertCommand(tx, command); err != nil {
+			return err
+		}
+		if err := addTagsToCommands(tx, []resource.ID{command.ID}, command.Tags); err != nil {
+			return err
+		}
+		if len(command.Placeholders) == 0 {
+			continue
+		}
+		if err := setCommandPlaceholders(tx, command.ID, command.Placeholders); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// execer is implemented by the database and by the transactions
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// insertCommand inserts the command and sets its ID
+func insertCommand(db execer, command *models.Command) error {
 	// Use Exec instead of Query for INSERT statements
-	result, err := s.dbAdapter.GetDB().Exec(
+	result, err := db.Exec(
 		`INSERT INTO command (
 			title, description, script, status,
 			lint_issues, lint_status, elapsed,
-			creation_datetime, modification_datetime
-		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			creation_datetime, modification_datetime, folder_id, history_source_id, secret_reason,
+			occurrence_count, first_seen_datetime, last_seen_datetime,
+			risk_level, risk_reason
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		command.Title, command.Description, command.Script, string(command.Status),
 		command.LintIssues, string(command.LintStatus), command.Elapsed,
 		command.CreationDatetime.Format(time.DateTime), command.ModificationDatetime.Format(time.DateTime),
+		folderIDToNullable(command.FolderID), historySourceIDToNullable(command.HistorySourceID),
+		command.SecretReason,
+		command.OccurrenceCount, optionalDateTime(command.FirstSeenDatetime), optionalDateTime(command.LastSeenDatetime),
+		string(command.RiskLevel), command.RiskReason,
 	)
 	if err != nil {
 		return err
@@ -71,11 +152,17 @@ func (s *DBService) DuplicateCommand(commandID resource.ID, status models.Comman
 		`INSERT INTO command (
 			title, description, script, status,
 			lint_issues, lint_status, elapsed,
-			creation_datetime, modification_datetime
+			creation_datetime, modification_datetime, folder_id,
+			use_count, last_used_datetime, history_source_id, secret_reason,
+			occurrence_count, first_seen_datetime, last_seen_datetime,
+			last_run_datetime, last_run_status, risk_level, risk_reason
 		) SELECT
 			title, description, script, ?,
 			lint_issues, lint_status, elapsed,
-			creation_datetime, ?
+			creation_datetime, ?, folder_id,
+			use_count, last_used_datetime, history_source_id, secret_reason,
+			occurrence_count, first_seen_datetime, last_seen_datetime,
+			last_run_datetime, last_run_status, risk_level, risk_reason
 		FROM command WHERE id = ?`,
 		status,
 		time.Now().Format(time.DateTime),
@@ -89,6 +176,25 @@ func (s *DBService) DuplicateCommand(commandID resource.ID, status models.Comman
 		slog.Error("Error retrieving last insert ID", "error", err)
 		return -1, err
 	}
+	// Duplicated command keeps the tags of the original one
+	_, err = s.dbAdapter.GetDB().Exec(
+		`INSERT INTO command_has_tag (command_id, tag_id)
+		SELECT ?, tag_id FROM command_has_tag WHERE command_id = ?`,
+		lastInsertID, commandID,
+	)
+	if err != nil {
+		slog.Error("Error duplicating command tags", "id", commandID, "error", err)
+		return -1, err
+	}
+	_, err = s.dbAdapter.GetDB().Exec(
+		`INSERT INTO command_placeholder (command_id, name, position, description, allowed_values)
+		SELECT ?, name, position, description, allowed_values FROM command_placeholder WHERE command_id = ?`,
+		lastInsertID, commandID,
+	)
+	if err != nil {
+		slog.Error("Error duplicating command placeholders", "id", commandID, "error", err)
+		return -1, err
+	}
 	return resource.ID(lastInsertID), nil
 }
 
@@ -99,7 +205,12 @@ func (s *DBService) GetCommandByID(id resource.ID) (*models.Command, error) {
 	row := s.dbAdapter.GetDB().QueryRow(
 		`SELECT id, title, description, script, status,
 			lint_issues, lint_status, elapsed,
-			creation_datetime, modification_datetime
+			creation_datetime, modification_datetime, IFNULL(folder_id, 0),
+			use_count, IFNULL(last_used_datetime, ''), IFNULL(history_source_id, 0),
+			secret_reason, occurrence_count,
+			IFNULL(first_seen_datetime, ''), IFNULL(last_seen_datetime, ''),
+			IFNULL(last_run_datetime, ''), IFNULL(last_run_status, ''),
+			risk_level, risk_reason
 			FROM command WHERE id = ? LIMIT 1`,
 		id,
 	)
@@ -107,7 +218,7 @@ func (s *DBService) GetCommandByID(id resource.ID) (*models.Command, error) {
 		slog.Debug("No command found in database", "id", id)
 		return nil, nil
 	}
-	return s.getCommandFromRow(row)
+	return s.getCommandWithTagsFromRow(row)
 }
 
 func (s *DBService) GetCommandByScript(script string) (*models.Command, error) {
@@ -116,7 +227,12 @@ func (s *DBService) GetCommandByScript(script string) (*models.Command, error) {
 	row := s.dbAdapter.GetDB().QueryRow(
 		`SELECT id, title, description, script, status,
 			lint_issues, lint_status, elapsed,
-			creation_datetime, modification_datetime
+			creation_datetime, modification_datetime, IFNULL(folder_id, 0),
+			use_count, IFNULL(last_used_datetime, ''), IFNULL(history_source_id, 0),
+			secret_reason, occurrence_count,
+			IFNULL(first_seen_datetime, ''), IFNULL(last_seen_datetime, ''),
+			IFNULL(last_run_datetime, ''), IFNULL(last_run_status, ''),
+			risk_level, risk_reason
 			FROM command WHERE script = ? LIMIT 1`,
 		script,
 	)
@@ -125,13 +241,64 @@ func (s *DBService) GetCommandByScript(script string) (*models.Command, error) {
 		return nil, nil
 	}
 
-	return s.getCommandFromRow(row)
+	return s.getCommandWithTagsFromRow(row)
+}
+
+// GetCommandScripts returns the set of the scripts of all the commands, whatever their status
+func (s *DBService) GetCommandScripts() (map[string]struct{}, error) {
+	rows, err := s.dbAdapter.GetDB().Query("SELECT DISTINCT script FROM command")
+	if err != nil {
+		slog.Error("Error retrieving command scripts", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+	scripts := map[string]struct{}{}
+	for rows.Next() {
+		var script string
+		if err := rows.Scan(&script); err != nil {
+			return nil, err
+		}
+		scripts[script] = struct{}{}
+	}
+	return scripts, rows.Err()
+}
+
+// GetActiveCommandByScript retrieves the oldest non obsolete command having the given script
+func (s *DBService) GetActiveCommandByScript(script string) (*models.Command, error) {
+	row := s.dbAdapter.GetDB().QueryRow(
+		`SELECT id, title, description, script, status,
+			lint_issues, lint_status, elapsed,
+			creation_datetime, modification_datetime, IFNULL(folder_id, 0),
+			use_count, IFNULL(last_used_datetime, ''), IFNULL(history_source_id, 0),
+			secret_reason, occurrence_count,
+			IFNULL(first_seen_datetime, ''), IFNULL(last_seen_datetime, ''),
+			IFNULL(last_run_datetime, ''), IFNULL(last_run_status, ''),
+			risk_level, risk_reason
+			FROM command WHERE script = ? AND status != ? ORDER BY id LIMIT 1`,
+		script, string(models.CommandStatusObsolete),
+	)
+	return s.getCommandWithTagsFromRow(row)
+}
+
+func (s *DBService) getCommandWithTagsFromRow(row *sql.Row) (*models.Command, error) {
+	command, err := s.getCommandFromRow(row)
+	if err != nil || command == nil {
+		return command, err
+	}
+	if err := s.loadCommandsRelations([]*models.Command{command}); err != nil {
+		return nil, err
+	}
+	return command, nil
 }
 
 func (*DBService) getCommandFromRow(row *sql.Row) (*models.Command, error) {
 	var command models.Command
 	var creationDateStr string
 	var modificationDateStr string
+	var lastUsedDateStr string
+	var firstSeenDateStr string
+	var lastSeenDateStr string
+	var lastRunDateStr string
 
 	err := row.Scan(
 		&command.ID,
@@ -144,6 +311,18 @@ func (*DBService) getCommandFromRow(row *sql.Row) (*models.Command, error) {
 		&command.Elapsed,
 		&creationDateStr,
 		&modificationDateStr,
+		&command.FolderID,
+		&command.UseCount,
+		&lastUsedDateStr,
+		&command.HistorySourceID,
+		&command.SecretReason,
+		&command.OccurrenceCount,
+		&firstSeenDateStr,
+		&lastSeenDateStr,
+		&lastRunDateStr,
+		&command.LastRunStatus,
+		&command.RiskLevel,
+		&command.RiskReason,
 	)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
@@ -163,6 +342,23 @@ func (*DBService) getCommandFromRow(row *sql.Row) (*models.Command, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	command.LastUsedDatetime, err = parseOptionalDateTime(lastUsedDateStr)
+	if err != nil {
+		return nil, err
+	}
+	command.FirstSeenDatetime, err = parseOptionalDateTime(firstSeenDateStr)
+	if err != nil {
+		return nil, err
+	}
+	command.LastSeenDatetime, err = parseOptionalDateTime(lastSeenDateStr)
+	if err != nil {
+		return nil, err
+	}
+	command.LastRunDatetime, err = parseOptionalDateTime(lastRunDateStr)
+	if err != nil {
+		return nil, err
+	}
 	return &command, nil
 }
 
@@ -194,15 +390,18 @@ func (s *DBService) GetMaxCommandTimestamp() (time.Time, error) {
 // GetCommands retrieves commands from the database, optionally filtered by status
 func (s *DBService) GetCommands(statuses ...models.CommandStatus) ([]*models.Command, error) {
 	var commands []*models.Command
-	var creationDateStr string
-	var modificationDateStr string
 	var query string
 	var args []interface{}
 
 	// Base query
 	query = `SELECT id, title, description, script, status,
 		lint_issues, lint_status, elapsed,
-		creation_datetime, modification_datetime
+		creation_datetime, modification_datetime, IFNULL(folder_id, 0),
+		use_count, IFNULL(last_used_datetime, ''), IFNULL(history_source_id, 0),
+		secret_reason, occurrence_count,
+		IFNULL(first_seen_datetime, ''), IFNULL(last_seen_datetime, ''),
+		IFNULL(last_run_datetime, ''), IFNULL(last_run_status, ''),
+		risk_level, risk_reason
 		FROM command`
 
 	// Add status filter if provided
@@ -224,47 +423,391 @@ func (s *DBService) GetCommands(statuses ...models.CommandStatus) ([]*models.Com
 	defer rows.Close()
 
 	for rows.Next() {
-		command := models.Command{
-			ID:                   0,
-			Title:                "",
-			Description:          "",
-			Script:               "",
-			Status:               "",
-			LintIssues:           "",
-			LintStatus:           "",
-			Elapsed:              0,
-			CreationDatetime:     time.Time{},
-			ModificationDatetime: time.Time{},
-			FilterScore:          0,
+		command, err := scanCommand(rows)
+		if err != nil {
+			return nil, err
 		}
-		err := rows.Scan(
-			&command.ID,
-			&command.Title,
-			&command.Description,
-			&command.Script,
-			&command.Status,
-			&command.LintIssues,
-			&command.LintStatus,
-			&command.Elapsed,
-			&creationDateStr,
-			&modificationDateStr,
-		)
+		commands = append(commands, command)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.loadCommandsRelations(commands); err != nil {
+		return nil, err
+	}
+	return commands, nil
+}
+
+// SearchCommands retrieves the commands matching the full text search filter
+// (see search.FTSQuery), optionally filtered by status.
+// Commands are sorted by bm25 rank, title matches weighting more than
+// description and script matches.
+func (s *DBService) SearchCommands(filter string, statuses ...models.CommandStatus) ([]*models.CommandSearchResult, error) {
+	matchQuery := search.FTSQuery(filter)
+	if matchQuery == "" {
+		return []*models.CommandSearchResult{}, nil
+	}
+	query := `SELECT c.id, c.title, c.description, c.script, c.status,
+		c.lint_issues, c.lint_status, c.elapsed,
+		c.creation_datetime, c.modification_datetime, IFNULL(c.folder_id, 0),
+		c.use_count, IFNULL(c.last_used_datetime, ''), IFNULL(c.history_source_id, 0), c.secret_reason,
+		c.occurrence_count, IFNULL(c.first_seen_datetime, ''), IFNULL(c.last_seen_datetime, ''),
+		IFNULL(c.last_run_datetime, ''), IFNULL(c.last_run_status, ''), c.risk_level, c.risk_reason,
+		bm25(command_fts, ?, ?, ?) AS search_rank,
+		snippet(command_fts, 2, ?, ?, ?, ?)
+		FROM command_fts
+		JOIN command c ON c.id = command_fts.rowid
+		WHERE command_fts MATCH ?`
+	args := []any{
+		ftsTitleWeight, ftsDescriptionWeight, ftsScriptWeight,
+		models.SearchHighlightStart, models.SearchHighlightEnd,
+		models.SearchSnippetEllipsis, models.SearchSnippetMaxTokens,
+		matchQuery,
+	}
+	if len(statuses) > 0 {
+		placeholders := make([]string, len(statuses))
+		for i, status := range statuses {
+			placeholders[i] = "?"
+			args = append(args, string(status))
+		}
+		query += " AND c.status IN (" + strings.Join(placeholders, ", ") + ")"
+	}
+	query += " ORDER BY search_rank"
+
+	rows, err := s.dbAdapter.GetDB().Query(query, args...)
+	if err != nil {
+		slog.Error("Error searching commands", "filter", filter, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []*models.CommandSearchResult{}
+	commands := []*models.Command{}
+	for rows.Next() {
+		result := models.CommandSearchResult{
+			Command: nil,
+			Snippet: "",
+			Rank:    0,
+		}
+		result.Command, err = scanCommand(rows, &result.Rank, &result.Snippet)
 		if err != nil {
 			return nil, err
 		}
+		results = append(results, &result)
+		commands = append(commands, result.Command)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if err := s.loadCommandsRelations(commands); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
 
-		command.CreationDatetime, err = time.Parse(time.DateTime, creationDateStr)
+// scanCommand scans the command columns of the current row (see GetCommands),
+// extraDest receives the values of the additional columns
+func scanCommand(rows *sql.Rows, extraDest ...any) (*models.Command, error) {
+	command := models.Command{
+		ID:                   0,
+		FolderID:             models.RootFolderID,
+		Title:                "",
+		Description:          "",
+		Script:               "",
+		Status:               "",
+		LintIssues:           "",
+		LintStatus:           "",
+		Elapsed:              0,
+		CreationDatetime:     time.Time{},
+		ModificationDatetime: time.Time{},
+		LastUsedDatetime:     time.Time{},
+		UseCount:             0,
+		HistorySourceID:      0,
+		SecretReason:         "",
+		OccurrenceCount:      0,
+		FirstSeenDatetime:    time.Time{},
+		LastSeenDatetime:     time.Time{},
+		LastRunDatetime:      time.Time{},
+		LastRunStatus:        models.CommandRunStatusNone,
+		RiskLevel:            models.RiskLevelNone,
+		RiskReason:           "",
+		FilterScore:          0,
+		FilterSnippet:        "",
+		Tags:                 []string{},
+		Placeholders:         []*models.PlaceholderDefinition{},
+	}
+	var creationDateStr string
+	var modificationDateStr string
+	var lastUsedDateStr string
+	var firstSeenDateStr string
+	var lastSeenDateStr string
+	var lastRunDateStr string
+	dest := []any{
+		&command.ID,
+		&command.Title,
+		&command.Description,
+		&command.Script,
+		&command.Status,
+		&command.LintIssues,
+		&command.LintStatus,
+		&command.Elapsed,
+		&creationDateStr,
+		&modificationDateStr,
+		&command.FolderID,
+		&command.UseCount,
+		&lastUsedDateStr,
+		&command.HistorySourceID,
+		&command.SecretReason,
+		&command.OccurrenceCount,
+		&firstSeenDateStr,
+		&lastSeenDateStr,
+		&lastRunDateStr,
+		&command.LastRunStatus,
+		&command.RiskLevel,
+		&command.RiskReason,
+	}
+	if err := rows.Scan(append(dest, extraDest...)...); err != nil {
+		return nil, err
+	}
+
+	var err error
+	command.CreationDatetime, err = time.Parse(time.DateTime, creationDateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	command.ModificationDatetime, err = time.Parse(time.DateTime, modificationDateStr)
+	if err != nil {
+		return nil, err
+	}
+
+	command.LastUsedDatetime, err = parseOptionalDateTime(lastUsedDateStr)
+	if err != nil {
+		return nil, err
+	}
+	command.FirstSeenDatetime, err = parseOptionalDateTime(firstSeenDateStr)
+	if err != nil {
+		return nil, err
+	}
+	command.LastSeenDatetime, err = parseOptionalDateTime(lastSeenDateStr)
+	if err != nil {
+		return nil, err
+	}
+	command.LastRunDatetime, err = parseOptionalDateTime(lastRunDateStr)
+	if err != nil {
+		return nil, err
+	}
+	return &command, nil
+}
+
+// parseOptionalDateTime parses a nullable datetime column, an empty string gives the zero time
+func parseOptionalDateTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.DateTime, value)
+}
+
+// optionalDateTime formats a nullable datetime column, the zero time gives NULL
+func optionalDateTime(value time.Time) sql.NullString {
+	if value.IsZero() {
+		return sql.NullString{String: "", Valid: false}
+	}
+	return sql.NullString{String: value.Format(time.DateTime), Valid: true}
+}
+
+// RecordCommandOccurrences adds the occurrences found in the history files to the
+// non obsolete commands having the same script, in a single transaction.
+// The modification datetime is left unchanged.
+func (s *DBService) RecordCommandOccurrences(occurrences []*models.CommandOccurrences) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		return recordCommandOccurrences(tx, occurrences)
+	})
+}
+
+// recordCommandOccurrences adds the occurrences to the non obsolete commands having the same script
+func recordCommandOccurrences(tx *sql.Tx, occurrences []*models.CommandOccurrences) error {
+	for _, occurrence := range occurrences {
+		firstSeen := occurrence.FirstSeen.Format(time.DateTime)
+		lastSeen := occurrence.LastSeen.Format(time.DateTime)
+		_, err := tx.Exec(
+			`UPDATE command
+			SET occurrence_count = occurrence_count + ?,
+				first_seen_datetime = min(IFNULL(first_seen_datetime, ?), ?),
+				last_seen_datetime = max(IFNULL(last_seen_datetime, ?), ?)
+			WHERE script = ? AND status != ?`,
+			occurrence.Count,
+			firstSeen, firstSeen,
+			lastSeen, lastSeen,
+			occurrence.Script, string(models.CommandStatusObsolete),
+		)
 		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetCommandsLastSeen returns the last occurrence in the history files of the scripts of
+// the non obsolete commands, the scripts never found in the history files are not returned
+func (s *DBService) GetCommandsLastSeen() (map[string]time.Time, error) {
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT script, max(last_seen_datetime) FROM command
+		WHERE last_seen_datetime IS NOT NULL AND status != ?
+		GROUP BY script`,
+		string(models.CommandStatusObsolete),
+	)
+	if err != nil {
+		slog.Error("Error retrieving the last occurrences of the commands", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+	lastSeen := map[string]time.Time{}
+	for rows.Next() {
+		var script, lastSeenDatetime string
+		if err := rows.Scan(&script, &lastSeenDatetime); err != nil {
+			return nil, err
+		}
+		datetime, err := time.Parse(time.DateTime, lastSeenDatetime)
+		if err != nil {
+			return nil, err
+		}
+		lastSeen[script] = datetime
+	}
+	return lastSeen, rows.Err()
+}
+
+// RecordCommandsUsage increments the use count of the given commands and sets
+// their last used datetime, the modification datetime is left unchanged
+func (s *DBService) RecordCommandsUsage(commandIDs []resource.ID, usedAt time.Time) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		for _, commandID := range commandIDs {
+			_, err := tx.Exec(
+				`UPDATE command
+				SET use_count = use_count + 1, last_used_datetime = ?
+				WHERE id = ?`,
+				usedAt.Format(time.DateTime), commandID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetCommandRisks returns the script and the risk level of all the commands
+func (s *DBService) GetCommandRisks() ([]*models.CommandRisk, error) {
+	rows, err := s.dbAdapter.GetDB().Query("SELECT id, script, risk_level, risk_reason FROM command")
+	if err != nil {
+		slog.Error("Error retrieving command risks", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+	risks := []*models.CommandRisk{}
+	for rows.Next() {
+		risk := models.CommandRisk{CommandID: 0, Script: "", Level: models.RiskLevelNone, Reason: ""}
+		if err := rows.Scan(&risk.CommandID, &risk.Script, &risk.Level, &risk.Reason); err != nil {
 			return nil, err
 		}
+		risks = append(risks, &risk)
+	}
+	return risks, rows.Err()
+}
+
+// UpdateCommandRisks sets the risk level of the commands in a single transaction,
+// the modification datetime is left unchanged
+func (s *DBService) UpdateCommandRisks(risks []*models.CommandRisk) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		for _, risk := range risks {
+			_, err := tx.Exec(
+				`UPDATE command SET risk_level = ?, risk_reason = ? WHERE id = ?`,
+				string(risk.Level), risk.Reason, risk.CommandID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
 
-		command.ModificationDatetime, err = time.Parse(time.DateTime, modificationDateStr)
+// SaveCommandRun records the run of a command and sets its last run datetime and status,
+// the oldest runs beyond maxRuns are deleted. The modification datetime is left unchanged.
+func (s *DBService) SaveCommandRun(run *models.CommandRun, maxRuns int) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		startDatetime := run.StartDatetime.Format(time.DateTime)
+		result, err := tx.Exec(
+			`INSERT INTO command_run (
+				command_id, start_datetime, duration_ms, exit_code, status,
+				working_directory, output, output_truncated
+			) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			run.CommandID, startDatetime, run.Duration.Milliseconds(), run.ExitCode, string(run.Status),
+			run.WorkingDirectory, run.Output, run.OutputTruncated,
+		)
+		if err != nil {
+			return err
+		}
+		lastInsertID, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+		run.ID = resource.ID(lastInsertID)
+		_, err = tx.Exec(
+			`UPDATE command SET last_run_datetime = ?, last_run_status = ? WHERE id = ?`,
+			startDatetime, string(run.Status), run.CommandID,
+		)
 		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`DELETE FROM command_run WHERE command_id = ? AND id NOT IN (
+				SELECT id FROM command_run WHERE command_id = ? ORDER BY id DESC LIMIT ?
+			)`,
+			run.CommandID, run.CommandID, maxRuns,
+		)
+		return err
+	})
+}
+
+// GetCommandRuns retrieves at most limit runs of a command, most recent first
+func (s *DBService) GetCommandRuns(commandID resource.ID, limit int) ([]*models.CommandRun, error) {
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT id, command_id, start_datetime, duration_ms, exit_code, status,
+			working_directory, output, output_truncated
+		FROM command_run
+		WHERE command_id = ?
+		ORDER BY id DESC
+		LIMIT ?`,
+		commandID, limit,
+	)
+	if err != nil {
+		slog.Error("Error querying command runs", "commandId", commandID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	runs := []*models.CommandRun{}
+	for rows.Next() {
+		run := models.NewCommandRun(0, time.Time{}, 0, -1, models.CommandRunStatusNone)
+		var startDateStr string
+		var durationMs int64
+		err := rows.Scan(
+			&run.ID, &run.CommandID, &startDateStr, &durationMs, &run.ExitCode, &run.Status,
+			&run.WorkingDirectory, &run.Output, &run.OutputTruncated,
+		)
+		if err != nil {
+			slog.Error("Error scanning command run row", "error", err)
 			return nil, err
 		}
-		commands = append(commands, &command)
+		run.StartDatetime, err = time.Parse(time.DateTime, startDateStr)
+		if err != nil {
+			return nil, err
+		}
+		run.Duration = time.Duration(durationMs) * time.Millisecond
+		runs = append(runs, run)
 	}
-	return commands, nil
+	return runs, rows.Err()
 }
 
 // UpdateCommand updates an existing command in the database
@@ -274,11 +817,13 @@ func (s *DBService) UpdateCommand(command *models.Command) error {
 	_, err := s.dbAdapter.GetDB().Exec(`UPDATE command
 		SET title = ?, description = ?, script = ?,
 		status = ?, lint_issues = ?, lint_status = ?,
-		elapsed = ?, modification_datetime = ?
+		elapsed = ?, modification_datetime = ?,
+		risk_level = ?, risk_reason = ?
 		WHERE id = ?`,
 		command.Title, command.Description, command.Script,
 		string(command.Status), command.LintIssues, string(command.LintStatus),
-		command.Elapsed, time.Now().Format(time.DateTime), command.ID,
+		command.Elapsed, time.Now().Format(time.DateTime),
+		string(command.RiskLevel), command.RiskReason, command.ID,
 	)
 	if err != nil {
 		slog.Error("Error updating command in database", "id", command.ID, "error", err)
@@ -288,6 +833,102 @@ func (s *DBService) UpdateCommand(command *models.Command) error {
 	return nil
 }
 
+// SaveCommandRevision records the revision of a command
+func (s *DBService) SaveCommandRevision(revision *models.CommandRevision) error {
+	result, err := s.dbAdapter.GetDB().Exec(
+		`INSERT INTO command_revision (
+			command_id, creation_datetime, title, description, script, tags
+		) VALUES (?, ?, ?, ?, ?, ?)`,
+		revision.CommandID, revision.CreationDatetime.Format(time.DateTime),
+		revision.Title, revision.Description, revision.Script, models.FormatTags(revision.Tags),
+	)
+	if err != nil {
+		slog.Error("Error saving command revision", "commandId", revision.CommandID, "error", err)
+		return err
+	}
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		slog.Error("Error retrieving last insert ID", "error", err)
+		return err
+	}
+	revision.ID = resource.ID(lastInsertID)
+	return nil
+}
+
+// GetCommandRevisions retrieves the revisions of a command, most recent first
+func (s *DBService) GetCommandRevisions(commandID resource.ID) ([]*models.CommandRevision, error) {
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT id, command_id, creation_datetime, title, IFNULL(description, ''), script, tags
+		FROM command_revision
+		WHERE command_id = ?
+		ORDER BY id DESC`,
+		commandID,
+	)
+	if err != nil {
+		slog.Error("Error querying command revisions", "commandId", commandID, "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []*models.CommandRevision{}
+	for rows.Next() {
+		revision := models.CommandRevision{
+			ID:               0,
+			CommandID:        0,
+			CreationDatetime: time.Time{},
+			Title:            "",
+			Description:      "",
+			Script:           "",
+			Tags:             []string{},
+		}
+		var creationDateStr string
+		var tags string
+		err := rows.Scan(
+			&revision.ID, &revision.CommandID, &creationDateStr,
+			&revision.Title, &revision.Description, &revision.Script, &tags,
+		)
+		if err != nil {
+			slog.Error("Error scanning command revision row", "error", err)
+			return nil, err
+		}
+		revision.CreationDatetime, err = time.Parse(time.DateTime, creationDateStr)
+		if err != nil {
+			return nil, err
+		}
+		revision.Tags = models.ParseTags(tags)
+		revisions = append(revisions, &revision)
+	}
+	return revisions, rows.Err()
+}
+
+// SetCommandSuccessor links an obsolete command to the command that replaces it
+func (s *DBService) SetCommandSuccessor(commandID resource.ID, successorID resource.ID) error {
+	_, err := s.dbAdapter.GetDB().Exec(
+		"UPDATE command SET successor_id = ? WHERE id = ?", successorID, commandID,
+	)
+	if err != nil {
+		slog.Error("Error setting command successor", "id", commandID, "successorId", successorID, "error", err)
+	}
+	return err
+}
+
+// GetCommandSuccessorID returns the id of the command replacing the given one,
+// 0 if the command has not been replaced
+func (s *DBService) GetCommandSuccessorID(commandID resource.ID) (resource.ID, error) {
+	var successorID resource.ID
+	row := s.dbAdapter.GetDB().QueryRow(
+		"SELECT IFNULL(successor_id, 0) FROM command WHERE id = ?", commandID,
+	)
+	if err := row.Scan(&successorID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		slog.Error("Error retrieving command successor", "id", commandID, "error", err)
+		return 0, err
+	}
+	return successorID, nil
+}
+
 // GetCommandCountsByStatus retrieves a count of commands grouped by status directly from the database
 func (s *DBService) GetCommandCountsByStatus() (map[models.CommandStatus]int, error) {
 	// Use SQL GROUP BY to count by status directly in the database
@@ -322,3 +963,597 @@ func (s *DBService) GetCommandCountsByStatus() (map[models.CommandStatus]int, er
 
 	return counts, nil
 }
+
+// GetSuggestedCommandCount returns the number of imported commands found at least
+// minOccurrences times in the history files
+func (s *DBService) GetSuggestedCommandCount(minOccurrences int) (int, error) {
+	var count int
+	err := s.dbAdapter.GetDB().QueryRow(
+		`SELECT COUNT(*) FROM command WHERE status = ? AND occurrence_count >= ?`,
+		string(models.CommandStatusImported), minOccurrences,
+	).Scan(&count)
+	if err != nil {
+		slog.Error("Error counting suggested commands", "error", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// relationsBatchSize is the number of commands whose tags or placeholders are loaded by a single query,
+// far below the maximum number of parameters of a sqlite query
+const relationsBatchSize = 500
+
+// loadCommandsRelations retrieves the tags and the placeholder definitions of the given commands
+func (s *DBService) loadCommandsRelations(commands []*models.Command) error {
+	if err := s.loadCommandsTags(commands); err != nil {
+		return err
+	}
+	return s.loadCommandsPlaceholders(commands)
+}
+
+// loadCommandsPlaceholders retrieves the placeholder definitions of the given commands,
+// in one query by batch of relationsBatchSize commands
+func (s *DBService) loadCommandsPlaceholders(commands []*models.Command) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	commandsByID := make(map[resource.ID]*models.Command, len(commands))
+	for _, command := range commands {
+		command.Placeholders = []*models.PlaceholderDefinition{}
+		commandsByID[command.ID] = command
+	}
+	for batch := range slices.Chunk(commands, relationsBatchSize) {
+		if err := s.loadCommandsPlaceholdersBatch(batch, commandsByID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCommandsPlaceholdersBatch adds their placeholder definitions to the commands of the batch
+func (s *DBService) loadCommandsPlaceholdersBatch(
+	batch []*models.Command, commandsByID map[resource.ID]*models.Command,
+) error {
+	placeholders, args := getCommandIDsArgs(batch)
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT command_id, name, description, allowed_values
+		FROM command_placeholder
+		WHERE command_id IN (`+placeholders+`)
+		ORDER BY command_id, position`,
+		args...,
+	)
+	if err != nil {
+		slog.Error("Error querying command placeholders", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commandID resource.ID
+		var allowedValues string
+		definition := models.PlaceholderDefinition{
+			Name:          "",
+			Description:   "",
+			AllowedValues: []string{},
+		}
+		if err := rows.Scan(&commandID, &definition.Name, &definition.Description, &allowedValues); err != nil {
+			slog.Error("Error scanning command placeholder row", "error", err)
+			return err
+		}
+		if allowedValues != "" {
+			definition.AllowedValues = strings.Split(allowedValues, models.PlaceholderValuesSeparator)
+		}
+		if command, ok := commandsByID[commandID]; ok {
+			command.Placeholders = append(command.Placeholders, &definition)
+		}
+	}
+	return rows.Err()
+}
+
+// SetCommandPlaceholders replaces the placeholder definitions of the command by the given ones
+func (s *DBService) SetCommandPlaceholders(commandID resource.ID, definitions []*models.PlaceholderDefinition) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		return setCommandPlaceholders(tx, commandID, definitions)
+	})
+}
+
+func setCommandPlaceholders(tx *sql.Tx, commandID resource.ID, definitions []*models.PlaceholderDefinition) error {
+	if _, err := tx.Exec("DELETE FROM command_placeholder WHERE command_id = ?", commandID); err != nil {
+		return err
+	}
+	for position, definition := range definitions {
+		_, err := tx.Exec(
+			`INSERT OR REPLACE INTO command_placeholder (
+				command_id, name, position, description, allowed_values
+			) VALUES (?, ?, ?, ?, ?)`,
+			commandID, definition.Name, position, definition.Description,
+			strings.Join(definition.AllowedValues, models.PlaceholderValuesSeparator),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPlaceholderValues retrieves the last value entered for each placeholder name
+func (s *DBService) GetPlaceholderValues() (map[string]string, error) {
+	rows, err := s.dbAdapter.GetDB().Query("SELECT name, value FROM placeholder_value")
+	if err != nil {
+		slog.Error("Error querying placeholder values", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := map[string]string{}
+	for rows.Next() {
+		var name, value string
+		if err := rows.Scan(&name, &value); err != nil {
+			slog.Error("Error scanning placeholder value row", "error", err)
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, rows.Err()
+}
+
+// SavePlaceholderValues remembers the value entered for each placeholder name
+func (s *DBService) SavePlaceholderValues(values map[string]string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		for name, value := range values {
+			_, err := tx.Exec(
+				`INSERT INTO placeholder_value (name, value, modification_datetime) VALUES (?, ?, ?)
+				ON CONFLICT(name) DO UPDATE SET
+					value = excluded.value, modification_datetime = excluded.modification_datetime`,
+				name, value, time.Now().Format(time.DateTime),
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// loadCommandsTags retrieves the tags of the given commands,
+// in one query by batch of relationsBatchSize commands
+func (s *DBService) loadCommandsTags(commands []*models.Command) error {
+	if len(commands) == 0 {
+		return nil
+	}
+	commandsByID := make(map[resource.ID]*models.Command, len(commands))
+	for _, command := range commands {
+		command.Tags = []string{}
+		commandsByID[command.ID] = command
+	}
+	for batch := range slices.Chunk(commands, relationsBatchSize) {
+		if err := s.loadCommandsTagsBatch(batch, commandsByID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCommandsTagsBatch adds their tags to the commands of the batch
+func (s *DBService) loadCommandsTagsBatch(
+	batch []*models.Command, commandsByID map[resource.ID]*models.Command,
+) error {
+	placeholders, args := getCommandIDsArgs(batch)
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT cht.command_id, t.title
+		FROM command_has_tag cht
+		JOIN tag t ON t.id = cht.tag_id
+		WHERE cht.command_id IN (`+placeholders+`)
+		ORDER BY t.title`,
+		args...,
+	)
+	if err != nil {
+		slog.Error("Error querying command tags", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commandID resource.ID
+		var title string
+		if err := rows.Scan(&commandID, &title); err != nil {
+			slog.Error("Error scanning command tag row", "error", err)
+			return err
+		}
+		if command, ok := commandsByID[commandID]; ok {
+			command.Tags = append(command.Tags, title)
+		}
+	}
+	return rows.Err()
+}
+
+// GetTags retrieves all the tags with the number of non obsolete commands using them
+func (s *DBService) GetTags() ([]*models.Tag, error) {
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT t.id, t.title, COUNT(c.id)
+		FROM tag t
+		LEFT JOIN command_has_tag cht ON cht.tag_id = t.id
+		LEFT JOIN command c ON c.id = cht.command_id AND c.status != ?
+		GROUP BY t.id, t.title
+		ORDER BY t.title`,
+		string(models.CommandStatusObsolete),
+	)
+	if err != nil {
+		slog.Error("Error querying tags", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*models.Tag{}
+	for rows.Next() {
+		tag := models.Tag{
+			ID:           0,
+			Title:        "",
+			CommandCount: 0,
+		}
+		if err := rows.Scan(&tag.ID, &tag.Title, &tag.CommandCount); err != nil {
+			slog.Error("Error scanning tag row", "error", err)
+			return nil, err
+		}
+		tags = append(tags, &tag)
+	}
+	return tags, rows.Err()
+}
+
+// CreateTag creates the tag if it does not exist yet and returns it
+func (s *DBService) CreateTag(title string) (*models.Tag, error) {
+	_, err := s.dbAdapter.GetDB().Exec(
+		"INSERT OR IGNORE INTO tag (title) VALUES (?)", title,
+	)
+	if err != nil {
+		slog.Error("Error creating tag", "title", title, "error", err)
+		return nil, err
+	}
+	tag := models.Tag{
+		ID:           0,
+		Title:        title,
+		CommandCount: 0,
+	}
+	row := s.dbAdapter.GetDB().QueryRow("SELECT id FROM tag WHERE title = ?", title)
+	if err := row.Scan(&tag.ID); err != nil {
+		slog.Error("Error retrieving tag", "title", title, "error", err)
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// RenameTag changes the title of the tag with the given id
+func (s *DBService) RenameTag(tagID resource.ID, title string) error {
+	_, err := s.dbAdapter.GetDB().Exec(
+		"UPDATE tag SET title = ? WHERE id = ?", title, tagID,
+	)
+	if err != nil {
+		slog.Error("Error renaming tag", "id", tagID, "title", title, "error", err)
+	}
+	return err
+}
+
+// DeleteTag deletes the tag, commands using it are untagged
+func (s *DBService) DeleteTag(tagID resource.ID) error {
+	_, err := s.dbAdapter.GetDB().Exec("DELETE FROM tag WHERE id = ?", tagID)
+	if err != nil {
+		slog.Error("Error deleting tag", "id", tagID, "error", err)
+	}
+	return err
+}
+
+// SetCommandTags replaces the tags of the command by the given ones,
+// missing tags are created
+func (s *DBService) SetCommandTags(commandID resource.ID, tags []string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		if _, err := tx.Exec("DELETE FROM command_has_tag WHERE command_id = ?", commandID); err != nil {
+			return err
+		}
+		return addTagsToCommands(tx, []resource.ID{commandID}, tags)
+	})
+}
+
+// AddTagsToCommands adds the given tags to each command, missing tags are created
+func (s *DBService) AddTagsToCommands(commandIDs []resource.ID, tags []string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		return addTagsToCommands(tx, commandIDs, tags)
+	})
+}
+
+// RemoveTagsFromCommands removes the given tags from each command
+func (s *DBService) RemoveTagsFromCommands(commandIDs []resource.ID, tags []string) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		for _, commandID := range commandIDs {
+			for _, tag := range tags {
+				_, err := tx.Exec(
+					`DELETE FROM command_has_tag
+					WHERE command_id = ? AND tag_id IN (SELECT id FROM tag WHERE title = ?)`,
+					commandID, tag,
+				)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func addTagsToCommands(tx *sql.Tx, commandIDs []resource.ID, tags []string) error {
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO tag (title) VALUES (?)", tag); err != nil {
+			return err
+		}
+		for _, commandID := range commandIDs {
+			_, err := tx.Exec(
+				`INSERT OR IGNORE INTO command_has_tag (command_id, tag_id)
+				SELECT ?, id FROM tag WHERE title = ?`,
+				commandID, tag,
+			)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// getCommandIDsArgs returns the parameters placeholders and the arguments
+// of a "command_id IN (...)" condition selecting the given commands
+func getCommandIDsArgs(commands []*models.Command) (string, []any) {
+	placeholders := make([]string, len(commands))
+	args := make([]any, len(commands))
+	for i, command := range commands {
+		placeholders[i] = "?"
+		args[i] = command.ID
+	}
+	return strings.Join(placeholders, ", "), args
+}
+
+// withTx runs the given function in a transaction, the transaction is
+// committed if the function succeeds, rolled back otherwise
+func (s *DBService) withTx(fn func(tx *sql.Tx) error) error {
+	tx, err := s.dbAdapter.BeginTx()
+	if err != nil {
+		slog.Error("Error starting transaction", "error", err)
+		return err
+	}
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil {
+			slog.Error("Error rolling back transaction", "error", rollbackErr)
+		}
+		slog.Error("Error in transaction", "error", err)
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetFolders retrieves all the folders with the number of non obsolete commands
+// directly stored in each of them, folders are not sorted
+func (s *DBService) GetFolders() ([]*models.Folder, error) {
+	rows, err := s.dbAdapter.GetDB().Query(
+		`SELECT f.id, IFNULL(f.parent_id, 0), f.title, COUNT(c.id)
+		FROM folder f
+		LEFT JOIN command c ON c.folder_id = f.id AND c.status != ?
+		GROUP BY f.id, f.parent_id, f.title`,
+		string(models.CommandStatusObsolete),
+	)
+	if err != nil {
+		slog.Error("Error querying folders", "error", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	folders := []*models.Folder{}
+	for rows.Next() {
+		folder := models.Folder{
+			ID:           0,
+			ParentID:     models.RootFolderID,
+			Title:        "",
+			Path:         "",
+			Depth:        0,
+			CommandCount: 0,
+		}
+		if err := rows.Scan(&folder.ID, &folder.ParentID, &folder.Title, &folder.CommandCount); err != nil {
+			slog.Error("Error scanning folder row", "error", err)
+			return nil, err
+		}
+		folders = append(folders, &folder)
+	}
+	return folders, rows.Err()
+}
+
+// CreateFolder creates a folder under the given parent (RootFolderID for a top level folder)
+func (s *DBService) CreateFolder(title string, parentID resource.ID) (*models.Folder, error) {
+	result, err := s.dbAdapter.GetDB().Exec(
+		"INSERT INTO folder (title, parent_id) VALUES (?, ?)",
+		title, folderIDToNullable(parentID),
+	)
+	if err != nil {
+		slog.Error("Error creating folder", "title", title, "parentId", parentID, "error", err)
+		return nil, err
+	}
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		slog.Error("Error retrieving last insert ID", "error", err)
+		return nil, err
+	}
+	return &models.Folder{
+		ID:           resource.ID(lastInsertID),
+		ParentID:     parentID,
+		Title:        title,
+		Path:         "",
+		Depth:        0,
+		CommandCount: 0,
+	}, nil
+}
+
+// RenameFolder changes the title of the folder with the given id
+func (s *DBService) RenameFolder(folderID resource.ID, title string) error {
+	_, err := s.dbAdapter.GetDB().Exec(
+		"UPDATE folder SET title = ? WHERE id = ?", title, folderID,
+	)
+	if err != nil {
+		slog.Error("Error renaming folder", "id", folderID, "title", title, "error", err)
+	}
+	return err
+}
+
+// MoveFolder changes the parent of the folder with the given id
+func (s *DBService) MoveFolder(folderID resource.ID, parentID resource.ID) error {
+	_, err := s.dbAdapter.GetDB().Exec(
+		"UPDATE folder SET parent_id = ? WHERE id = ?", folderIDToNullable(parentID), folderID,
+	)
+	if err != nil {
+		slog.Error("Error moving folder", "id", folderID, "parentId", parentID, "error", err)
+	}
+	return err
+}
+
+// DeleteFolder deletes the folder and its sub folders.
+// Commands stored in the deleted folders are moved to the parent of the
+// deleted folder as the schema would otherwise delete them in cascade.
+func (s *DBService) DeleteFolder(folderID resource.ID) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`WITH RECURSIVE subtree(id) AS (
+				SELECT id FROM folder WHERE id = ?
+				UNION
+				SELECT f.id FROM folder f JOIN subtree s ON f.parent_id = s.id
+			)
+			UPDATE command
+			SET folder_id = (SELECT parent_id FROM folder WHERE id = ?)
+			WHERE folder_id IN (SELECT id FROM subtree)`,
+			folderID, folderID,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec("DELETE FROM folder WHERE id = ?", folderID)
+		return err
+	})
+}
+
+// MoveCommandsToFolder moves the given commands to the folder
+// (RootFolderID removes them from any folder)
+func (s *DBService) MoveCommandsToFolder(commandIDs []resource.ID, folderID resource.ID) error {
+	return s.withTx(func(tx *sql.Tx) error {
+		for _, commandID := range commandIDs {
+			_, err := tx.Exec(
+				"UPDATE command SET folder_id = ? WHERE id = ?",
+				folderIDToNullable(folderID), commandID,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// folderIDToNullable converts RootFolderID to NULL as folder_id references the folder table
+func folderIDToNullable(folderID resource.ID) sql.NullInt64 {
+	return sql.NullInt64{
+		Int64: int64(folderID),
+		Valid: folderID != models.RootFolderID,
+	}
+}
+
+// historySourceIDToNullable converts NoHistorySourceID to NULL
+// as history_source_id references the history_source table
+func historySourceIDToNullable(historySourceID resource.ID) sql.NullInt64 {
+	return sql.NullInt64{
+		Int64: int64(historySourceID),
+		Valid: historySourceID != models.NoHistorySourceID,
+	}
+}
+
+// GetOrCreateHistorySource returns the history source of the file, it is created if needed,
+// the format of an existing source is updated if it has changed
+func (s *DBService) GetOrCreateHistorySource(format string, path string) (*models.HistorySource, error) {
+	_, err := s.dbAdapter.GetDB().Exec(
+		`INSERT INTO history_source (format, path) VALUES (?, ?)
+		ON CONFLICT(path) DO UPDATE SET format = excluded.format`,
+		format, path,
+	)
+	if err != nil {
+		slog.Error("Error saving history source", "format", format, "path", path, "error", err)
+		return nil, err
+	}
+	source := &models.HistorySource{
+		ID:     0,
+		Format: "",
+		Path:   "",
+	}
+	err = s.dbAdapter.GetDB().QueryRow(
+		"SELECT id, format, path FROM history_source WHERE path = ?", path,
+	).Scan(&source.ID, &source.Format, &source.Path)
+	if err != nil {
+		slog.Error("Error retrieving history source", "path", path, "error", err)
+		return nil, err
+	}
+	return source, nil
+}
+
+// GetHistorySourceByID returns the history source, nil if not found
+func (s *DBService) GetHistorySourceByID(historySourceID resource.ID) (*models.HistorySource, error) {
+	source := &models.HistorySource{
+		ID:     0,
+		Format: "",
+		Path:   "",
+	}
+	err := s.dbAdapter.GetDB().QueryRow(
+		"SELECT id, format, path FROM history_source WHERE id = ?", historySourceID,
+	).Scan(&source.ID, &source.Format, &source.Path)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// GetHistoryCheckpoint returns the ingestion checkpoint of the history source, nil if none
+func (s *DBService) GetHistoryCheckpoint(historySourceID resource.ID) (*models.HistoryCheckpoint, error) {
+	checkpoint := &models.HistoryCheckpoint{
+		HistorySourceID: historySourceID,
+		Inode:           0,
+		Offset:          0,
+		LastLineHash:    "",
+	}
+	var inode int64
+	err := s.dbAdapter.GetDB().QueryRow(
+		"SELECT inode, byte_offset, last_line_hash FROM history_checkpoint WHERE history_source_id = ?",
+		historySourceID,
+	).Scan(&inode, &checkpoint.Offset, &checkpoint.LastLineHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint.Inode = uint64(inode) //nolint:gosec // inode stored as its two's complement
+	return checkpoint, nil
+}
+
+// saveHistoryCheckpoint creates or replaces the ingestion checkpoint of the history source
+func saveHistoryCheckpoint(tx *sql.Tx, checkpoint *models.HistoryCheckpoint) error {
+	_, err := tx.Exec(
+		`INSERT INTO history_checkpoint (history_source_id, inode, byte_offset, last_line_hash)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(history_source_id) DO UPDATE SET
+			inode = excluded.inode,
+			byte_offset = excluded.byte_offset,
+			last_line_hash = excluded.last_line_hash,
+			modification_datetime = datetime('now')`,
+		checkpoint.HistorySourceID,
+		int64(checkpoint.Inode), //nolint:gosec // sqlite integers are signed
+		checkpoint.Offset,
+		checkpoint.LastLineHash,
+	)
+	if err != nil {
+		slog.Error("Error saving history checkpoint", "historySourceID", checkpoint.HistorySourceID, "error", err)
+	}
+	return err
+}
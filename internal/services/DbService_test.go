@@ -0,0 +1,281 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDBService creates a DBService on a temporary database initialized
+// with the application migrations
+func newTestDBService(t *testing.T) *DBService {
+	t.Helper()
+	dbService := NewDBService(
+		filepath.Join(t.TempDir(), "test.db"),
+		os.DirFS(filepath.Join("..", "..", "app", "resources", "migrations")),
+	)
+	require.NoError(t, dbService.Open())
+	t.Cleanup(func() {
+		dbService.Close()
+	})
+	return dbService
+}
+
+func saveTestCommand(t *testing.T, dbService *DBService, script string, tags ...string) *models.Command {
+	t.Helper()
+	cmd := models.NewCommand(script, 0, time.Now())
+	cmd.Title = script
+	cmd.Tags = tags
+	require.NoError(t, dbService.SaveCommand(cmd))
+	return cmd
+}
+
+func TestDBService_CommandTags(t *testing.T) {
+	dbService := newTestDBService(t)
+	cmd1 := saveTestCommand(t, dbService, "docker ps -a", "docker")
+	cmd2 := saveTestCommand(t, dbService, "kubectl get pods")
+
+	t.Run("tags loaded with command", func(t *testing.T) {
+		cmd, err := dbService.GetCommandByID(cmd1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"docker"}, cmd.Tags)
+
+		cmd, err = dbService.GetCommandByID(cmd2.ID)
+		require.NoError(t, err)
+		assert.Empty(t, cmd.Tags)
+	})
+
+	t.Run("add and remove tags on several commands", func(t *testing.T) {
+		ids := []resource.ID{cmd1.ID, cmd2.ID}
+		require.NoError(t, dbService.AddTagsToCommands(ids, []string{"container", "ops"}))
+		require.NoError(t, dbService.RemoveTagsFromCommands(ids, []string{"ops"}))
+
+		commands, err := dbService.GetCommands()
+		require.NoError(t, err)
+		require.Len(t, commands, 2)
+		assert.Equal(t, []string{"container", "docker"}, commands[0].Tags)
+		assert.Equal(t, []string{"container"}, commands[1].Tags)
+	})
+
+	t.Run("set command tags", func(t *testing.T) {
+		require.NoError(t, dbService.SetCommandTags(cmd2.ID, []string{"k8s"}))
+		cmd, err := dbService.GetCommandByID(cmd2.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"k8s"}, cmd.Tags)
+	})
+
+	t.Run("duplicated command keeps tags", func(t *testing.T) {
+		newID, err := dbService.DuplicateCommand(cmd1.ID, models.CommandStatusObsolete)
+		require.NoError(t, err)
+		cmd, err := dbService.GetCommandByID(newID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"container", "docker"}, cmd.Tags)
+	})
+
+	t.Run("tags of more commands than a batch", func(t *testing.T) {
+		commands := make([]*models.Command, 0, relationsBatchSize+1)
+		for i := range relationsBatchSize + 1 {
+			command := models.NewCommand(fmt.Sprintf("echo %d", i), 0, time.Now())
+			command.Tags = []string{fmt.Sprintf("tag-%d", i)}
+			commands = append(commands, command)
+		}
+		require.NoError(t, dbService.SaveCommands(commands))
+
+		loaded, err := dbService.GetCommands()
+		require.NoError(t, err)
+		count := 0
+		for _, command := range loaded {
+			if number, ok := strings.CutPrefix(command.Script, "echo "); ok {
+				count++
+				assert.Equal(t, []string{"tag-" + number}, command.Tags)
+			}
+		}
+		assert.Equal(t, relationsBatchSize+1, count)
+	})
+}
+
+func TestDBService_TagCRUD(t *testing.T) {
+	dbService := newTestDBService(t)
+	cmd := saveTestCommand(t, dbService, "git status", "git")
+
+	tag, err := dbService.CreateTag("vcs")
+	require.NoError(t, err)
+	sameTag, err := dbService.CreateTag("vcs")
+	require.NoError(t, err)
+	assert.Equal(t, tag.ID, sameTag.ID)
+
+	require.NoError(t, dbService.RenameTag(tag.ID, "scm"))
+
+	tags, err := dbService.GetTags()
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	assert.Equal(t, "git", tags[0].Title)
+	assert.Equal(t, 1, tags[0].CommandCount)
+	assert.Equal(t, "scm", tags[1].Title)
+	assert.Equal(t, 0, tags[1].CommandCount)
+
+	require.NoError(t, dbService.DeleteTag(tags[0].ID))
+	loaded, err := dbService.GetCommandByID(cmd.ID)
+	require.NoError(t, err)
+	assert.Empty(t, loaded.Tags)
+}
+
+func TestDBService_Folders(t *testing.T) {
+	dbService := newTestDBService(t)
+	docker, err := dbService.CreateFolder("docker", models.RootFolderID)
+	require.NoError(t, err)
+	compose, err := dbService.CreateFolder("compose", docker.ID)
+	require.NoError(t, err)
+
+	cmd1 := saveTestCommand(t, dbService, "docker ps -a")
+	cmd2 := saveTestCommand(t, dbService, "docker compose up")
+	require.NoError(t, dbService.MoveCommandsToFolder([]resource.ID{cmd1.ID}, docker.ID))
+	require.NoError(t, dbService.MoveCommandsToFolder([]resource.ID{cmd2.ID}, compose.ID))
+
+	t.Run("folders with command counts", func(t *testing.T) {
+		folders, err := dbService.GetFolders()
+		require.NoError(t, err)
+		folders = models.SortFoldersAsTree(folders)
+		require.Len(t, folders, 2)
+		assert.Equal(t, "docker/compose", folders[1].Path)
+		assert.Equal(t, 1, folders[0].CommandCount)
+		assert.Equal(t, 1, folders[1].CommandCount)
+
+		cmd, err := dbService.GetCommandByID(cmd2.ID)
+		require.NoError(t, err)
+		assert.Equal(t, compose.ID, cmd.FolderID)
+	})
+
+	t.Run("duplicated command keeps its folder", func(t *testing.T) {
+		id, err := dbService.DuplicateCommand(cmd2.ID, models.CommandStatusObsolete)
+		require.NoError(t, err)
+		cmd, err := dbService.GetCommandByID(id)
+		require.NoError(t, err)
+		assert.Equal(t, compose.ID, cmd.FolderID)
+	})
+
+	t.Run("deleting a folder keeps its commands", func(t *testing.T) {
+		require.NoError(t, dbService.DeleteFolder(docker.ID))
+		folders, err := dbService.GetFolders()
+		require.NoError(t, err)
+		assert.Empty(t, folders)
+
+		for _, id := range []resource.ID{cmd1.ID, cmd2.ID} {
+			cmd, err := dbService.GetCommandByID(id)
+			require.NoError(t, err)
+			require.NotNil(t, cmd)
+			assert.Equal(t, models.RootFolderID, cmd.FolderID)
+		}
+	})
+}
+
+func TestDBService_SearchCommands(t *testing.T) {
+	dbService := newTestDBService(t)
+	inScript := saveTestCommand(t, dbService, "docker compose up -d")
+	inTitle := saveTestCommand(t, dbService, "ls -al")
+	inTitle.Title = "list docker images"
+	require.NoError(t, dbService.UpdateCommand(inTitle))
+	deleted := saveTestCommand(t, dbService, "docker rm -f old")
+	deleted.Status = models.CommandStatusDeleted
+	require.NoError(t, dbService.UpdateCommand(deleted))
+
+	t.Run("title matches rank first", func(t *testing.T) {
+		results, err := dbService.SearchCommands("dock", models.CommandStatusImported)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, inTitle.ID, results[0].Command.ID)
+		assert.Equal(t, inScript.ID, results[1].Command.ID)
+		assert.Less(t, results[0].Rank, results[1].Rank)
+		assert.Equal(t,
+			models.SearchHighlightStart+"docker"+models.SearchHighlightEnd+" compose up -d",
+			results[1].Snippet,
+		)
+	})
+
+	t.Run("all words are required", func(t *testing.T) {
+		results, err := dbService.SearchCommands("docker comp")
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, inScript.ID, results[0].Command.ID)
+	})
+
+	t.Run("status filter", func(t *testing.T) {
+		results, err := dbService.SearchCommands("old", models.CommandStatusDeleted)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, deleted.ID, results[0].Command.ID)
+	})
+
+	t.Run("fts syntax is escaped", func(t *testing.T) {
+		results, err := dbService.SearchCommands(`"docker OR NOT (`)
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestDBService_RecordCommandsUsage(t *testing.T) {
+	dbService := newTestDBService(t)
+	cmd1 := saveTestCommand(t, dbService, "docker ps -a")
+	cmd2 := saveTestCommand(t, dbService, "kubectl get pods")
+
+	t.Run("never used", func(t *testing.T) {
+		cmd, err := dbService.GetCommandByID(cmd1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, cmd.UseCount)
+		assert.True(t, cmd.LastUsedDatetime.IsZero())
+	})
+
+	t.Run("usage is recorded without changing modification date", func(t *testing.T) {
+		before, err := dbService.GetCommandByID(cmd1.ID)
+		require.NoError(t, err)
+		usedAt := time.Date(2025, 5, 1, 12, 30, 0, 0, time.UTC)
+		require.NoError(t, dbService.RecordCommandsUsage([]resource.ID{cmd1.ID}, usedAt))
+		require.NoError(t, dbService.RecordCommandsUsage([]resource.ID{cmd1.ID, cmd2.ID}, usedAt))
+
+		commands, err := dbService.GetCommands()
+		require.NoError(t, err)
+		require.Len(t, commands, 2)
+		assert.Equal(t, 2, commands[0].UseCount)
+		assert.Equal(t, usedAt, commands[0].LastUsedDatetime)
+		assert.Equal(t, before.ModificationDatetime, commands[0].ModificationDatetime)
+		assert.Equal(t, 1, commands[1].UseCount)
+	})
+
+	t.Run("duplicated command keeps its usage", func(t *testing.T) {
+		newID, err := dbService.DuplicateCommand(cmd1.ID, models.CommandStatusObsolete)
+		require.NoError(t, err)
+		cmd, err := dbService.GetCommandByID(newID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, cmd.UseCount)
+	})
+}
+
+func TestDBService_SaveCommands(t *testing.T) {
+	dbService := newTestDBService(t)
+	saveTestCommand(t, dbService, "git status")
+
+	first := models.NewCommand("docker ps -a", 0, time.Now())
+	second := models.NewCommand("make build", 0, time.Now())
+	second.Tags = []string{"build"}
+	require.NoError(t, dbService.SaveCommands([]*models.Command{first, second}))
+	assert.NotEqual(t, resource.ID(0), first.ID)
+	assert.NotEqual(t, first.ID, second.ID)
+
+	saved, err := dbService.GetCommandByID(second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"build"}, saved.Tags)
+
+	scripts, err := dbService.GetCommandScripts()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]struct{}{"git status": {}, "docker ps -a": {}, "make build": {}}, scripts)
+}
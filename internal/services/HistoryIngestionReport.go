@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
+)
+
+// HistoryIngestionReport gives the progress and the results of the ingestion of the history sources
+type HistoryIngestionReport struct {
+	// Err joins the errors of the sources, it is only set once the ingestion is finished
+	Err error
+	// CurrentSource is the path of the history file being ingested
+	CurrentSource string
+	// SourceCount is the number of history files to ingest
+	SourceCount int
+	// IngestedSourceCount is the number of history files fully ingested
+	IngestedSourceCount int
+	// ParsedCount is the number of commands read from the history files
+	ParsedCount        int
+	ImportedCount      int
+	AlreadyExistsCount int
+	FilteredOutCount   int
+	SkippedCount       int
+	ErrorCount         int
+	// SecretCount is the number of commands read containing secrets (see SecretScanner)
+	SecretCount int
+	Finished    bool
+}
+
+func newHistoryIngestionReport(sourceCount int) *HistoryIngestionReport {
+	return &HistoryIngestionReport{
+		Err:                 nil,
+		CurrentSource:       "",
+		SourceCount:         sourceCount,
+		IngestedSourceCount: 0,
+		ParsedCount:         0,
+		ImportedCount:       0,
+		AlreadyExistsCount:  0,
+		FilteredOutCount:    0,
+		SkippedCount:        0,
+		ErrorCount:          0,
+		SecretCount:         0,
+		Finished:            false,
+	}
+}
+
+// addCommand counts a command read from a history file according to its import status
+func (r *HistoryIngestionReport) addCommand(status processors.CommandImportedStatus) {
+	if status == processors.CommandImportedStatusInProgress {
+		return
+	}
+	r.ParsedCount++
+	switch status {
+	case processors.CommandImportedStatusNew:
+		r.ImportedCount++
+	case processors.CommandImportedStatusAlreadyExists:
+		r.AlreadyExistsCount++
+	case processors.CommandImportedStatusFilteredOut:
+		r.FilteredOutCount++
+	case processors.CommandImportedStatusSkipped:
+		r.SkippedCount++
+	case processors.CommandImportedStatusError:
+		r.ErrorCount++
+	case processors.CommandImportedStatusInProgress:
+		// not a command
+	}
+}
+
+// Progress describes the ingestion in progress
+func (r *HistoryIngestionReport) Progress() string {
+	return fmt.Sprintf(
+		"Importing history %d/%d: %d command(s) read, %d new",
+		min(r.IngestedSourceCount+1, r.SourceCount), r.SourceCount, r.ParsedCount, r.ImportedCount,
+	)
+}
+
+// String summarizes the results of the ingestion
+func (r *HistoryIngestionReport) String() string {
+	var summary strings.Builder
+	fmt.Fprintf(&summary,
+		"History imported from %d file(s): %d new command(s), %d already existing, %d filtered out, %d skipped",
+		r.IngestedSourceCount, r.ImportedCount, r.AlreadyExistsCount, r.FilteredOutCount, r.SkippedCount,
+	)
+	if r.SecretCount > 0 {
+		fmt.Fprintf(&summary, ", %d containing secrets", r.SecretCount)
+	}
+	if r.ErrorCount > 0 {
+		fmt.Fprintf(&summary, ", %d error(s)", r.ErrorCount)
+	}
+	return summary.String()
+}
@@ -1,43 +1,53 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
-	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/search"
 )
 
-const (
-	// MinCommandLength is the minimum length of a command to be ingested
-	MinCommandLength = 6
-)
+// IngestionBatchSize is the number of commands inserted in a single transaction during ingestion
+const IngestionBatchSize = 500
+
+// RecentCommandRunsCount is the number of runs of a command displayed in the command editor
+const RecentCommandRunsCount = 5
 
 type HistoryIngestor interface {
-	// IngestHistoryWithCallback reads the bash history file and ingests it into the database using a callback
-	ParseBashHistory(
-		historyFilePath string, fromTimestamp time.Time,
+	// ParseHistory reads the history file of the source (bash, zsh or fish) from the checkpoint
+	// and calls the callback for each command to ingest it into the database,
+	// the checkpoint following the last command ingested is returned
+	ParseHistory(
+		source *processors.HistorySource, checkpoint *processors.HistoryCheckpoint,
 		callback func(processors.HistoryCommand) (processors.CommandImportedStatus, error),
-	) error
+	) (*processors.HistoryCheckpoint, error)
 }
 
 // CommandCategory defines the categories of commands shown in the UI
 type CommandCategory string
 
 const (
+	// CommandCategoryFrequent represents available commands ranked by frecency
+	CommandCategoryFrequent CommandCategory = "frequent"
 	// CommandCategoryAvailable represents commands that are available for use
 	CommandCategoryAvailable CommandCategory = "available"
 	// CommandCategorySaved represents commands that have been saved
 	CommandCategorySaved CommandCategory = "saved"
 	// CommandCategoryNew represents commands that have been imported but not yet saved
 	CommandCategoryNew CommandCategory = "new"
+	// CommandCategorySuggested represents imported commands run often, suggested to be saved
+	CommandCategorySuggested CommandCategory = "suggested"
 	// CommandCategoryDeleted represents commands that have been marked as deleted
 	CommandCategoryDeleted CommandCategory = "deleted"
 	// CommandCategoryAll represents all commands regardless of status
@@ -45,12 +55,13 @@ const (
 )
 
 type HistoryService struct {
-	ingestor          HistoryIngestor
-	homeDir           string
-	dbService         *DBService
-	lintService       *LintService
-	scriptRegexp      *regexp.Regexp
-	ignoreLinesRegexp []*regexp.Regexp
+	ingestor         HistoryIngestor
+	homeDir          string
+	dbService        *DBService
+	lintService      *LintService
+	ingestionFilter  *IngestionFilter
+	secretScanner    *SecretScanner
+	dangerClassifier *DangerClassifier
 }
 
 func NewHistoryService(
@@ -59,12 +70,13 @@ func NewHistoryService(
 	lintService *LintService,
 ) *HistoryService {
 	return &HistoryService{
-		ingestor:          ingestor,
-		dbService:         dbService,
-		lintService:       lintService,
-		homeDir:           "",
-		scriptRegexp:      nil,
-		ignoreLinesRegexp: nil,
+		ingestor:         ingestor,
+		dbService:        dbService,
+		lintService:      lintService,
+		homeDir:          "",
+		ingestionFilter:  nil,
+		secretScanner:    nil,
+		dangerClassifier: nil,
 	}
 }
 
@@ -90,29 +102,16 @@ func (s *HistoryService) GetCommandCountsByStatus() (map[models.CommandStatus]in
 	return counts, nil
 }
 
-func (s *HistoryService) getScriptRegexp() *regexp.Regexp {
-	if s.scriptRegexp != nil {
-		return s.scriptRegexp
-	}
-	s.scriptRegexp = regexp.MustCompile("[|&;><()\\[\\]{}$*?!+=,`]")
-	return s.scriptRegexp
+// SetIngestionFilter replaces the default rules deciding which history commands are ingested
+func (s *HistoryService) SetIngestionFilter(filter *IngestionFilter) {
+	s.ingestionFilter = filter
 }
 
-func (s *HistoryService) getIgnoreLinesRegexp() []*regexp.Regexp {
-	if s.ignoreLinesRegexp != nil {
-		return s.ignoreLinesRegexp
-	}
-
-	s.ignoreLinesRegexp = []*regexp.Regexp{
-		regexp.MustCompile("^#"),
-		regexp.MustCompile("( --version| --help)"),
-		regexp.MustCompile("^(shutdown|export|kill|ln|man|mc|ls|ll|ps|source|which|command -v|cd|pwd|echo|cat|rm|mv|cp|touch|mkdir|rmdir|chmod|chown|top|killall|grep|find|locate|updatedb|z) "),
-		regexp.MustCompile("^(code|vi|vim|nano|exit|logout|clear|history|alias|unalias|export|unset|set|env|source|bash|sh|zsh) "),
-		regexp.MustCompile(`^(\./[^ ]+|exit|ls|alias|cd)$`),
-		regexp.MustCompile(`^[A-Za-z0-9_]+=[^ ]+$`),
-		regexp.MustCompile(`^\s*$`),
+func (s *HistoryService) getIngestionFilter() *IngestionFilter {
+	if s.ingestionFilter == nil {
+		s.ingestionFilter = MustNewIngestionFilter(DefaultIngestionFilterConfig())
 	}
-	return s.ignoreLinesRegexp
+	return s.ingestionFilter
 }
 
 func (s *HistoryService) Init() error {
@@ -125,99 +124,370 @@ func (s *HistoryService) Init() error {
 	return nil
 }
 
-func (s *HistoryService) getDefaultHistoryFilePath() (string, error) {
-	historyFile := filepath.Join(s.homeDir, ".bash_history")
-	if _, err := os.Stat(historyFile); err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			slog.Error("History file does not exist", "file", historyFile, "error", err)
-			return "", err
-		}
-		if errors.Is(err, os.ErrPermission) {
-			slog.Error("Permission denied to access history file", "file", historyFile, "error", err)
-			return "", err
+// getHistorySources returns the history files of bash, zsh and fish found in the usual locations
+func (s *HistoryService) getHistorySources() []*processors.HistorySource {
+	sources := processors.DiscoverHistorySources(s.homeDir, os.Getenv)
+	for _, source := range sources {
+		slog.Info("History source discovered", "format", source.Format, "file", source.Path)
+	}
+	return sources
+}
+
+// SetSecretScanner replaces the default detection of the secrets of the history commands
+func (s *HistoryService) SetSecretScanner(scanner *SecretScanner) {
+	s.secretScanner = scanner
+}
+
+func (s *HistoryService) getSecretScanner() *SecretScanner {
+	if s.secretScanner == nil {
+		s.secretScanner = MustNewSecretScanner(DefaultSecretScannerConfig())
+	}
+	return s.secretScanner
+}
+
+// SetDangerClassifier replaces the default rules giving the risk level of the commands
+func (s *HistoryService) SetDangerClassifier(classifier *DangerClassifier) {
+	s.dangerClassifier = classifier
+}
+
+func (s *HistoryService) getDangerClassifier() *DangerClassifier {
+	if s.dangerClassifier == nil {
+		s.dangerClassifier = MustNewDangerClassifier(DefaultDangerClassifierConfig())
+	}
+	return s.dangerClassifier
+}
+
+// classifyRisk sets the risk level of the commands from their script
+func (s *HistoryService) classifyRisk(commands ...*models.Command) {
+	classifier := s.getDangerClassifier()
+	for _, command := range commands {
+		classifier.ClassifyCommand(command)
+	}
+}
+
+// UpdateCommandRisks classifies again all the commands with the current danger rules
+// and saves the risk levels which have changed, it returns the number of commands updated
+func (s *HistoryService) UpdateCommandRisks() (int, error) {
+	risks, err := s.dbService.GetCommandRisks()
+	if err != nil {
+		return 0, err
+	}
+	classifier := s.getDangerClassifier()
+	changed := []*models.CommandRisk{}
+	for _, risk := range risks {
+		level, reason := classifier.Classify(risk.Script)
+		if level == risk.Level && reason == risk.Reason {
+			continue
 		}
+		risk.Level, risk.Reason = level, reason
+		changed = append(changed, risk)
 	}
-	return historyFile, nil
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	if err := s.dbService.UpdateCommandRisks(changed); err != nil {
+		slog.Error("Error updating command risks", "count", len(changed), "error", err)
+		return 0, err
+	}
+	slog.Info("Command risks updated", "count", len(changed))
+	return len(changed), nil
 }
 
-func (s *HistoryService) getHistoryFilePath() (string, error) {
-	historyFile := os.Getenv("HISTFILE")
-	if historyFile == "" {
-		historyFile, err := s.getDefaultHistoryFilePath()
-		if err != nil {
-			slog.Error("Error getting default history file path", "error", err)
-			return "", err
+// historyIngestion is the state shared by the ingestion of all the history sources
+type historyIngestion struct {
+	filter        *IngestionFilter
+	secretScanner *SecretScanner
+	// knownScripts is the set of the scripts of the database and of the pending commands
+	knownScripts map[string]struct{}
+	// pendingCommands are the commands to lint and insert in the next batch
+	pendingCommands []*models.Command
+	// pendingOccurrences are the occurrences of the known scripts to record in the next batch, by script
+	pendingOccurrences map[string]*models.CommandOccurrences
+	// checkpoint is the position of the history file following the pending commands and occurrences,
+	// saved with them, nil if it must not be saved
+	checkpoint *models.HistoryCheckpoint
+	// lastSeen is the last occurrence of the scripts of the database, loaded when a history file
+	// is read again from the beginning to count only the occurrences not counted yet
+	lastSeen map[string]time.Time
+	report   *HistoryIngestionReport
+	// onProgress, if not nil, receives a copy of the report at each step of the ingestion
+	onProgress func(HistoryIngestionReport)
+}
+
+// addOccurrence records an occurrence of a known script, its command is not imported again
+func (i *historyIngestion) addOccurrence(script string, seenAt time.Time) {
+	occurrences, ok := i.pendingOccurrences[script]
+	if !ok {
+		occurrences = &models.CommandOccurrences{
+			FirstSeen: time.Time{},
+			LastSeen:  time.Time{},
+			Script:    script,
+			Count:     0,
 		}
-		slog.Info("Using default history file path", "file", historyFile)
-		return historyFile, nil
+		i.pendingOccurrences[script] = occurrences
 	}
-	_, err := os.Stat(historyFile)
-	if errors.Is(err, os.ErrNotExist) {
-		slog.Error("History file does not exist", "file", historyFile, "error", err)
-		return "", err
+	occurrences.Add(seenAt)
+}
+
+// notifyProgress sends a copy of the report to the progress callback
+func (i *historyIngestion) notifyProgress() {
+	if i.onProgress != nil {
+		i.onProgress(*i.report)
+	}
+}
+
+// checkIfCommandShouldBeSaved applies the ingestion filter and the secret scanner to the command,
+// the script of the command is redacted when the secret scanner is configured to do so.
+// The secrets found are returned whatever the status, nil if none.
+func (*HistoryService) checkIfCommandShouldBeSaved(
+	ingestion *historyIngestion, cmd *processors.HistoryCommand,
+) (processors.CommandImportedStatus, *SecretFinding) {
+	finding := ingestion.secretScanner.Scan(cmd.Command)
+	// the secrets are never written in the logs
+	loggedScript := cmd.Command
+	if finding != nil {
+		loggedScript = finding.Redacted
 	}
-	if errors.Is(err, os.ErrPermission) {
-		slog.Error("Permission denied to access history file", "file", historyFile, "error", err)
-		return "", err
+	if status := ingestion.filter.Check(cmd.Command); status != processors.CommandImportedStatusNew {
+		slog.Info("Command rejected by the ingestion filter", "command", loggedScript, "status", status)
+		return status, finding
 	}
-	return historyFile, nil
+	if finding != nil {
+		ingestion.report.SecretCount++
+		switch finding.Action {
+		case SecretActionSkip:
+			slog.Info("Command containing secrets skipped", "command", loggedScript, "secrets", finding.Names)
+			return processors.CommandImportedStatusFilteredOut, finding
+		case SecretActionRedact:
+			cmd.Command = finding.Redacted
+		case SecretActionFlag:
+			// ingested as is, the reason asks to review it
+		}
+	}
+	if _, exists := ingestion.knownScripts[cmd.Command]; exists {
+		slog.Debug("Command already exists in database", "command", loggedScript)
+		return processors.CommandImportedStatusAlreadyExists, finding
+	}
+	return processors.CommandImportedStatusNew, finding
+}
+
+// IngestHistory imports the new commands of all the history sources discovered,
+// an error on a source does not prevent the ingestion of the other ones.
+// The scripts of the database are loaded once, the new commands are linted
+// concurrently and inserted by batches of IngestionBatchSize commands.
+// onProgress, if not nil, is called when a source starts and every IngestionBatchSize commands read.
+// The ingestion stops once ctx is canceled, the commands read so far are saved with their checkpoint.
+// The returned report is never nil, its Err field is the returned error.
+func (s *HistoryService) IngestHistory(
+	ctx context.Context, onProgress func(HistoryIngestionReport),
+) (*HistoryIngestionReport, error) {
+	return s.ingestHistorySources(ctx, s.getHistorySources(), onProgress)
 }
 
-func (s *HistoryService) checkIfCommandShouldBeSaved(cmd processors.HistoryCommand) (processors.CommandImportedStatus, error) {
-	if len(cmd.Command) < MinCommandLength {
-		slog.Info("Command too short, skipping", "command", cmd)
-		return processors.CommandImportedStatusSkipped, nil
+// ingestHistorySources imports the new commands of the given history sources, see IngestHistory
+func (s *HistoryService) ingestHistorySources(
+	ctx context.Context, sources []*processors.HistorySource, onProgress func(HistoryIngestionReport),
+) (*HistoryIngestionReport, error) {
+	ingestion := &historyIngestion{
+		filter:             s.getIngestionFilter(),
+		secretScanner:      s.getSecretScanner(),
+		knownScripts:       nil,
+		pendingCommands:    make([]*models.Command, 0, IngestionBatchSize),
+		pendingOccurrences: make(map[string]*models.CommandOccurrences),
+		checkpoint:         nil,
+		lastSeen:           nil,
+		report:             newHistoryIngestionReport(len(sources)),
+		onProgress:         onProgress,
 	}
-	if !s.getScriptRegexp().MatchString(cmd.Command) &&
-		matchOneOfRegexp(cmd.Command, s.getIgnoreLinesRegexp()) {
-		slog.Info("Command does not match any script or is ignored", "command", cmd)
-		return processors.CommandImportedStatusFilteredOut, nil
+	defer func() {
+		ingestion.report.Finished = true
+		ingestion.report.CurrentSource = ""
+	}()
+	if len(sources) == 0 {
+		slog.Warn("No history file found")
+		return ingestion.report, nil
 	}
 
-	existingCmd, err := s.dbService.GetCommandByScript(cmd.Command)
+	knownScripts, err := s.dbService.GetCommandScripts()
 	if err != nil {
-		slog.Error("Error getting command from database", "command", cmd, "error", err)
-		return processors.CommandImportedStatusError, err
+		ingestion.report.Err = err
+		return ingestion.report, err
 	}
-	if existingCmd != nil {
-		slog.Debug("Command already exists in database", "command", cmd)
-		return processors.CommandImportedStatusAlreadyExists, nil
+	ingestion.knownScripts = knownScripts
+	var errs []error
+	for _, source := range sources {
+		if ctx.Err() != nil {
+			break
+		}
+		ingestion.report.CurrentSource = source.Path
+		ingestion.notifyProgress()
+		err := s.ingestHistorySource(ctx, ingestion, source)
+		if err != nil && !errors.Is(err, ctx.Err()) {
+			slog.Error("Error ingesting history", "format", source.Format, "file", source.Path, "error", err)
+			errs = append(errs, err)
+		}
+		ingestion.report.IngestedSourceCount++
 	}
-	return processors.CommandImportedStatusNew, nil
+	if err := ctx.Err(); err != nil {
+		// the next ingestion resumes from the checkpoints saved
+		errs = append(errs, err)
+	}
+	ingestion.report.Err = errors.Join(errs...)
+	slog.Info("History ingestion finished", "report", ingestion.report.String(), "error", ingestion.report.Err)
+	return ingestion.report, ingestion.report.Err
 }
 
-func (s *HistoryService) IngestHistory() error {
-	historyFilePath, err := s.getHistoryFilePath()
+// ingestHistorySource imports the commands of the history file written since the last ingestion,
+// the checkpoint reached is saved in the same transaction as the last commands read.
+// The file is no longer read once ctx is canceled.
+func (s *HistoryService) ingestHistorySource(
+	ctx context.Context, ingestion *historyIngestion, source *processors.HistorySource,
+) error {
+	historySource, err := s.dbService.GetOrCreateHistorySource(string(source.Format), source.Path)
 	if err != nil {
-		slog.Error("Error getting history file path", "error", err)
 		return err
 	}
+	ingestion.checkpoint = nil
+	ingestion.lastSeen = nil
 
-	if historyFilePath == "" {
-		slog.Warn("No history file path provided")
-		return nil
+	var checkpoint *processors.HistoryCheckpoint
+	savedCheckpoint, err := s.dbService.GetHistoryCheckpoint(historySource.ID)
+	if err != nil {
+		slog.Warn("Error getting history checkpoint, reading the whole file", "file", source.Path, "error", err)
+	} else if savedCheckpoint != nil {
+		checkpoint = &processors.HistoryCheckpoint{
+			LastLineHash: savedCheckpoint.LastLineHash,
+			Inode:        savedCheckpoint.Inode,
+			Offset:       savedCheckpoint.Offset,
+		}
 	}
+	slog.Debug("History checkpoint", "file", source.Path, "checkpoint", checkpoint)
 
-	maxCommandTimestamp, err := s.dbService.GetMaxCommandTimestamp()
-	if err != nil {
-		slog.Debug("Error getting max command timestamp, fallback to 0", "error", err)
-		maxCommandTimestamp = time.Time{}
+	newCheckpoint, err := s.ingestor.ParseHistory(
+		source, checkpoint,
+		func(historyCmd processors.HistoryCommand) (processors.CommandImportedStatus, error) {
+			if err := ctx.Err(); err != nil {
+				return processors.CommandImportedStatusError, err
+			}
+			status, err := s.processCmd(ingestion, historyCmd, historySource.ID)
+			ingestion.report.addCommand(status)
+			if ingestion.report.ParsedCount%IngestionBatchSize == 0 {
+				ingestion.notifyProgress()
+			}
+			return status, err
+		},
+	)
+	// the commands read before an error are kept, the checkpoint returned follows them
+	if newCheckpoint != nil {
+		ingestion.checkpoint = newHistoryCheckpoint(historySource.ID, newCheckpoint)
 	}
-	slog.Debug("Max command timestamp", "timestamp", maxCommandTimestamp)
+	if flushErr := s.flushIngestedCommands(ingestion); flushErr != nil {
+		// the checkpoint has not been saved, the next ingestion reads these commands again
+		ingestion.pendingCommands = make([]*models.Command, 0, IngestionBatchSize)
+		ingestion.pendingOccurrences = make(map[string]*models.CommandOccurrences)
+		return errors.Join(err, flushErr)
+	}
+	return err
+}
 
-	if err := s.ingestor.ParseBashHistory(historyFilePath, maxCommandTimestamp, s.processCmd); err != nil {
-		slog.Error("Error ingesting history", "file", historyFilePath, "error", err)
-		return err
+func newHistoryCheckpoint(
+	historySourceID resource.ID, checkpoint *processors.HistoryCheckpoint,
+) *models.HistoryCheckpoint {
+	return &models.HistoryCheckpoint{
+		HistorySourceID: historySourceID,
+		LastLineHash:    checkpoint.LastLineHash,
+		Inode:           checkpoint.Inode,
+		Offset:          checkpoint.Offset,
 	}
+}
 
-	return nil
+// PreviewIngestion reads the whole history files and classifies their commands with the filter
+// without modifying the database, onCommand is called for each command not already in the database,
+// the secrets of its script being redacted.
+// The returned report is never nil, its Err field is the returned error.
+func (s *HistoryService) PreviewIngestion(
+	filter *IngestionFilter, onCommand func(processors.HistoryCommand, processors.CommandImportedStatus),
+) (*HistoryIngestionReport, error) {
+	sources := s.getHistorySources()
+	report := newHistoryIngestionReport(len(sources))
+	defer func() {
+		report.Finished = true
+	}()
+	knownScripts, err := s.dbService.GetCommandScripts()
+	if err != nil {
+		report.Err = err
+		return report, err
+	}
+	ingestion := &historyIngestion{
+		filter:             filter,
+		secretScanner:      s.getSecretScanner(),
+		knownScripts:       knownScripts,
+		pendingCommands:    nil,
+		pendingOccurrences: nil,
+		checkpoint:         nil,
+		lastSeen:           nil,
+		report:             report,
+		onProgress:         nil,
+	}
+	var errs []error
+	for _, source := range sources {
+		_, err := s.ingestor.ParseHistory(
+			source, nil,
+			func(historyCmd processors.HistoryCommand) (processors.CommandImportedStatus, error) {
+				status, finding := s.checkIfCommandShouldBeSaved(ingestion, &historyCmd)
+				if status == processors.CommandImportedStatusNew {
+					// the next occurrences of the command would not be imported again
+					ingestion.knownScripts[historyCmd.Command] = struct{}{}
+				}
+				if finding != nil {
+					// the secrets are never displayed, whatever the secret action
+					historyCmd.Command = finding.Redacted
+				}
+				report.addCommand(status)
+				if status != processors.CommandImportedStatusAlreadyExists {
+					onCommand(historyCmd, status)
+				}
+				return status, nil
+			},
+		)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		report.IngestedSourceCount++
+	}
+	report.Err = errors.Join(errs...)
+	return report, report.Err
 }
 
-func (s *HistoryService) processCmd(historyCmd processors.HistoryCommand) (processors.CommandImportedStatus, error) {
-	if importStatus, err := s.checkIfCommandShouldBeSaved(historyCmd); err != nil {
+// processCmd adds the command to the pending commands if it is new, or its occurrence to the pending
+// occurrences if it is already known. The pending batch is flushed before, once full, with the
+// checkpoint preceding the command.
+func (s *HistoryService) processCmd(
+	ingestion *historyIngestion, historyCmd processors.HistoryCommand, historySourceID resource.ID,
+) (processors.CommandImportedStatus, error) {
+	ingestion.checkpoint = newHistoryCheckpoint(historySourceID, &historyCmd.Checkpoint)
+	if err := s.flushIngestedCommandsIfFull(ingestion); err != nil {
 		return processors.CommandImportedStatusError, err
-	} else if importStatus != processors.CommandImportedStatusNew {
-		slog.Debug("Command already exists in database or is ignored", "command", historyCmd, "status", importStatus)
+	}
+	if historyCmd.Reread && ingestion.lastSeen == nil {
+		lastSeen, err := s.dbService.GetCommandsLastSeen()
+		if err != nil {
+			return processors.CommandImportedStatusError, err
+		}
+		ingestion.lastSeen = lastSeen
+	}
+	importStatus, finding := s.checkIfCommandShouldBeSaved(ingestion, &historyCmd)
+	if importStatus == processors.CommandImportedStatusAlreadyExists {
+		// the command has been run again, unless the file is read again and
+		// this occurrence may have already been counted by a previous ingestion,
+		// the occurrences without timestamp in the file cannot be told apart
+		if !historyCmd.Reread ||
+			(!historyCmd.SyntheticTimestamp && historyCmd.Timestamp.After(ingestion.lastSeen[historyCmd.Command])) {
+			ingestion.addOccurrence(historyCmd.Command, historyCmd.Timestamp)
+		}
+		return importStatus, nil
+	}
+	if importStatus != processors.CommandImportedStatusNew {
 		return importStatus, nil
 	}
 	cmd := models.NewCommand(
@@ -225,18 +495,78 @@ func (s *HistoryService) processCmd(historyCmd processors.HistoryCommand) (proce
 		historyCmd.Elapsed,
 		historyCmd.Timestamp,
 	)
-
-	s.lintService.LintCommand(cmd)
-	if err := s.dbService.SaveCommand(cmd); err != nil {
-		slog.Error("Error saving command to database", "command", cmd, "error", err)
-		return processors.CommandImportedStatusError, err
+	cmd.HistorySourceID = historySourceID
+	cmd.OccurrenceCount = 1
+	cmd.FirstSeenDatetime = historyCmd.Timestamp
+	cmd.LastSeenDatetime = historyCmd.Timestamp
+	if finding != nil {
+		cmd.SecretReason = finding.Reason()
 	}
-	slog.Info("Command saved successfully", "command", cmd)
+	ingestion.knownScripts[historyCmd.Command] = struct{}{}
+	ingestion.pendingCommands = append(ingestion.pendingCommands, cmd)
 	return processors.CommandImportedStatusNew, nil
 }
 
+// flushIngestedCommandsIfFull flushes the pending commands and occurrences
+// once one of them reaches IngestionBatchSize
+func (s *HistoryService) flushIngestedCommandsIfFull(ingestion *historyIngestion) error {
+	if len(ingestion.pendingCommands) < IngestionBatchSize && len(ingestion.pendingOccurrences) < IngestionBatchSize {
+		return nil
+	}
+	return s.flushIngestedCommands(ingestion)
+}
+
+// flushIngestedCommands lints the pending commands, then inserts them, records the pending occurrences
+// and saves the checkpoint in a single transaction, so the occurrences of the inserted commands are
+// not lost and the next ingestion does not count them again.
+// The pending commands and occurrences are kept if the transaction fails.
+func (s *HistoryService) flushIngestedCommands(ingestion *historyIngestion) error {
+	commands := ingestion.pendingCommands
+	if len(commands) == 0 && len(ingestion.pendingOccurrences) == 0 && ingestion.checkpoint == nil {
+		return nil
+	}
+	s.lintService.LintCommands(commands)
+	s.classifyRisk(commands...)
+	occurrences := make([]*models.CommandOccurrences, 0, len(ingestion.pendingOccurrences))
+	for _, occurrence := range ingestion.pendingOccurrences {
+		occurrences = append(occurrences, occurrence)
+	}
+	if err := s.dbService.SaveIngestedHistory(commands, occurrences, ingestion.checkpoint); err != nil {
+		slog.Error("Error saving ingested commands to database",
+			"count", len(commands), "occurrences", len(occurrences), "error", err)
+		return err
+	}
+	slog.Info("Ingested commands saved successfully", "count", len(commands), "occurrences", len(occurrences))
+	ingestion.pendingCommands = make([]*models.Command, 0, IngestionBatchSize)
+	ingestion.pendingOccurrences = make(map[string]*models.CommandOccurrences)
+	ingestion.checkpoint = nil
+	return nil
+}
+
+// GetHistorySource returns the history file the command has been imported from,
+// nil if the command has not been imported from a history file
+func (s *HistoryService) GetHistorySource(command *models.Command) *models.HistorySource {
+	if command.HistorySourceID == models.NoHistorySourceID {
+		return nil
+	}
+	source, err := s.dbService.GetHistorySourceByID(command.HistorySourceID)
+	if err != nil {
+		slog.Warn("Unable to retrieve history source", "id", command.HistorySourceID, "error", err)
+		return nil
+	}
+	return source
+}
+
 func (s *HistoryService) UpdateCommand(command *models.Command) (newCommand *models.Command, err error) {
 	slog.Debug("Updating command", "id", command.ID, "status", command.Status)
+	if err := ValidateTags(command.Tags); err != nil {
+		return nil, err
+	}
+	previous, err := s.dbService.GetCommandByID(command.ID)
+	if err != nil {
+		slog.Error("Error retrieving command before update", "id", command.ID, "error", err)
+		return nil, err
+	}
 
 	// If it's an IMPORTED command being updated, we need to handle duplication
 	if command.Status == models.CommandStatusImported {
@@ -251,14 +581,87 @@ func (s *HistoryService) UpdateCommand(command *models.Command) (newCommand *mod
 	command.Status = models.CommandStatusSaved
 	// Lint the new command
 	s.lintCommand(command)
+	s.classifyRisk(command)
 	err = s.dbService.UpdateCommand(command)
 	if err != nil {
 		slog.Error("Error updating command in database", "id", command.ID, "error", err)
 		return nil, err
 	}
+	err = s.dbService.SetCommandTags(command.ID, command.Tags)
+	if err != nil {
+		slog.Error("Error updating command tags in database", "id", command.ID, "error", err)
+		return nil, err
+	}
+	err = s.dbService.SetCommandPlaceholders(command.ID, command.Placeholders)
+	if err != nil {
+		slog.Error("Error updating command placeholders in database", "id", command.ID, "error", err)
+		return nil, err
+	}
+	if err := s.recordRevision(previous, command); err != nil {
+		return nil, err
+	}
 	return command, nil
 }
 
+// recordRevision keeps the previous content of the command if it has been changed
+func (s *HistoryService) recordRevision(previous *models.Command, command *models.Command) error {
+	if previous == nil {
+		return nil
+	}
+	revision := models.NewCommandRevision(previous, command.ModificationDatetime)
+	if revision.HasSameContent(command) {
+		return nil
+	}
+	if err := s.dbService.SaveCommandRevision(revision); err != nil {
+		slog.Error("Error saving command revision", "id", command.ID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetCommandRevisions returns the command and its revisions, most recent first.
+// The command replacing an obsolete command is used when there is one,
+// so the history of an edited imported command can be reached from its obsolete copy.
+func (s *HistoryService) GetCommandRevisions(
+	commandID resource.ID,
+) (*models.Command, []*models.CommandRevision, error) {
+	successorID, err := s.dbService.GetCommandSuccessorID(commandID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if successorID != 0 {
+		commandID = successorID
+	}
+	command, err := s.dbService.GetCommandByID(commandID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if command == nil {
+		return nil, nil, &CommandNotFoundError{ID: commandID}
+	}
+	revisions, err := s.dbService.GetCommandRevisions(commandID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return command, revisions, nil
+}
+
+// RevertCommand restores the content of the given revision of the command,
+// the replaced content is recorded as a new revision
+func (s *HistoryService) RevertCommand(commandID resource.ID, revisionID resource.ID) (*models.Command, error) {
+	command, revisions, err := s.GetCommandRevisions(commandID)
+	if err != nil {
+		return nil, err
+	}
+	for _, revision := range revisions {
+		if revision.ID == revisionID {
+			revision.ApplyTo(command)
+			return s.UpdateCommand(command)
+		}
+	}
+	return nil, &RevisionNotFoundError{ID: revisionID, CommandID: command.ID}
+}
+
 func (s *HistoryService) lintCommand(command *models.Command) {
 	if command.Status != models.CommandStatusSaved {
 		slog.Warn("Command is not in a state that can be linted", "id", command.ID, "status", command.Status)
@@ -276,7 +679,7 @@ func (s *HistoryService) duplicateCommandAsObsolete(commandID resource.ID) error
 		return err
 	}
 	slog.Info("Original command duplicated as obsolete", "id", newID, "originalID", commandID)
-	return nil
+	return s.dbService.SetCommandSuccessor(newID, commandID)
 }
 
 func matchOneOfRegexp(line string, regexps []*regexp.Regexp) bool {
@@ -296,6 +699,7 @@ func (s *HistoryService) RestoreCommand(commands []*models.Command) error {
 		cmd.Status = models.CommandStatusSaved
 		cmd.ModificationDatetime = time.Now()
 		s.lintService.LintCommand(cmd)
+		s.classifyRisk(cmd)
 		err := s.dbService.UpdateCommand(cmd)
 		if err != nil {
 			slog.Error("Error restoring command", "id", cmd.ID, "error", err)
@@ -305,6 +709,56 @@ func (s *HistoryService) RestoreCommand(commands []*models.Command) error {
 	return nil
 }
 
+// SaveSuggestedCommands promotes the imported commands into the saved commands,
+// their imported version is kept as obsolete like when they are edited
+func (s *HistoryService) SaveSuggestedCommands(commands []*models.Command) error {
+	for _, cmd := range commands {
+		if cmd.Status != models.CommandStatusImported {
+			return &CommandNotImportedError{ID: cmd.ID, Status: cmd.Status}
+		}
+		if _, err := s.UpdateCommand(cmd); err != nil {
+			slog.Error("Error saving suggested command", "id", cmd.ID, "error", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// CreateCommand validates, lints and saves a new command, it is saved with the SAVED status
+func (s *HistoryService) CreateCommand(command *models.Command) error {
+	if strings.TrimSpace(command.Script) == "" {
+		return &EmptyScriptError{}
+	}
+	if utf8.RuneCountInString(command.Title) > models.CommandTitleMaxLength {
+		return &CommandTitleTooLongError{Title: command.Title}
+	}
+	if err := ValidateTags(command.Tags); err != nil {
+		return err
+	}
+	command.Status = models.CommandStatusSaved
+	s.lintCommand(command)
+	s.classifyRisk(command)
+	if err := s.dbService.SaveCommand(command); err != nil {
+		slog.Error("Error saving new command", "error", err)
+		return err
+	}
+	return nil
+}
+
+// DeleteCommands marks the commands as deleted, they can be restored with RestoreCommand
+func (s *HistoryService) DeleteCommands(commands []*models.Command) error {
+	for _, cmd := range commands {
+		originalStatus := cmd.Status
+		cmd.Status = models.CommandStatusDeleted
+		if err := s.dbService.UpdateCommand(cmd); err != nil {
+			slog.Error("Error marking command as deleted", "id", cmd.ID, "error", err)
+			cmd.Status = originalStatus
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *HistoryService) ComposeCommand(commands []*models.Command) (*models.Command, error) {
 	if len(commands) < 1 {
 		return nil, &ComposeInsufficientCommandsProvidedError{nil}
@@ -315,6 +769,7 @@ func (s *HistoryService) ComposeCommand(commands []*models.Command) (*models.Com
 		time.Now(),
 	)
 	s.lintService.LintCommand(newCommand)
+	s.classifyRisk(newCommand)
 
 	err := s.dbService.SaveCommand(newCommand)
 	return newCommand, err
@@ -330,6 +785,95 @@ func (s *HistoryService) generateComposeCommandScript(commands []*models.Command
 	return script.String()
 }
 
+// GetPlaceholders returns the placeholders of the commands scripts, a placeholder used by
+// several commands is returned once. Placeholders are completed with their definitions
+// and the value previously entered for them.
+func (s *HistoryService) GetPlaceholders(commands []*models.Command) ([]*models.Placeholder, error) {
+	placeholders := []*models.Placeholder{}
+	seen := map[string]bool{}
+	for _, cmd := range commands {
+		cmdPlaceholders := models.ParsePlaceholders(cmd.Script)
+		models.ApplyDefinitions(cmdPlaceholders, cmd.Placeholders)
+		for _, placeholder := range cmdPlaceholders {
+			if seen[placeholder.Name] {
+				continue
+			}
+			seen[placeholder.Name] = true
+			placeholders = append(placeholders, placeholder)
+		}
+	}
+	if len(placeholders) == 0 {
+		return placeholders, nil
+	}
+	values, err := s.dbService.GetPlaceholderValues()
+	if err != nil {
+		slog.Error("Error getting placeholder values", "error", err)
+		return nil, err
+	}
+	for _, placeholder := range placeholders {
+		placeholder.Value = values[placeholder.Name]
+	}
+	return placeholders, nil
+}
+
+// FillPlaceholders returns copies of the commands with their placeholders replaced
+// by the given values, the values are remembered for the next uses of the placeholders
+func (s *HistoryService) FillPlaceholders(
+	commands []*models.Command, values map[string]string,
+) ([]*models.Command, error) {
+	if err := s.dbService.SavePlaceholderValues(values); err != nil {
+		slog.Error("Error saving placeholder values", "error", err)
+		return nil, err
+	}
+	filledCommands := make([]*models.Command, 0, len(commands))
+	for _, cmd := range commands {
+		filledCommand := *cmd
+		filledCommand.Script = models.FillPlaceholders(cmd.Script, values)
+		// the values of the placeholders can make the command more dangerous
+		level, reason := s.getDangerClassifier().Classify(filledCommand.Script)
+		if level.Rank() > filledCommand.RiskLevel.Rank() {
+			filledCommand.RiskLevel, filledCommand.RiskReason = level, reason
+		}
+		filledCommands = append(filledCommands, &filledCommand)
+	}
+	return filledCommands, nil
+}
+
+// RecordCommandsUsage increments the use count of the commands and sets their
+// last used datetime, it is called each time commands are selected for the
+// shell or copied to the clipboard
+func (s *HistoryService) RecordCommandsUsage(commands []*models.Command) error {
+	if len(commands) < 1 {
+		return nil
+	}
+	usedAt := time.Now().Truncate(time.Second)
+	if err := s.dbService.RecordCommandsUsage(getCommandIDs(commands), usedAt); err != nil {
+		slog.Error("Error recording commands usage", "count", len(commands), "error", err)
+		return err
+	}
+	for _, cmd := range commands {
+		cmd.UseCount++
+		cmd.LastUsedDatetime = usedAt
+	}
+	return nil
+}
+
+// RecordCommandRun records the result of a run of a command,
+// only the models.MaxCommandRuns most recent runs of each command are kept
+func (s *HistoryService) RecordCommandRun(run *models.CommandRun) error {
+	if err := s.dbService.SaveCommandRun(run, models.MaxCommandRuns); err != nil {
+		slog.Error("Error recording command run", "commandId", run.CommandID, "error", err)
+		return err
+	}
+	slog.Info("Command run recorded", "commandId", run.CommandID, "status", run.Status, "exitCode", run.ExitCode)
+	return nil
+}
+
+// GetRecentCommandRuns returns the RecentCommandRunsCount most recent runs of the command
+func (s *HistoryService) GetRecentCommandRuns(commandID resource.ID) ([]*models.CommandRun, error) {
+	return s.dbService.GetCommandRuns(commandID, RecentCommandRunsCount)
+}
+
 // CreateCommandsString creates a concatenated string of all commands
 // suitable for copying to clipboard
 func (s *HistoryService) CreateCommandsString(commands []*models.Command) string {
@@ -359,12 +903,22 @@ func (s *HistoryService) GetCommandCountsByCategory() (map[CommandCategory]int,
 	categoryCounts[CommandCategoryAvailable] = statusCounts[models.CommandStatusImported] +
 		statusCounts[models.CommandStatusSaved]
 
+	// Frequent commands are the available commands sorted by frecency
+	categoryCounts[CommandCategoryFrequent] = categoryCounts[CommandCategoryAvailable]
+
 	// Saved commands (showing Saved status)
 	categoryCounts[CommandCategorySaved] = statusCounts[models.CommandStatusSaved]
 
 	// New commands (showing Imported status)
 	categoryCounts[CommandCategoryNew] = statusCounts[models.CommandStatusImported]
 
+	// Suggested commands are the new commands found several times in the history files
+	categoryCounts[CommandCategorySuggested], err = s.dbService.GetSuggestedCommandCount(models.SuggestionMinOccurrences)
+	if err != nil {
+		slog.Error("Error getting suggested command count", "error", err)
+		return nil, err
+	}
+
 	// Deleted commands
 	categoryCounts[CommandCategoryDeleted] = statusCounts[models.CommandStatusDeleted]
 
@@ -376,3 +930,279 @@ func (s *HistoryService) GetCommandCountsByCategory() (map[CommandCategory]int,
 
 	return categoryCounts, nil
 }
+
+// SearchCommands returns the commands matching the filter using the full text index,
+// sorted by relevance. FilterScore and FilterSnippet of the commands are computed
+// from the search results.
+func (s *HistoryService) SearchCommands(filter string, statuses ...models.CommandStatus) ([]*models.Command, error) {
+	results, err := s.dbService.SearchCommands(filter, statuses...)
+	if err != nil {
+		return nil, err
+	}
+	commands := make([]*models.Command, 0, len(results))
+	for _, result := range results {
+		command := result.Command
+		command.FilterScore = search.RankToScore(result.Rank, results[0].Rank)
+		if strings.Contains(result.Snippet, models.SearchHighlightStart) {
+			command.FilterSnippet = result.Snippet
+		}
+		commands = append(commands, command)
+	}
+	return commands, nil
+}
+
+// GetTags returns all the tags sorted by title
+func (s *HistoryService) GetTags() ([]*models.Tag, error) {
+	tags, err := s.dbService.GetTags()
+	if err != nil {
+		slog.Error("Error getting tags", "error", err)
+		return nil, err
+	}
+	return tags, nil
+}
+
+// GetTagTitles returns the titles of all the tags, used for autocompletion
+func (s *HistoryService) GetTagTitles() []string {
+	tags, err := s.GetTags()
+	if err != nil {
+		return []string{}
+	}
+	titles := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		titles = append(titles, tag.Title)
+	}
+	return titles
+}
+
+// CreateTag creates a new tag, the title is normalized (see models.NormalizeTag)
+func (s *HistoryService) CreateTag(title string) (*models.Tag, error) {
+	title = models.NormalizeTag(title)
+	if err := ValidateTags([]string{title}); err != nil {
+		return nil, err
+	}
+	return s.dbService.CreateTag(title)
+}
+
+// RenameTag renames the tag, the title is normalized (see models.NormalizeTag)
+func (s *HistoryService) RenameTag(tagID resource.ID, title string) error {
+	title = models.NormalizeTag(title)
+	if err := ValidateTags([]string{title}); err != nil {
+		return err
+	}
+	return s.dbService.RenameTag(tagID, title)
+}
+
+// DeleteTag deletes the tag and removes it from all the commands
+func (s *HistoryService) DeleteTag(tagID resource.ID) error {
+	return s.dbService.DeleteTag(tagID)
+}
+
+// AddTagsToCommands adds the tags to all the given commands
+func (s *HistoryService) AddTagsToCommands(commands []*models.Command, tags []string) error {
+	if len(commands) < 1 || len(tags) < 1 {
+		return nil
+	}
+	if err := ValidateTags(tags); err != nil {
+		return err
+	}
+	return s.dbService.AddTagsToCommands(getCommandIDs(commands), tags)
+}
+
+// RemoveTagsFromCommands removes the tags from all the given commands
+func (s *HistoryService) RemoveTagsFromCommands(commands []*models.Command, tags []string) error {
+	if len(commands) < 1 || len(tags) < 1 {
+		return nil
+	}
+	return s.dbService.RemoveTagsFromCommands(getCommandIDs(commands), tags)
+}
+
+// ValidateTags checks that the tags respect the database constraints,
+// the lengths are counted in characters like the SQLite length function
+func ValidateTags(tags []string) error {
+	for _, tag := range tags {
+		if tag == "" {
+			return &EmptyTagError{}
+		}
+		if utf8.RuneCountInString(tag) > models.TagMaxLength {
+			return &TagTooLongError{Tag: tag}
+		}
+	}
+	return nil
+}
+
+// GetFolders returns all the folders sorted as a tree (see models.SortFoldersAsTree)
+func (s *HistoryService) GetFolders() ([]*models.Folder, error) {
+	folders, err := s.dbService.GetFolders()
+	if err != nil {
+		slog.Error("Error getting folders", "error", err)
+		return nil, err
+	}
+	return models.SortFoldersAsTree(folders), nil
+}
+
+// GetFolderPaths returns the paths of all the folders, used for autocompletion
+func (s *HistoryService) GetFolderPaths() []string {
+	folders, err := s.GetFolders()
+	if err != nil {
+		return []string{}
+	}
+	paths := make([]string, 0, len(folders))
+	for _, folder := range folders {
+		paths = append(paths, folder.Path)
+	}
+	return paths
+}
+
+// CreateFolderPath creates the missing folders of the given path (eg: "docker/compose")
+// and returns the last folder of the path.
+// An empty path returns nil, meaning the root folder.
+func (s *HistoryService) CreateFolderPath(path string) (*models.Folder, error) {
+	titles := models.SplitFolderPath(path)
+	if err := ValidateFolderTitles(titles); err != nil {
+		return nil, err
+	}
+	folders, err := s.GetFolders()
+	if err != nil {
+		return nil, err
+	}
+	var folder *models.Folder
+	for i, title := range titles {
+		folderPath := strings.Join(titles[:i+1], models.FolderPathSeparator)
+		existing := models.FindFolderByPath(folders, folderPath)
+		if existing != nil {
+			folder = existing
+			continue
+		}
+		parentID := models.RootFolderID
+		if folder != nil {
+			parentID = folder.ID
+		}
+		folder, err = s.dbService.CreateFolder(title, parentID)
+		if err != nil {
+			return nil, err
+		}
+		folder.Path = folderPath
+		folder.Depth = i
+	}
+	return folder, nil
+}
+
+// RenameFolder renames the folder, the title is normalized (see models.NormalizeFolderTitle)
+func (s *HistoryService) RenameFolder(folderID resource.ID, title string) error {
+	title = models.NormalizeFolderTitle(title)
+	if err := ValidateFolderTitles([]string{title}); err != nil {
+		return err
+	}
+	return s.dbService.RenameFolder(folderID, title)
+}
+
+// MoveFolder moves the folder under the given parent (models.RootFolderID for top level),
+// a folder cannot be moved into itself or one of its sub folders
+func (s *HistoryService) MoveFolder(folderID resource.ID, parentID resource.ID) error {
+	folders, err := s.GetFolders()
+	if err != nil {
+		return err
+	}
+	folder := findFolderByID(folders, folderID)
+	if folder == nil {
+		return &FolderNotFoundError{ID: folderID}
+	}
+	if parentID != models.RootFolderID && slices.Contains(models.GetFolderSubtreeIDs(folders, folderID), parentID) {
+		parentPath := ""
+		if parent := findFolderByID(folders, parentID); parent != nil {
+			parentPath = parent.Path
+		}
+		return &FolderCycleError{FolderPath: folder.Path, ParentPath: parentPath}
+	}
+	return s.dbService.MoveFolder(folderID, parentID)
+}
+
+// MoveFolderToPath moves the folder under the folder having the given path,
+// missing folders of the path are created and an empty path moves the folder to the top level
+func (s *HistoryService) MoveFolderToPath(folderID resource.ID, parentPath string) error {
+	folders, err := s.GetFolders()
+	if err != nil {
+		return err
+	}
+	folder := findFolderByID(folders, folderID)
+	if folder == nil {
+		return &FolderNotFoundError{ID: folderID}
+	}
+	parentPath = strings.Join(models.SplitFolderPath(parentPath), models.FolderPathSeparator)
+	if isSameOrSubFolderPath(parentPath, folder.Path) {
+		return &FolderCycleError{FolderPath: folder.Path, ParentPath: parentPath}
+	}
+	parent, err := s.CreateFolderPath(parentPath)
+	if err != nil {
+		return err
+	}
+	return s.MoveFolder(folderID, getFolderID(parent))
+}
+
+// DeleteFolder deletes the folder and its sub folders,
+// their commands are moved to the parent of the deleted folder
+func (s *HistoryService) DeleteFolder(folderID resource.ID) error {
+	return s.dbService.DeleteFolder(folderID)
+}
+
+// MoveCommandsToFolder moves the commands to the folder (models.RootFolderID for no folder)
+func (s *HistoryService) MoveCommandsToFolder(commands []*models.Command, folderID resource.ID) error {
+	if len(commands) < 1 {
+		return nil
+	}
+	return s.dbService.MoveCommandsToFolder(getCommandIDs(commands), folderID)
+}
+
+// MoveCommandsToFolderPath moves the commands to the folder having the given path,
+// missing folders of the path are created and an empty path removes the commands from their folder
+func (s *HistoryService) MoveCommandsToFolderPath(commands []*models.Command, path string) (*models.Folder, error) {
+	folder, err := s.CreateFolderPath(path)
+	if err != nil {
+		return nil, err
+	}
+	return folder, s.MoveCommandsToFolder(commands, getFolderID(folder))
+}
+
+// ValidateFolderTitles checks that the folder titles respect the database constraints
+func ValidateFolderTitles(titles []string) error {
+	for _, title := range titles {
+		if title == "" {
+			return &EmptyFolderTitleError{}
+		}
+		if utf8.RuneCountInString(title) > models.FolderTitleMaxLength {
+			return &FolderTitleTooLongError{Title: title}
+		}
+	}
+	return nil
+}
+
+func findFolderByID(folders []*models.Folder, folderID resource.ID) *models.Folder {
+	for _, folder := range folders {
+		if folder.ID == folderID {
+			return folder
+		}
+	}
+	return nil
+}
+
+// getFolderID returns the id of the folder, RootFolderID if folder is nil
+func getFolderID(folder *models.Folder) resource.ID {
+	if folder == nil {
+		return models.RootFolderID
+	}
+	return folder.ID
+}
+
+func isSameOrSubFolderPath(path string, basePath string) bool {
+	path = strings.ToLower(path)
+	basePath = strings.ToLower(basePath)
+	return path == basePath || strings.HasPrefix(path, basePath+models.FolderPathSeparator)
+}
+
+func getCommandIDs(commands []*models.Command) []resource.ID {
+	ids := make([]resource.ID, 0, len(commands))
+	for _, cmd := range commands {
+		ids = append(ids, cmd.ID)
+	}
+	return ids
+}
@@ -0,0 +1,90 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryService_Folders(t *testing.T) {
+	historyService := NewHistoryService(nil, newTestDBService(t), nil)
+
+	compose, err := historyService.CreateFolderPath("docker / compose")
+	require.NoError(t, err)
+	assert.Equal(t, "docker/compose", compose.Path)
+
+	again, err := historyService.CreateFolderPath("Docker/compose/")
+	require.NoError(t, err)
+	assert.Equal(t, compose.ID, again.ID)
+	assert.Equal(t, []string{"docker", "docker/compose"}, historyService.GetFolderPaths())
+
+	t.Run("invalid titles", func(t *testing.T) {
+		var tooLongErr *FolderTitleTooLongError
+		_, err := historyService.CreateFolderPath("docker/" + strings.Repeat("a", models.FolderTitleMaxLength+1))
+		require.ErrorAs(t, err, &tooLongErr)
+		var emptyErr *EmptyFolderTitleError
+		require.ErrorAs(t, historyService.RenameFolder(compose.ID, "  "), &emptyErr)
+	})
+
+	t.Run("move folder", func(t *testing.T) {
+		docker := models.FindFolderByPath(mustGetFolders(t, historyService), "docker")
+		require.NotNil(t, docker)
+
+		var cycleErr *FolderCycleError
+		require.ErrorAs(t, historyService.MoveFolder(docker.ID, compose.ID), &cycleErr)
+		require.ErrorAs(t, historyService.MoveFolder(docker.ID, docker.ID), &cycleErr)
+
+		require.ErrorAs(t, historyService.MoveFolderToPath(docker.ID, "docker/compose/new"), &cycleErr)
+		assert.Equal(t, []string{"docker", "docker/compose"}, historyService.GetFolderPaths())
+
+		require.NoError(t, historyService.MoveFolder(compose.ID, models.RootFolderID))
+		assert.Equal(t, []string{"compose", "docker"}, historyService.GetFolderPaths())
+
+		require.NoError(t, historyService.MoveFolderToPath(compose.ID, "tools/"))
+		assert.Equal(t, []string{"docker", "tools", "tools/compose"}, historyService.GetFolderPaths())
+	})
+
+	t.Run("move commands to a new folder path", func(t *testing.T) {
+		cmd := models.NewCommand("git status", 0, time.Now())
+		require.NoError(t, historyService.dbService.SaveCommand(cmd))
+
+		folder, err := historyService.MoveCommandsToFolderPath([]*models.Command{cmd}, "git/status")
+		require.NoError(t, err)
+		saved, err := historyService.dbService.GetCommandByID(cmd.ID)
+		require.NoError(t, err)
+		assert.Equal(t, folder.ID, saved.FolderID)
+
+		folder, err = historyService.MoveCommandsToFolderPath([]*models.Command{cmd}, "")
+		require.NoError(t, err)
+		assert.Nil(t, folder)
+		saved, err = historyService.dbService.GetCommandByID(cmd.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RootFolderID, saved.FolderID)
+	})
+}
+
+func mustGetFolders(t *testing.T, historyService *HistoryService) []*models.Folder {
+	t.Helper()
+	folders, err := historyService.GetFolders()
+	require.NoError(t, err)
+	return folders
+}
+
+func TestHistoryService_MultibyteTitles(t *testing.T) {
+	historyService := NewHistoryService(nil, newTestDBService(t), nil)
+
+	// the maximum length is counted in characters like the database constraint
+	title := strings.Repeat("é", models.FolderTitleMaxLength)
+	folder, err := historyService.CreateFolderPath("文档/" + title)
+	require.NoError(t, err)
+	assert.Equal(t, "文档/"+title, folder.Path)
+	require.NoError(t, ValidateTags([]string{strings.Repeat("标", models.TagMaxLength)}))
+	var tooLongErr *TagTooLongError
+	require.ErrorAs(t, ValidateTags([]string{strings.Repeat("标", models.TagMaxLength+1)}), &tooLongErr)
+}
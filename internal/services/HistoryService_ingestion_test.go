@@ -0,0 +1,424 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIngestionHistoryService(t *testing.T) (*HistoryService, *DBService, string) {
+	t.Helper()
+	homeDir := t.TempDir()
+	t.Setenv("HISTFILE", "")
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("XDG_DATA_HOME", "")
+	dbService := newTestDBService(t)
+	historyService := NewHistoryService(processors.NewHistoryIngestor(), dbService, NewLintService())
+	historyService.homeDir = homeDir
+	return historyService, dbService, homeDir
+}
+
+func writeTestHistoryFile(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func ingestTestHistory(t *testing.T, historyService *HistoryService) *HistoryIngestionReport {
+	t.Helper()
+	report, err := historyService.IngestHistory(context.Background(), nil)
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.True(t, report.Finished)
+	return report
+}
+
+func TestHistoryService_IngestHistorySources(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	bashHistory := filepath.Join(homeDir, ".bash_history")
+	zshHistory := filepath.Join(homeDir, ".zsh_history")
+	fishHistory := filepath.Join(homeDir, ".local", "share", "fish", "fish_history")
+	writeTestHistoryFile(t, bashHistory, "docker ps -a | grep web\n")
+	writeTestHistoryFile(t, zshHistory, ": 1712345678:0;kubectl get pods -A | grep -v Running\n")
+	writeTestHistoryFile(t, fishHistory, "- cmd: git log --oneline | head\n  when: 1712345690\n")
+
+	ingestTestHistory(t, historyService)
+
+	for script, expected := range map[string]struct {
+		format string
+		path   string
+	}{
+		"docker ps -a | grep web":               {format: "bash", path: bashHistory},
+		"kubectl get pods -A | grep -v Running": {format: "zsh", path: zshHistory},
+		"git log --oneline | head":              {format: "fish", path: fishHistory},
+	} {
+		command, err := dbService.GetCommandByScript(script)
+		require.NoError(t, err)
+		require.NotNil(t, command, script)
+		assert.Equal(t, models.CommandStatusImported, command.Status)
+		source := historyService.GetHistorySource(command)
+		require.NotNil(t, source, script)
+		assert.Equal(t, expected.format, source.Format)
+		assert.Equal(t, expected.path, source.Path)
+	}
+
+	// a command created in the application has no history source
+	composed := models.NewCommand("echo composed", 0, time.Now())
+	require.NoError(t, dbService.SaveCommand(composed))
+	assert.Nil(t, historyService.GetHistorySource(composed))
+}
+
+func TestHistoryService_IngestHistoryFromCheckpoint(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	bashHistory := filepath.Join(homeDir, ".bash_history")
+	writeTestHistoryFile(t, bashHistory, ": 1712345678:0;docker ps -a | grep web\n")
+	ingestTestHistory(t, historyService)
+
+	// a command created in the application is more recent than the history
+	composed := models.NewCommand("echo composed", 0, time.Now())
+	require.NoError(t, dbService.SaveCommand(composed))
+
+	// an older command appended to the history is still ingested
+	file, err := os.OpenFile(bashHistory, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(": 1712345600:0;kubectl get pods -A\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	ingestTestHistory(t, historyService)
+
+	command, err := dbService.GetCommandByScript("kubectl get pods -A")
+	require.NoError(t, err)
+	require.NotNil(t, command)
+
+	source, err := dbService.GetOrCreateHistorySource("bash", bashHistory)
+	require.NoError(t, err)
+	checkpoint, err := dbService.GetHistoryCheckpoint(source.ID)
+	require.NoError(t, err)
+	require.NotNil(t, checkpoint)
+	info, err := os.Stat(bashHistory)
+	require.NoError(t, err)
+	assert.Equal(t, info.Size(), checkpoint.Offset)
+}
+
+func TestHistoryService_IngestHistoryRewritten(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	bashHistory := filepath.Join(homeDir, ".bash_history")
+	writeTestHistoryFile(t, bashHistory, ""+
+		": 1712345600:0;docker ps -a | grep web\n"+
+		": 1712345700:0;kubectl get pods -A\n"+
+		": 1712345800:0;docker ps -a | grep web\n")
+	ingestTestHistory(t, historyService)
+
+	// the oldest lines are removed by the shell and a new occurrence is appended,
+	// the file is read again from the beginning
+	writeTestHistoryFile(t, bashHistory, ""+
+		": 1712345800:0;docker ps -a | grep web\n"+
+		": 1712345900:0;docker ps -a | grep web\n")
+	report := ingestTestHistory(t, historyService)
+	assert.Equal(t, 2, report.AlreadyExistsCount)
+
+	// only the occurrence more recent than the last one ingested is counted
+	command, err := dbService.GetCommandByScript("docker ps -a | grep web")
+	require.NoError(t, err)
+	require.NotNil(t, command)
+	assert.Equal(t, 3, command.OccurrenceCount)
+	assert.Equal(t, time.Unix(1712345600, 0).UTC(), command.FirstSeenDatetime)
+	assert.Equal(t, time.Unix(1712345900, 0).UTC(), command.LastSeenDatetime)
+	command, err = dbService.GetCommandByScript("kubectl get pods -A")
+	require.NoError(t, err)
+	require.NotNil(t, command)
+	assert.Equal(t, 1, command.OccurrenceCount)
+}
+
+func TestHistoryService_IngestHistoryRewrittenWithoutTimestamps(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	bashHistory := filepath.Join(homeDir, ".bash_history")
+	writeTestHistoryFile(t, bashHistory, ""+
+		"docker ps -a | grep web\n"+
+		"kubectl get pods -A\n"+
+		"docker ps -a | grep web\n")
+	ingestTestHistory(t, historyService)
+
+	// the oldest lines are removed by the shell and a new occurrence is appended,
+	// the file is read again from the beginning
+	writeTestHistoryFile(t, bashHistory, ""+
+		"docker ps -a | grep web\n"+
+		"docker ps -a | grep web\n")
+	report := ingestTestHistory(t, historyService)
+	assert.Equal(t, 2, report.AlreadyExistsCount)
+
+	// without timestamps, the occurrences already counted cannot be told apart from
+	// the new ones, none of them is counted again
+	command, err := dbService.GetCommandByScript("docker ps -a | grep web")
+	require.NoError(t, err)
+	require.NotNil(t, command)
+	assert.Equal(t, 2, command.OccurrenceCount)
+}
+
+func TestHistoryService_IngestHistoryBatches(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	var content strings.Builder
+	for i := range IngestionBatchSize + 10 {
+		fmt.Fprintf(&content, "docker logs web-%d | tail\n", i)
+		// duplicated lines are ingested once
+		fmt.Fprintf(&content, "docker logs web-%d | tail\n", i)
+	}
+	writeTestHistoryFile(t, filepath.Join(homeDir, ".bash_history"), content.String())
+
+	var progressReports []HistoryIngestionReport
+	report, err := historyService.IngestHistory(context.Background(), func(progress HistoryIngestionReport) {
+		progressReports = append(progressReports, progress)
+	})
+	require.NoError(t, err)
+
+	commands, err := dbService.GetCommands(models.CommandStatusImported)
+	require.NoError(t, err)
+	assert.Len(t, commands, IngestionBatchSize+10)
+	for _, command := range commands {
+		assert.Equal(t, 2, command.OccurrenceCount, command.Script)
+	}
+
+	assert.True(t, report.Finished)
+	assert.Equal(t, 1, report.IngestedSourceCount)
+	assert.Equal(t, 2*(IngestionBatchSize+10), report.ParsedCount)
+	assert.Equal(t, IngestionBatchSize+10, report.ImportedCount)
+	assert.Equal(t, IngestionBatchSize+10, report.AlreadyExistsCount)
+	// start of the source and every IngestionBatchSize commands read
+	require.Len(t, progressReports, 3)
+	assert.False(t, progressReports[0].Finished)
+	assert.Equal(t, filepath.Join(homeDir, ".bash_history"), progressReports[0].CurrentSource)
+	assert.Equal(t, IngestionBatchSize, progressReports[1].ParsedCount)
+}
+
+func TestHistoryService_IngestHistoryCanceled(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	var content strings.Builder
+	for i := range IngestionBatchSize + 10 {
+		fmt.Fprintf(&content, "docker logs web-%d | tail\n", i)
+		fmt.Fprintf(&content, "docker logs web-%d | tail\n", i)
+	}
+	writeTestHistoryFile(t, filepath.Join(homeDir, ".bash_history"), content.String())
+
+	// the ingestion is canceled once IngestionBatchSize commands have been read
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	report, err := historyService.IngestHistory(ctx, func(progress HistoryIngestionReport) {
+		if progress.ParsedCount == IngestionBatchSize {
+			cancel()
+		}
+	})
+	require.ErrorIs(t, err, context.Canceled)
+	assert.True(t, report.Finished)
+	assert.Equal(t, IngestionBatchSize, report.ParsedCount)
+	commands, err := dbService.GetCommands(models.CommandStatusImported)
+	require.NoError(t, err)
+	assert.Len(t, commands, IngestionBatchSize/2)
+
+	// the next ingestion resumes from the checkpoint of the commands saved
+	ingestTestHistory(t, historyService)
+	commands, err = dbService.GetCommands(models.CommandStatusImported)
+	require.NoError(t, err)
+	assert.Len(t, commands, IngestionBatchSize+10)
+	for _, command := range commands {
+		assert.Equal(t, 2, command.OccurrenceCount, command.Script)
+	}
+}
+
+func TestHistoryService_PollHistory(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	bashHistory := filepath.Join(homeDir, ".bash_history")
+	writeTestHistoryFile(t, bashHistory, "docker ps -a | grep web\n")
+	watch := newHistoryWatch()
+
+	// the history files are all ingested at the first check
+	report := historyService.pollHistory(context.Background(), watch)
+	require.NotNil(t, report)
+	assert.Equal(t, 1, report.ImportedCount)
+
+	// unchanged history files are not ingested again
+	assert.Nil(t, historyService.pollHistory(context.Background(), watch))
+
+	// only the appended commands are read
+	file, err := os.OpenFile(bashHistory, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString("kubectl get pods -A | grep -v Running\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	report = historyService.pollHistory(context.Background(), watch)
+	require.NotNil(t, report)
+	assert.True(t, report.Finished)
+	assert.Equal(t, 1, report.ParsedCount)
+	assert.Equal(t, 1, report.ImportedCount)
+	command, err := dbService.GetCommandByScript("kubectl get pods -A | grep -v Running")
+	require.NoError(t, err)
+	require.NotNil(t, command)
+	assert.Equal(t, models.CommandStatusImported, command.Status)
+}
+
+func TestHistoryService_PreviewIngestion(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	writeTestHistoryFile(t, filepath.Join(homeDir, ".bash_history"),
+		"docker ps -a | grep web\nls -la | grep foo\nls -la | grep foo\nkubectl get pods\n")
+	require.NoError(t, dbService.SaveCommand(models.NewCommand("kubectl get pods", 0, time.Now())))
+	config := DefaultIngestionFilterConfig()
+	config.DenyPrograms = []string{"ls"}
+
+	previewed := map[string]processors.CommandImportedStatus{}
+	report, err := historyService.PreviewIngestion(
+		MustNewIngestionFilter(config),
+		func(cmd processors.HistoryCommand, status processors.CommandImportedStatus) {
+			previewed[cmd.Command] = status
+		},
+	)
+	require.NoError(t, err)
+	assert.True(t, report.Finished)
+	assert.Equal(t, map[string]processors.CommandImportedStatus{
+		"docker ps -a | grep web": processors.CommandImportedStatusNew,
+		"ls -la | grep foo":       processors.CommandImportedStatusFilteredOut,
+	}, previewed)
+	assert.Equal(t, 1, report.ImportedCount)
+	assert.Equal(t, 2, report.FilteredOutCount)
+	assert.Equal(t, 1, report.AlreadyExistsCount)
+
+	// nothing has been written in the database
+	command, err := dbService.GetCommandByScript("docker ps -a | grep web")
+	require.NoError(t, err)
+	assert.Nil(t, command)
+	source, err := dbService.GetOrCreateHistorySource("bash", filepath.Join(homeDir, ".bash_history"))
+	require.NoError(t, err)
+	checkpoint, err := dbService.GetHistoryCheckpoint(source.ID)
+	require.NoError(t, err)
+	assert.Nil(t, checkpoint)
+}
+
+func TestHistoryService_IngestHistorySecrets(t *testing.T) {
+	history := "mysql -u root -pPassword db < dump.sql\n" +
+		"curl -H 'Authorization: Bearer abc.def' https://api.example.com\n" +
+		"docker ps -a | grep web\n"
+
+	for _, action := range []SecretAction{SecretActionRedact, SecretActionFlag, SecretActionSkip} {
+		t.Run(string(action), func(t *testing.T) {
+			historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+			historyService.SetSecretScanner(MustNewSecretScanner(SecretScannerConfig{
+				Action:   action,
+				Patterns: []SecretPatternConfig{},
+			}))
+			writeTestHistoryFile(t, filepath.Join(homeDir, ".bash_history"), history)
+
+			// the preview never displays the secrets
+			previewed := 0
+			_, err := historyService.PreviewIngestion(historyService.getIngestionFilter(),
+				func(cmd processors.HistoryCommand, _ processors.CommandImportedStatus) {
+					previewed++
+					assert.NotContains(t, cmd.Command, "Password")
+					assert.NotContains(t, cmd.Command, "abc.def")
+				})
+			require.NoError(t, err)
+			assert.Equal(t, 3, previewed)
+
+			report := ingestTestHistory(t, historyService)
+			assert.Equal(t, 2, report.SecretCount)
+
+			commands, err := dbService.GetCommands(models.CommandStatusImported)
+			require.NoError(t, err)
+			scripts := map[string]string{}
+			for _, command := range commands {
+				scripts[command.Script] = command.SecretReason
+			}
+			switch action {
+			case SecretActionRedact:
+				assert.Len(t, scripts, 3)
+				assert.Contains(t, scripts["mysql -u root -p{{mysql_password}} db < dump.sql"], "mysql_password")
+				assert.Contains(t, scripts, "curl -H 'Authorization: Bearer {{authorization}}' https://api.example.com")
+			case SecretActionFlag:
+				assert.Len(t, scripts, 3)
+				assert.Contains(t, scripts["mysql -u root -pPassword db < dump.sql"], "review the command")
+			case SecretActionSkip:
+				assert.Len(t, scripts, 1)
+				assert.Equal(t, 2, report.FilteredOutCount)
+			}
+			assert.Empty(t, scripts["docker ps -a | grep web"])
+		})
+	}
+}
+
+func TestHistoryService_IngestHistoryUnicode(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	scripts := []string{
+		"git commit -m 'café ☕ 修复 🎉' && git push",
+		"tar czf /tmp/文档.tgz ~/文档/项目 | tee log",
+	}
+	writeTestHistoryFile(t, filepath.Join(homeDir, ".bash_history"), strings.Join(scripts, "\n")+"\n")
+
+	report := ingestTestHistory(t, historyService)
+	assert.Equal(t, 2, report.ImportedCount)
+	for _, script := range scripts {
+		command, err := dbService.GetCommandByScript(script)
+		require.NoError(t, err)
+		require.NotNil(t, command, script)
+	}
+}
+
+func TestHistoryService_IngestHistoryOccurrences(t *testing.T) {
+	historyService, dbService, homeDir := newTestIngestionHistoryService(t)
+	zshHistory := filepath.Join(homeDir, ".zsh_history")
+	writeTestHistoryFile(t, zshHistory, strings.Join([]string{
+		": 1712345678:0;docker ps -a | grep web",
+		": 1712345600:0;kubectl get pods -A | grep -v Running",
+		": 1712345700:0;docker ps -a | grep web",
+	}, "\n")+"\n")
+	ingestTestHistory(t, historyService)
+
+	command, err := dbService.GetCommandByScript("docker ps -a | grep web")
+	require.NoError(t, err)
+	require.NotNil(t, command)
+	assert.Equal(t, 2, command.OccurrenceCount)
+	assert.Equal(t, int64(1712345678), command.FirstSeenDatetime.Unix())
+	assert.Equal(t, int64(1712345700), command.LastSeenDatetime.Unix())
+	assert.True(t, command.IsSuggestion())
+
+	single, err := dbService.GetCommandByScript("kubectl get pods -A | grep -v Running")
+	require.NoError(t, err)
+	require.NotNil(t, single)
+	assert.Equal(t, 1, single.OccurrenceCount)
+	assert.False(t, single.IsSuggestion())
+
+	counts, err := historyService.GetCommandCountsByCategory()
+	require.NoError(t, err)
+	assert.Equal(t, 1, counts[CommandCategorySuggested])
+
+	// the saved command keeps counting its occurrences, not its obsolete imported version
+	require.NoError(t, historyService.SaveSuggestedCommands([]*models.Command{command}))
+	file, err := os.OpenFile(zshHistory, os.O_APPEND|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = file.WriteString(": 1712345800:0;docker ps -a | grep web\n")
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	ingestTestHistory(t, historyService)
+
+	saved, err := dbService.GetActiveCommandByScript("docker ps -a | grep web")
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.Equal(t, models.CommandStatusSaved, saved.Status)
+	assert.Equal(t, 3, saved.OccurrenceCount)
+	assert.Equal(t, int64(1712345800), saved.LastSeenDatetime.Unix())
+
+	counts, err = historyService.GetCommandCountsByCategory()
+	require.NoError(t, err)
+	assert.Equal(t, 0, counts[CommandCategorySuggested])
+
+	var notImportedErr *CommandNotImportedError
+	require.ErrorAs(t, historyService.SaveSuggestedCommands([]*models.Command{saved}), &notImportedErr)
+}
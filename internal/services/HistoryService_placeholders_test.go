@@ -0,0 +1,50 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryService_Placeholders(t *testing.T) {
+	dbService := newTestDBService(t)
+	historyService := NewHistoryService(nil, dbService, NewLintService())
+	logs := saveTestCommand(t, dbService, "kubectl -n <namespace> logs {{pod:api}}")
+	ssh := saveTestCommand(t, dbService, "ssh <host> && kubectl -n <namespace> get pods")
+	require.NoError(t, dbService.SetCommandPlaceholders(logs.ID, []*models.PlaceholderDefinition{
+		{Name: "namespace", Description: "kubernetes namespace", AllowedValues: []string{"default", "prod"}},
+	}))
+
+	loaded, err := dbService.GetCommandByID(logs.ID)
+	require.NoError(t, err)
+	require.Len(t, loaded.Placeholders, 1)
+	assert.Equal(t, []string{"default", "prod"}, loaded.Placeholders[0].AllowedValues)
+
+	commands := []*models.Command{loaded, ssh}
+	placeholders, err := historyService.GetPlaceholders(commands)
+	require.NoError(t, err)
+	require.Len(t, placeholders, 3)
+	assert.Equal(t, "namespace", placeholders[0].Name)
+	assert.Equal(t, "default", placeholders[0].GetInitialValue())
+	assert.Equal(t, "api", placeholders[1].GetInitialValue())
+	assert.Equal(t, "host", placeholders[2].Name)
+
+	filled, err := historyService.FillPlaceholders(commands, map[string]string{
+		"namespace": "prod", "pod": "api-1", "host": "bastion",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "kubectl -n prod logs api-1", filled[0].Script)
+	assert.Equal(t, "ssh bastion && kubectl -n prod get pods", filled[1].Script)
+	assert.Equal(t, "kubectl -n <namespace> logs {{pod:api}}", loaded.Script)
+
+	// entered values are remembered
+	placeholders, err = historyService.GetPlaceholders(commands)
+	require.NoError(t, err)
+	assert.Equal(t, "prod", placeholders[0].GetInitialValue())
+	assert.Equal(t, "api-1", placeholders[1].GetInitialValue())
+	assert.Equal(t, "bastion", placeholders[2].GetInitialValue())
+}
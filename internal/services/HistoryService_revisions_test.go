@@ -0,0 +1,76 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryService_Revisions(t *testing.T) {
+	dbService := newTestDBService(t)
+	historyService := NewHistoryService(nil, dbService, NewLintService())
+	imported := saveTestCommand(t, dbService, "docker ps")
+
+	// first save of an imported command keeps an obsolete copy
+	edited, err := dbService.GetCommandByID(imported.ID)
+	require.NoError(t, err)
+	edited.Script = "docker ps -a"
+	edited.Tags = []string{"docker"}
+	_, err = historyService.UpdateCommand(edited)
+	require.NoError(t, err)
+
+	t.Run("previous content recorded", func(t *testing.T) {
+		command, revisions, err := historyService.GetCommandRevisions(imported.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "docker ps -a", command.Script)
+		require.Len(t, revisions, 1)
+		assert.Equal(t, "docker ps", revisions[0].Script)
+		assert.Empty(t, revisions[0].Tags)
+	})
+
+	t.Run("obsolete copy leads to its successor history", func(t *testing.T) {
+		obsolete, err := dbService.GetCommands(models.CommandStatusObsolete)
+		require.NoError(t, err)
+		require.Len(t, obsolete, 1)
+		command, revisions, err := historyService.GetCommandRevisions(obsolete[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, imported.ID, command.ID)
+		assert.Len(t, revisions, 1)
+	})
+
+	t.Run("save without change does not record a revision", func(t *testing.T) {
+		command, err := dbService.GetCommandByID(imported.ID)
+		require.NoError(t, err)
+		_, err = historyService.UpdateCommand(command)
+		require.NoError(t, err)
+		_, revisions, err := historyService.GetCommandRevisions(imported.ID)
+		require.NoError(t, err)
+		assert.Len(t, revisions, 1)
+	})
+
+	t.Run("revert", func(t *testing.T) {
+		_, revisions, err := historyService.GetCommandRevisions(imported.ID)
+		require.NoError(t, err)
+		reverted, err := historyService.RevertCommand(imported.ID, revisions[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, "docker ps", reverted.Script)
+		assert.Empty(t, reverted.Tags)
+
+		command, revisions, err := historyService.GetCommandRevisions(imported.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "docker ps", command.Script)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, "docker ps -a", revisions[0].Script)
+		assert.Equal(t, []string{"docker"}, revisions[0].Tags)
+	})
+
+	t.Run("unknown revision", func(t *testing.T) {
+		var notFoundErr *RevisionNotFoundError
+		_, err := historyService.RevertCommand(imported.ID, 999)
+		require.ErrorAs(t, err, &notFoundErr)
+	})
+}
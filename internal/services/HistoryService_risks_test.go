@@ -0,0 +1,62 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryService_CommandRisks(t *testing.T) {
+	dbService := newTestDBService(t)
+	historyService := NewHistoryService(nil, dbService, nil)
+	// saved without classification, like the commands existing before the danger rules
+	dangerous := saveTestCommand(t, dbService, "kubectl delete namespace {{namespace}}")
+	template := saveTestCommand(t, dbService, "{{tool}} -rf build")
+
+	t.Run("existing commands classified", func(t *testing.T) {
+		count, err := historyService.UpdateCommandRisks()
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		cmd, err := dbService.GetCommandByID(dangerous.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RiskLevelHigh, cmd.RiskLevel)
+		assert.Equal(t, "kubectl_delete", cmd.RiskReason)
+
+		count, err = historyService.UpdateCommandRisks()
+		require.NoError(t, err)
+		assert.Zero(t, count, "unchanged risks are not saved again")
+	})
+
+	t.Run("rules changed", func(t *testing.T) {
+		historyService.SetDangerClassifier(MustNewDangerClassifier(DangerClassifierConfig{
+			Rules: []DangerRuleConfig{{Name: "kubectl_delete", Regexp: "", Level: "none"}},
+		}))
+		count, err := historyService.UpdateCommandRisks()
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		cmd, err := dbService.GetCommandByID(dangerous.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RiskLevelNone, cmd.RiskLevel)
+		assert.Empty(t, cmd.RiskReason)
+		historyService.SetDangerClassifier(nil)
+	})
+
+	t.Run("filled placeholders classified", func(t *testing.T) {
+		cmd, err := dbService.GetCommandByID(template.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.RiskLevelNone, cmd.RiskLevel)
+
+		filled, err := historyService.FillPlaceholders([]*models.Command{cmd}, map[string]string{"tool": "rm"})
+		require.NoError(t, err)
+		require.Len(t, filled, 1)
+		assert.Equal(t, "rm -rf build", filled[0].Script)
+		assert.Equal(t, models.RiskLevelHigh, filled[0].RiskLevel)
+		assert.Equal(t, models.RiskLevelNone, cmd.RiskLevel, "the original command is unchanged")
+	})
+}
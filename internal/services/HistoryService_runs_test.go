@@ -0,0 +1,116 @@
+//go:build sqlite_fts5 || fts5
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptExecutor outputs the script and exits with its length modulo 2
+type scriptExecutor struct{}
+
+func (*scriptExecutor) Execute(_ context.Context, script string, streams task.Streams) (int, error) {
+	_, err := fmt.Fprintln(streams.Stdout, script)
+	return len(script) % 2, err
+}
+
+func TestHistoryService_CommandRuns(t *testing.T) {
+	dbService := newTestDBService(t)
+	historyService := NewHistoryService(nil, dbService, nil)
+	command := saveTestCommand(t, dbService, "make test")
+	start := time.Now().Truncate(time.Second)
+
+	for i := range models.MaxCommandRuns + 2 {
+		run := models.NewCommandRun(
+			command.ID, start.Add(time.Duration(i)*time.Second), 1500*time.Millisecond, i, models.GetExitCodeRunStatus(i),
+		)
+		run.WorkingDirectory = "/tmp"
+		run.SetOutput(fmt.Sprintf("run %d\n", i), false)
+		require.NoError(t, historyService.RecordCommandRun(run))
+		assert.NotZero(t, run.ID)
+	}
+
+	t.Run("recent runs", func(t *testing.T) {
+		runs, err := historyService.GetRecentCommandRuns(command.ID)
+		require.NoError(t, err)
+		require.Len(t, runs, RecentCommandRunsCount)
+		last := runs[0]
+		assert.Equal(t, command.ID, last.CommandID)
+		assert.Equal(t, models.MaxCommandRuns+1, last.ExitCode)
+		assert.Equal(t, models.CommandRunStatusFailure, last.Status)
+		assert.Equal(t, 1500*time.Millisecond, last.Duration)
+		assert.Equal(t, "/tmp", last.WorkingDirectory)
+		assert.Equal(t, fmt.Sprintf("run %d\n", models.MaxCommandRuns+1), last.Output)
+		assert.False(t, last.OutputTruncated)
+	})
+
+	t.Run("oldest runs dropped", func(t *testing.T) {
+		runs, err := dbService.GetCommandRuns(command.ID, models.MaxCommandRuns*2)
+		require.NoError(t, err)
+		require.Len(t, runs, models.MaxCommandRuns)
+		assert.Equal(t, 2, runs[len(runs)-1].ExitCode)
+	})
+
+	t.Run("last run of the command", func(t *testing.T) {
+		reloaded, err := dbService.GetCommandByID(command.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CommandRunStatusFailure, reloaded.LastRunStatus)
+		assert.Equal(t,
+			start.Add((models.MaxCommandRuns+1)*time.Second).Format(time.DateTime),
+			reloaded.LastRunDatetime.Format(time.DateTime),
+		)
+		commands, err := dbService.GetCommands()
+		require.NoError(t, err)
+		require.Len(t, commands, 1)
+		assert.Equal(t, reloaded.LastRunDatetime, commands[0].LastRunDatetime)
+	})
+
+	t.Run("command never run", func(t *testing.T) {
+		other := saveTestCommand(t, dbService, "make lint")
+		reloaded, err := dbService.GetCommandByID(other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.CommandRunStatusNone, reloaded.LastRunStatus)
+		assert.True(t, reloaded.LastRunDatetime.IsZero())
+		runs, err := historyService.GetRecentCommandRuns(other.ID)
+		require.NoError(t, err)
+		assert.Empty(t, runs)
+	})
+}
+
+func TestTaskService_RecordRuns(t *testing.T) {
+	dbService := newTestDBService(t)
+	historyService := NewHistoryService(nil, dbService, nil)
+	success := saveTestCommand(t, dbService, "ls")
+	failure := saveTestCommand(t, dbService, "pwd")
+	taskService := NewTaskService(&scriptExecutor{}, 2, historyService)
+	defer taskService.Stop()
+
+	tasks := taskService.RunCommands([]*models.Command{success, failure})
+	require.Len(t, tasks, 2)
+	require.Eventually(t, func() bool {
+		return taskService.GetRecordedRunCount() == 2
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, task.Exited, tasks[0].Status())
+	assert.False(t, taskService.HasRunningTasks())
+
+	runs, err := historyService.GetRecentCommandRuns(success.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.CommandRunStatusSuccess, runs[0].Status)
+	assert.Equal(t, "ls\n", runs[0].Output)
+	assert.NotEmpty(t, runs[0].WorkingDirectory)
+
+	runs, err = historyService.GetRecentCommandRuns(failure.ID)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.CommandRunStatusFailure, runs[0].Status)
+	assert.Equal(t, 1, runs[0].ExitCode)
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
+)
+
+// HistoryWatchInterval is the delay between two checks of the history files while watching them
+const HistoryWatchInterval = 2 * time.Second
+
+// historyFileState is the state of a history file when it has been checked for the last time
+type historyFileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func (f historyFileState) equal(other historyFileState) bool {
+	return f.size == other.size && f.modTime.Equal(other.modTime)
+}
+
+// historyWatch keeps the state of the watched history files between two checks
+type historyWatch struct {
+	files map[string]historyFileState
+}
+
+func newHistoryWatch() *historyWatch {
+	return &historyWatch{
+		files: make(map[string]historyFileState),
+	}
+}
+
+// WatchHistory checks the history files every interval until the context is canceled,
+// the commands appended to a changed history file since its checkpoint are ingested.
+// onIngested is called after each ingestion having imported new commands or having failed.
+// The history files are all ingested at the first check, so the commands written
+// between a previous ingestion and the start of the watch are not missed.
+func (s *HistoryService) WatchHistory(
+	ctx context.Context, interval time.Duration, onIngested func(*HistoryIngestionReport),
+) {
+	watch := newHistoryWatch()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report := s.pollHistory(ctx, watch)
+			if ctx.Err() != nil {
+				return
+			}
+			if report != nil && (report.ImportedCount > 0 || report.Err != nil) {
+				onIngested(report)
+			}
+		}
+	}
+}
+
+// pollHistory ingests the history sources whose file has changed since the last check,
+// nil is returned if no history file has changed
+func (s *HistoryService) pollHistory(ctx context.Context, watch *historyWatch) *HistoryIngestionReport {
+	var changedSources []*processors.HistorySource
+	seen := make(map[string]struct{})
+	for _, source := range processors.DiscoverHistorySources(s.homeDir, os.Getenv) {
+		info, err := os.Stat(source.Path)
+		if err != nil {
+			slog.Debug("History file not available", "file", source.Path, "error", err)
+			continue
+		}
+		seen[source.Path] = struct{}{}
+		state := historyFileState{modTime: info.ModTime(), size: info.Size()}
+		if previous, ok := watch.files[source.Path]; ok && previous.equal(state) {
+			continue
+		}
+		watch.files[source.Path] = state
+		changedSources = append(changedSources, source)
+	}
+	// a removed history file is ingested again if it is recreated
+	for path := range watch.files {
+		if _, ok := seen[path]; !ok {
+			delete(watch.files, path)
+		}
+	}
+	if len(changedSources) == 0 {
+		return nil
+	}
+
+	slog.Debug("History files changed", "count", len(changedSources))
+	report, err := s.ingestHistorySources(ctx, changedSources, nil)
+	if err != nil && ctx.Err() == nil {
+		slog.Error("Error ingesting watched history", "error", err)
+	}
+	return report
+}
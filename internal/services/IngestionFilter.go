@@ -0,0 +1,150 @@
+package services
+
+import (
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
+)
+
+// DefaultMinCommandLength is the minimum length of a command to be ingested
+// when the configuration does not define it
+const DefaultMinCommandLength = 6
+
+// IngestionFilterConfig defines the rules deciding which history commands are ingested.
+// The rules are applied in this order:
+//   - a command shorter than MinCommandLength is skipped
+//   - a command whose program is in DenyPrograms is filtered out
+//   - a command whose program is in AllowPrograms is ingested
+//   - a command matching one of the Include regexps is ingested
+//   - a command matching one of the Exclude regexps is filtered out
+//   - any other command is ingested
+type IngestionFilterConfig struct {
+	// Include are the regexps of the commands always ingested, by default the script-like commands
+	Include []string `yaml:"include"`
+	// Exclude are the regexps of the commands not ingested unless they match an Include regexp
+	Exclude []string `yaml:"exclude"`
+	// AllowPrograms are the programs whose commands are always ingested
+	AllowPrograms []string `yaml:"allowPrograms"`
+	// DenyPrograms are the programs whose commands are never ingested
+	DenyPrograms     []string `yaml:"denyPrograms"`
+	MinCommandLength int      `yaml:"minCommandLength"`
+}
+
+// DefaultIngestionFilterConfig returns the rules used when no configuration file overrides them
+func DefaultIngestionFilterConfig() IngestionFilterConfig {
+	return IngestionFilterConfig{
+		Include: []string{"[|&;><()\\[\\]{}$*?!+=,`]"},
+		Exclude: []string{
+			"^#",
+			"( --version| --help)",
+			"^(shutdown|export|kill|ln|man|mc|ls|ll|ps|source|which|command -v|cd|pwd|echo|cat|rm|mv|cp|touch|mkdir|rmdir|chmod|chown|top|killall|grep|find|locate|updatedb|z) ",
+			"^(code|vi|vim|nano|exit|logout|clear|history|alias|unalias|export|unset|set|env|source|bash|sh|zsh) ",
+			`^(\./[^ ]+|exit|ls|alias|cd)$`,
+			`^[A-Za-z0-9_]+=[^ ]+$`,
+			`^\s*$`,
+		},
+		AllowPrograms:    []string{},
+		DenyPrograms:     []string{},
+		MinCommandLength: DefaultMinCommandLength,
+	}
+}
+
+// IngestionFilter applies the rules of an IngestionFilterConfig to the history commands
+type IngestionFilter struct {
+	include          []*regexp.Regexp
+	exclude          []*regexp.Regexp
+	allowPrograms    []string
+	denyPrograms     []string
+	minCommandLength int
+}
+
+// NewIngestionFilter compiles the regexps of the configuration
+func NewIngestionFilter(config IngestionFilterConfig) (*IngestionFilter, error) {
+	include, err := compileIngestionRegexps(config.Include)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := compileIngestionRegexps(config.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return &IngestionFilter{
+		include:          include,
+		exclude:          exclude,
+		allowPrograms:    config.AllowPrograms,
+		denyPrograms:     config.DenyPrograms,
+		minCommandLength: config.MinCommandLength,
+	}, nil
+}
+
+// MustNewIngestionFilter is like NewIngestionFilter but panics if a regexp cannot be compiled
+func MustNewIngestionFilter(config IngestionFilterConfig) *IngestionFilter {
+	filter, err := NewIngestionFilter(config)
+	if err != nil {
+		panic(err)
+	}
+	return filter
+}
+
+func compileIngestionRegexps(expressions []string) ([]*regexp.Regexp, error) {
+	regexps := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expression := range expressions {
+		re, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, &InvalidIngestionRegexpError{Regexp: expression, Err: err}
+		}
+		regexps = append(regexps, re)
+	}
+	return regexps, nil
+}
+
+// Check returns CommandImportedStatusNew if the command has to be ingested,
+// CommandImportedStatusSkipped or CommandImportedStatusFilteredOut otherwise
+func (f *IngestionFilter) Check(command string) processors.CommandImportedStatus {
+	if utf8.RuneCountInString(command) < f.minCommandLength {
+		return processors.CommandImportedStatusSkipped
+	}
+	program := getCommandProgram(command)
+	switch {
+	case slices.Contains(f.denyPrograms, program):
+		return processors.CommandImportedStatusFilteredOut
+	case slices.Contains(f.allowPrograms, program):
+		return processors.CommandImportedStatusNew
+	case matchOneOfRegexp(command, f.include):
+		return processors.CommandImportedStatusNew
+	case matchOneOfRegexp(command, f.exclude):
+		return processors.CommandImportedStatusFilteredOut
+	default:
+		return processors.CommandImportedStatusNew
+	}
+}
+
+// getCommandProgram returns the name of the program run by the command,
+// the variable assignments and sudo preceding it are ignored
+func getCommandProgram(command string) string {
+	for _, word := range strings.Fields(command) {
+		if word == "sudo" || isVariableAssignment(word) {
+			continue
+		}
+		return filepath.Base(word)
+	}
+	return ""
+}
+
+func isVariableAssignment(word string) bool {
+	name, _, found := strings.Cut(word, "=")
+	if !found || name == "" {
+		return false
+	}
+	for i, char := range name {
+		isLetter := (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z') || char == '_'
+		if !isLetter && (i == 0 || char < '0' || char > '9') {
+			return false
+		}
+	}
+	return true
+}
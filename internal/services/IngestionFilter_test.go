@@ -0,0 +1,112 @@
+package services
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/processors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngestionFilter_Check(t *testing.T) {
+	defaultFilter := MustNewIngestionFilter(DefaultIngestionFilterConfig())
+	config := DefaultIngestionFilterConfig()
+	config.AllowPrograms = []string{"kubectl", "ls"}
+	config.DenyPrograms = []string{"grep", "docker"}
+	config.MinCommandLength = 3
+	customFilter := MustNewIngestionFilter(config)
+
+	tests := []struct {
+		name     string
+		filter   *IngestionFilter
+		command  string
+		expected processors.CommandImportedStatus
+	}{
+		{"TooShort", defaultFilter, "ls -l", processors.CommandImportedStatusSkipped},
+		{"ScriptLike", defaultFilter, "ls -la | grep foo", processors.CommandImportedStatusNew},
+		{"Excluded", defaultFilter, "cat file.txt", processors.CommandImportedStatusFilteredOut},
+		{"NotExcluded", defaultFilter, "kubectl get pods", processors.CommandImportedStatusNew},
+		{"CustomMinLength", customFilter, "git", processors.CommandImportedStatusNew},
+		{"AllowedProgram", customFilter, "ls -la", processors.CommandImportedStatusNew},
+		{"DeniedProgram", customFilter, "docker ps | grep web", processors.CommandImportedStatusFilteredOut},
+		{"DeniedProgramPath", customFilter, "/usr/bin/grep -r foo .", processors.CommandImportedStatusFilteredOut},
+		{"DeniedProgramSudo", customFilter, "DEBUG=1 sudo docker ps", processors.CommandImportedStatusFilteredOut},
+		{"NotDeniedArgument", customFilter, "git grep foo", processors.CommandImportedStatusNew},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Check(tt.command))
+		})
+	}
+}
+
+func TestNewIngestionFilter_InvalidRegexp(t *testing.T) {
+	config := DefaultIngestionFilterConfig()
+	config.Exclude = []string{"^(unclosed"}
+
+	_, err := NewIngestionFilter(config)
+	var regexpErr *InvalidIngestionRegexpError
+	require.ErrorAs(t, err, &regexpErr)
+	assert.Equal(t, "^(unclosed", regexpErr.Regexp)
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("MissingDefaultFile", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		t.Setenv("HOME", t.TempDir())
+		config, err := LoadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, DefaultConfig(), config)
+	})
+
+	t.Run("MissingFile", func(t *testing.T) {
+		_, err := LoadConfig(filepath.Join(t.TempDir(), "config.yaml"))
+		var configErr *InvalidConfigFileError
+		assert.ErrorAs(t, err, &configErr)
+	})
+
+	t.Run("OverridesDefaults", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "ingestion:\n  minCommandLength: 2\n  exclude: []\n  denyPrograms: [ls]\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		config, err := LoadConfig(path)
+		require.NoError(t, err)
+		assert.Equal(t, 2, config.Ingestion.MinCommandLength)
+		assert.Empty(t, config.Ingestion.Exclude)
+		assert.Equal(t, []string{"ls"}, config.Ingestion.DenyPrograms)
+		// the lists not defined keep their default value
+		assert.Equal(t, DefaultIngestionFilterConfig().Include, config.Ingestion.Include)
+	})
+
+	t.Run("InvalidRegexp", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("ingestion:\n  include: ['[']\n"), 0o600))
+
+		_, err := LoadConfig(path)
+		var regexpErr *InvalidIngestionRegexpError
+		assert.ErrorAs(t, err, &regexpErr)
+	})
+
+	t.Run("InvalidSecretAction", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte("secrets:\n  action: ignore\n"), 0o600))
+
+		_, err := LoadConfig(path)
+		var actionErr *InvalidSecretActionError
+		assert.ErrorAs(t, err, &actionErr)
+	})
+
+	t.Run("InvalidRiskLevel", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		content := "danger:\n  rules:\n    - name: helm\n      regexp: helm\n      level: critical\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		_, err := LoadConfig(path)
+		var levelErr *InvalidRiskLevelError
+		assert.ErrorAs(t, err, &levelErr)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"log/slog"
 	"os/exec"
+	"sync"
 
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/executors"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
@@ -42,10 +43,19 @@ type LintService struct {
 	commandExecutor CommandExecutorInterface
 	lookupExecutor  LookupExecutorInterface
 	shellCheckPath  string
+	// maxTasks is the maximum number of shellcheck processes run concurrently by LintCommands
+	maxTasks int
 }
 
 type LintServiceOption func(*LintService)
 
+// WithMaxTasks sets the maximum number of shellcheck processes run concurrently
+func WithMaxTasks(maxTasks int) LintServiceOption {
+	return func(s *LintService) {
+		s.maxTasks = max(1, maxTasks)
+	}
+}
+
 func getLogMappingForLintStatus(lintStatus models.LintStatus) slog.Level {
 	switch lintStatus {
 	case models.LintStatusWarning:
@@ -71,6 +81,7 @@ func NewLintService(options ...LintServiceOption) *LintService {
 		shellCheckPath:  "",
 		commandExecutor: defaultCommandExecutor,
 		lookupExecutor:  lookupExecutor,
+		maxTasks:        1,
 	}
 	for _, option := range options {
 		option(service)
@@ -168,6 +179,26 @@ func (s *LintService) LintCommand(cmd *models.Command) []ShellCheckIssue {
 	return issues
 }
 
+// LintCommands lints the commands using a pool of at most maxTasks workers
+func (s *LintService) LintCommands(commands []*models.Command) {
+	jobs := make(chan *models.Command)
+	var waitGroup sync.WaitGroup
+	for range min(max(1, s.maxTasks), len(commands)) {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for cmd := range jobs {
+				s.LintCommand(cmd)
+			}
+		}()
+	}
+	for _, cmd := range commands {
+		jobs <- cmd
+	}
+	close(jobs)
+	waitGroup.Wait()
+}
+
 func (*LintService) FormatLintIssuesAsJSON(issues []ShellCheckIssue) string {
 	str, err := json.Marshal(issues)
 	if err != nil {
@@ -3,7 +3,9 @@ package services
 import (
 	"os/exec"
 	"testing"
+	"time"
 
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -67,6 +69,7 @@ func TestNewLintService(t *testing.T) {
 			shellCheckPath:  "",
 			commandExecutor: nil,
 			lookupExecutor:  nil,
+			maxTasks:        1,
 		} // Manually create the state
 		assert.Equal(t, false, service.IsLintingAvailable())
 		_, err := service.LintScript("echo 'hello'")
@@ -149,6 +152,7 @@ func TestLintService_LintScript(t *testing.T) {
 						path: "/fake/path/to/shellcheck",
 						err:  nil,
 					},
+					maxTasks: 1,
 				}
 
 				issues, err := service.LintScript(tc.scriptContent)
@@ -204,6 +208,7 @@ func TestLintService_LintScript(t *testing.T) {
 						path: "/fake/path/to/shellcheck",
 						err:  nil,
 					},
+					maxTasks: 1,
 				}
 
 				issues, err := service.LintScript(tc.scriptContent)
@@ -213,3 +218,27 @@ func TestLintService_LintScript(t *testing.T) {
 		}
 	})
 }
+
+func TestLintService_LintCommands(t *testing.T) {
+	service := NewLintService(
+		WithLookPathExecutor(&MockLookupExecutor{path: "/fake/path/to/shellcheck", err: nil}),
+		WithMaxTasks(4),
+	)
+	service.commandExecutor = &MockCommandExecutor{
+		stdout: `[{"file": "-", "line": 1, "level": "warning", "code": 2086, "message": "Double quote"}]`,
+		stderr: "",
+		err:    nil,
+	}
+	assert.NoError(t, service.Init())
+
+	commands := make([]*models.Command, 0, 10)
+	for range 10 {
+		commands = append(commands, models.NewCommand("echo $var", 0, time.Now()))
+	}
+	service.LintCommands(commands)
+	for _, command := range commands {
+		assert.Equal(t, models.LintStatusWarning, command.LintStatus)
+	}
+	// no command
+	service.LintCommands(nil)
+}
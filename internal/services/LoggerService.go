@@ -17,7 +17,9 @@ const WriteFileMode = 0o644
 type LoggerService struct {
 	logFileHandler  io.WriteCloser
 	dumpFileHandler io.WriteCloser
-	debug           bool
+	// logWriter, if not nil, receives the logs instead of the log files
+	logWriter io.Writer
+	debug     bool
 }
 
 func NewLoggerService(debugMode bool) *LoggerService {
@@ -25,22 +27,37 @@ func NewLoggerService(debugMode bool) *LoggerService {
 		debug:           debugMode,
 		logFileHandler:  nil,
 		dumpFileHandler: nil,
+		logWriter:       nil,
+	}
+}
+
+// NewHeadlessLoggerService creates a LoggerService for the sub commands of the command line,
+// it does not need the log files: the logs are written to stderr in debug mode and discarded otherwise
+func NewHeadlessLoggerService(debugMode bool) *LoggerService {
+	logWriter := io.Discard
+	if debugMode {
+		logWriter = os.Stderr
+	}
+	return &LoggerService{
+		debug:           debugMode,
+		logFileHandler:  nil,
+		dumpFileHandler: nil,
+		logWriter:       logWriter,
 	}
 }
 
 func (s *LoggerService) Init() error {
+	if s.logWriter != nil {
+		s.setLogger(s.logWriter, s.getLevel())
+		return nil
+	}
 	var err error
 	s.logFileHandler, err = openFileInWriteMode("logs/tui.log")
 	if err != nil {
 		return err
 	}
 
-	level := slog.LevelError
-	if s.debug {
-		level = slog.LevelDebug
-	}
-
-	if err := s.initLogger(level); err != nil {
+	if err := s.initLogger(s.getLevel()); err != nil {
 		return err
 	}
 
@@ -144,21 +161,32 @@ func openFileInWriteMode(filePath string) (io.WriteCloser, error) {
 	return file, nil
 }
 
+func (s *LoggerService) getLevel() slog.Level {
+	if s.debug {
+		return slog.LevelDebug
+	}
+	return slog.LevelError
+}
+
 func (s *LoggerService) initLogger(level slog.Level) error {
 	var err error
 	s.logFileHandler, err = openFileInWriteMode("logs/error.log")
 	if err != nil {
 		return err
 	}
+	s.setLogger(s.logFileHandler, level)
+	return nil
+}
+
+func (*LoggerService) setLogger(writer io.Writer, level slog.Level) {
 	slog.SetLogLoggerLevel(level)
 	opts := &slog.HandlerOptions{
 		AddSource:   level == slog.LevelDebug,
 		Level:       level,
 		ReplaceAttr: nil,
 	}
-	handler := slog.NewTextHandler(s.logFileHandler, opts)
+	handler := slog.NewTextHandler(writer, opts)
 
 	logger := slog.New(handler)
 	slog.SetDefault(logger)
-	return nil
 }
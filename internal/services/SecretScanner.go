@@ -0,0 +1,210 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SecretAction is what is done with a history command containing a secret
+type SecretAction string
+
+const (
+	// SecretActionSkip does not ingest the command
+	SecretActionSkip SecretAction = "skip"
+	// SecretActionRedact replaces the secrets by placeholders
+	SecretActionRedact SecretAction = "redact"
+	// SecretActionFlag ingests the command as is with a reason asking to review it
+	SecretActionFlag SecretAction = "flag"
+)
+
+// secretPlaceholderGroup is the name of the regexp group matching the secret itself,
+// the whole match is the secret if the regexp does not define it
+const secretPlaceholderGroup = "secret"
+
+// SecretPatternConfig is a regexp matching a secret, the secret is the group named secret if any.
+// Name is used as placeholder name when the secret is redacted.
+type SecretPatternConfig struct {
+	Name   string `yaml:"name"`
+	Regexp string `yaml:"regexp"`
+}
+
+// SecretScannerConfig defines how the secrets of the history commands are detected and handled
+type SecretScannerConfig struct {
+	Action SecretAction `yaml:"action"`
+	// Patterns are added to the built-in patterns
+	Patterns []SecretPatternConfig `yaml:"patterns"`
+}
+
+// DefaultSecretScannerConfig returns the configuration used when no configuration file overrides it
+func DefaultSecretScannerConfig() SecretScannerConfig {
+	return SecretScannerConfig{
+		Action:   SecretActionRedact,
+		Patterns: []SecretPatternConfig{},
+	}
+}
+
+// builtinSecretPatterns detect the most common tokens, passwords and keys
+func builtinSecretPatterns() []SecretPatternConfig {
+	return []SecretPatternConfig{
+		{Name: "private_key", Regexp: `-----BEGIN [A-Z ]*PRIVATE KEY-----(?s:.*?)(?:-----END [A-Z ]*PRIVATE KEY-----|$)`},
+		{Name: "aws_access_key_id", Regexp: `\b(?P<secret>(?:AKIA|ASIA)[0-9A-Z]{16})\b`},
+		{Name: "github_token", Regexp: `\b(?P<secret>gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`},
+		{Name: "slack_token", Regexp: `\b(?P<secret>xox[abposr]-[A-Za-z0-9-]{10,})`},
+		{Name: "authorization", Regexp: `(?i)\bauthorization:\s*(?:bearer|basic|token)\s+(?P<secret>[^\s'"]+)`},
+		{Name: "url_password", Regexp: `://[^/\s:@]+:(?P<secret>[^/\s@]+)@`},
+		{Name: "mysql_password", Regexp: `\bmysql(?:dump|admin)?\b[^|;&]*?\s-p(?P<secret>[^\s|;&]+)`},
+		{Name: "sshpass_password", Regexp: `\bsshpass\s+-p\s*(?P<secret>[^\s'"]+)`},
+		{
+			Name:   "password",
+			Regexp: `(?i)--(?:password|passwd|pass|token|api-key|secret)(?:=|\s+)(?P<secret>'[^'$]*'|"[^"$]*"|[^\s'"$-][^\s'"]*)`,
+		},
+		{
+			Name: "secret_variable",
+			Regexp: `(?i)\b[A-Z0-9_]*(?:SECRET|PASSWORD|PASSWD|TOKEN|API_?KEY|ACCESS_KEY|PRIVATE_KEY|CREDENTIALS?)[A-Z0-9_]*=` +
+				`(?P<secret>'[^'$]*'|"[^"$]*"|[^\s'"$][^\s'"]*)`,
+		},
+	}
+}
+
+// SecretFinding describes the secrets detected in a command
+type SecretFinding struct {
+	Action SecretAction
+	// Redacted is the script whose secrets are replaced by placeholders
+	Redacted string
+	// Names are the names of the patterns having detected a secret
+	Names []string
+}
+
+// Reason explains why the command has been redacted or flagged, it is displayed in the command editor
+func (f *SecretFinding) Reason() string {
+	names := strings.Join(f.Names, ", ")
+	switch f.Action {
+	case SecretActionRedact:
+		return "secrets replaced by placeholders during history ingestion: " + names
+	case SecretActionFlag:
+		return "possible secrets detected during history ingestion, review the command: " + names
+	case SecretActionSkip:
+		return "command containing secrets not ingested: " + names
+	default:
+		return names
+	}
+}
+
+type secretPattern struct {
+	re    *regexp.Regexp
+	name  string
+	group int
+}
+
+// SecretScanner detects the secrets of the history commands with the built-in and the configured patterns
+type SecretScanner struct {
+	action   SecretAction
+	patterns []secretPattern
+}
+
+// NewSecretScanner compiles the built-in patterns and the patterns of the configuration
+func NewSecretScanner(config SecretScannerConfig) (*SecretScanner, error) {
+	if !slices.Contains([]SecretAction{SecretActionSkip, SecretActionRedact, SecretActionFlag}, config.Action) {
+		return nil, &InvalidSecretActionError{Action: string(config.Action)}
+	}
+	patternConfigs := slices.Concat(builtinSecretPatterns(), config.Patterns)
+	patterns := make([]secretPattern, 0, len(patternConfigs))
+	for _, patternConfig := range patternConfigs {
+		re, err := regexp.Compile(patternConfig.Regexp)
+		if err != nil {
+			return nil, &InvalidSecretPatternRegexpError{
+				Pattern: patternConfig.Name, Regexp: patternConfig.Regexp, Err: err,
+			}
+		}
+		group := re.SubexpIndex(secretPlaceholderGroup)
+		if group < 0 {
+			group = 0
+		}
+		patterns = append(patterns, secretPattern{
+			re:    re,
+			name:  secretPlaceholderName(patternConfig.Name),
+			group: group,
+		})
+	}
+	return &SecretScanner{action: config.Action, patterns: patterns}, nil
+}
+
+// MustNewSecretScanner is like NewSecretScanner but panics if the configuration is invalid
+func MustNewSecretScanner(config SecretScannerConfig) *SecretScanner {
+	scanner, err := NewSecretScanner(config)
+	if err != nil {
+		panic(err)
+	}
+	return scanner
+}
+
+// secretPlaceholderName converts the pattern name to a valid placeholder name
+func secretPlaceholderName(name string) string {
+	var placeholder strings.Builder
+	for i, char := range strings.ToLower(name) {
+		switch {
+		case char >= 'a' && char <= 'z', char == '_':
+			placeholder.WriteRune(char)
+		case i > 0 && ((char >= '0' && char <= '9') || char == '-'):
+			placeholder.WriteRune(char)
+		default:
+			placeholder.WriteRune('_')
+		}
+	}
+	if placeholder.Len() == 0 {
+		return "secret"
+	}
+	return placeholder.String()
+}
+
+type secretMatch struct {
+	name       string
+	start, end int
+}
+
+// Scan returns the secrets detected in the script, nil if there is none
+func (s *SecretScanner) Scan(script string) *SecretFinding {
+	var matches []secretMatch
+	for _, pattern := range s.patterns {
+		for _, indexes := range pattern.re.FindAllStringSubmatchIndex(script, -1) {
+			start, end := indexes[2*pattern.group], indexes[2*pattern.group+1]
+			if start < 0 || start == end {
+				continue
+			}
+			matches = append(matches, secretMatch{name: pattern.name, start: start, end: end})
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(matches, func(a, b secretMatch) int {
+		return a.start - b.start
+	})
+
+	finding := &SecretFinding{Action: s.action, Redacted: "", Names: []string{}}
+	var redacted strings.Builder
+	placeholderCounts := map[string]int{}
+	previousEnd := 0
+	for _, match := range matches {
+		if match.start < previousEnd {
+			// overlapping an already detected secret
+			continue
+		}
+		if !slices.Contains(finding.Names, match.name) {
+			finding.Names = append(finding.Names, match.name)
+		}
+		placeholderCounts[match.name]++
+		placeholder := match.name
+		if count := placeholderCounts[match.name]; count > 1 {
+			placeholder = fmt.Sprintf("%s_%d", match.name, count)
+		}
+		redacted.WriteString(script[previousEnd:match.start])
+		redacted.WriteString("{{" + placeholder + "}}")
+		previousEnd = match.end
+	}
+	redacted.WriteString(script[previousEnd:])
+	finding.Redacted = redacted.String()
+	return finding
+}
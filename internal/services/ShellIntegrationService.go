@@ -2,6 +2,13 @@ package services
 
 import (
 	_ "embed"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 )
 
 //go:embed templates/bash-integration.sh
@@ -10,9 +17,53 @@ var bashIntegrationTemplate string
 //go:embed templates/zsh-integration.zsh
 var zshIntegrationTemplate string
 
+//go:embed templates/shell-functions.sh.tmpl
+var shellFunctionsTemplate string
+
+// shellFunctionsTemplateParsed is the template of the file generated by GenerateFunctions
+//
+//nolint:gochecknoglobals // parsed once
+var shellFunctionsTemplateParsed = template.Must(
+	template.New("shell-functions").
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Parse(shellFunctionsTemplate),
+)
+
+// defaultFunctionName is the prefix of the name of the functions
+// generated for the commands whose title gives no valid name
+const defaultFunctionName = "command"
+
 // ShellIntegrationService provides shell integration scripts
 type ShellIntegrationService struct{}
 
+// shellFunctionsTemplateData is the data given to the shell functions template
+type shellFunctionsTemplateData struct {
+	Shell     ShellType
+	Functions []*shellFunction
+}
+
+// shellFunction is a saved command converted to a shell function
+type shellFunction struct {
+	Name         string
+	Title        string
+	Usage        string
+	Body         string
+	CommentLines []string
+	Parameters   []*shellFunctionParameter
+	// RequiredCount is the number of arguments to provide, the last parameters
+	// having a default value are optional
+	RequiredCount int
+}
+
+// shellFunctionParameter is a placeholder of a command converted to a positional parameter
+type shellFunctionParameter struct {
+	Name          string
+	Description   string
+	DefaultValue  string
+	AllowedValues []string
+	Position      int
+}
+
 // NewShellIntegrationService creates a new instance of ShellIntegrationService
 func NewShellIntegrationService() *ShellIntegrationService {
 	return &ShellIntegrationService{}
@@ -27,3 +78,183 @@ func (s *ShellIntegrationService) GenerateBashIntegration() string {
 func (s *ShellIntegrationService) GenerateZshIntegration() string {
 	return zshIntegrationTemplate
 }
+
+// GenerateFunctions writes a file to source in bash or zsh defining one function per command.
+// The function name is deduced from the command title prefixed by namePrefix,
+// the placeholders of the command become its positional parameters.
+func (s *ShellIntegrationService) GenerateFunctions(
+	writer io.Writer, shellType ShellType, namePrefix string, commands []*models.Command,
+) error {
+	if shellType != ShellTypeZsh {
+		shellType = ShellTypeBash
+	}
+	data := shellFunctionsTemplateData{
+		Shell:     shellType,
+		Functions: make([]*shellFunction, 0, len(commands)),
+	}
+	usedNames := map[string]bool{}
+	for _, command := range commands {
+		name := getUniqueFunctionName(namePrefix+GetFunctionName(command), usedNames)
+		data.Functions = append(data.Functions, newShellFunction(name, command))
+	}
+	return shellFunctionsTemplateParsed.Execute(writer, data)
+}
+
+// GetFunctionName converts the title of the command to a valid shell function name:
+// lower case ascii letters, digits and underscores, command_<id> if the title gives no name
+func GetFunctionName(command *models.Command) string {
+	var name strings.Builder
+	for _, char := range strings.ToLower(command.Title) {
+		switch {
+		case (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9'):
+			name.WriteRune(char)
+		case name.Len() > 0 && !strings.HasSuffix(name.String(), "_"):
+			name.WriteRune('_')
+		}
+	}
+	functionName := strings.TrimSuffix(name.String(), "_")
+	switch {
+	case functionName == "":
+		return fmt.Sprintf("%s_%d", defaultFunctionName, command.ID)
+	case functionName[0] >= '0' && functionName[0] <= '9':
+		return defaultFunctionName + "_" + functionName
+	default:
+		return functionName
+	}
+}
+
+// getUniqueFunctionName suffixes the name by _2, _3, ... if it is already used
+func getUniqueFunctionName(name string, usedNames map[string]bool) string {
+	uniqueName := name
+	for i := 2; usedNames[uniqueName]; i++ {
+		uniqueName = name + "_" + strconv.Itoa(i)
+	}
+	usedNames[uniqueName] = true
+	return uniqueName
+}
+
+func newShellFunction(name string, command *models.Command) *shellFunction {
+	placeholders := models.ParsePlaceholders(command.Script)
+	models.ApplyDefinitions(placeholders, command.Placeholders)
+	function := &shellFunction{
+		Name:          name,
+		Title:         command.Title,
+		Usage:         name,
+		Body:          "",
+		CommentLines:  []string{},
+		Parameters:    make([]*shellFunctionParameter, 0, len(placeholders)),
+		RequiredCount: 0,
+	}
+	if function.Title == "" {
+		function.Title = fmt.Sprintf("Command #%d", command.ID)
+	}
+	if description := strings.TrimSpace(command.Description); description != "" {
+		function.CommentLines = strings.Split(description, "\n")
+	}
+	expansions := make(map[string]string, len(placeholders))
+	for i, placeholder := range placeholders {
+		position := i + 1
+		expansions[placeholder.Name] = "${" + strconv.Itoa(position) + "}"
+		if placeholder.DefaultValue != "" {
+			expansions[placeholder.Name] = "${" + strconv.Itoa(position) + ":-" +
+				escapeDoubleQuoted(placeholder.DefaultValue) + "}"
+			function.Usage += " [" + placeholder.Name + "]"
+		} else {
+			function.RequiredCount = position
+			function.Usage += " <" + placeholder.Name + ">"
+		}
+		function.Parameters = append(function.Parameters, &shellFunctionParameter{
+			Name:          placeholder.Name,
+			Description:   placeholder.Description,
+			DefaultValue:  placeholder.DefaultValue,
+			AllowedValues: placeholder.AllowedValues,
+			Position:      position,
+		})
+	}
+	function.Body = strings.TrimRight(fillShellPlaceholders(command.Script, expansions), " \t\n")
+	return function
+}
+
+// shellQuoting is the kind of quotes surrounding a position of a script
+type shellQuoting int
+
+const (
+	shellQuotingNone shellQuoting = iota
+	shellQuotingSingle
+	shellQuotingDouble
+	// shellQuotingANSIC is the $'...' quoting
+	shellQuotingANSIC
+	// shellQuotingComment is a comment, up to the end of the line
+	shellQuotingComment
+)
+
+// fillShellPlaceholders replaces the placeholders of the script by the parameter expansions,
+// double quoted so the values are not split, the single quotes surrounding a placeholder
+// are closed before the expansion and reopened after it
+func fillShellPlaceholders(script string, expansions map[string]string) string {
+	quoting := shellQuotingNone
+	scanned := 0
+	return models.FillPlaceholdersFunc(script, func(name string, start int, end int) (string, bool) {
+		// the placeholder itself is not scanned, its default value may contain quotes
+		quoting = scanShellQuoting(script, scanned, start, quoting)
+		scanned = end
+		expansion, ok := expansions[name]
+		if !ok {
+			return "", false
+		}
+		switch quoting {
+		case shellQuotingSingle:
+			return `'"` + expansion + `"'`, true
+		case shellQuotingANSIC:
+			return `'"` + expansion + `"$'`, true
+		case shellQuotingDouble:
+			return expansion, true
+		default:
+			return `"` + expansion + `"`, true
+		}
+	})
+}
+
+// scanShellQuoting returns the quoting at the end offset of the script, the start offset having the given quoting
+func scanShellQuoting(script string, start int, end int, quoting shellQuoting) shellQuoting {
+	for i := start; i < end; i++ {
+		switch {
+		case quoting == shellQuotingComment:
+			if script[i] == '\n' {
+				quoting = shellQuotingNone
+			}
+		case script[i] == '\\' && quoting != shellQuotingSingle:
+			i++ // escaped character
+		case quoting == shellQuotingNone && strings.HasPrefix(script[i:], "$'"):
+			quoting = shellQuotingANSIC
+			i++
+		case quoting == shellQuotingNone && script[i] == '\'':
+			quoting = shellQuotingSingle
+		case quoting == shellQuotingNone && script[i] == '"':
+			quoting = shellQuotingDouble
+		case quoting == shellQuotingNone && script[i] == '#' && isShellWordStart(script, i):
+			quoting = shellQuotingComment
+		case (quoting == shellQuotingSingle || quoting == shellQuotingANSIC) && script[i] == '\'',
+			quoting == shellQuotingDouble && script[i] == '"':
+			quoting = shellQuotingNone
+		}
+	}
+	return quoting
+}
+
+// isShellWordStart checks if the character at the offset starts a word of the script
+func isShellWordStart(script string, offset int) bool {
+	return offset == 0 || strings.IndexByte(" \t\n;&|(", script[offset-1]) >= 0
+}
+
+// escapeDoubleQuoted escapes the characters interpreted in a double quoted string
+func escapeDoubleQuoted(value string) string {
+	var escaped strings.Builder
+	for _, char := range value {
+		if strings.ContainsRune(`\$"`+"`", char) {
+			escaped.WriteRune('\\')
+		}
+		escaped.WriteRune(char)
+	}
+	return escaped.String()
+}
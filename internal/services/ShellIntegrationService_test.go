@@ -1,9 +1,13 @@
 package services
 
 import (
+	"bytes"
 	"testing"
+	"time"
 
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestShellIntegrationService_GenerateBashIntegration(t *testing.T) {
@@ -31,3 +35,66 @@ func TestShellIntegrationService_GenerateZshIntegration(t *testing.T) {
 	assert.Contains(t, script, "zle -N shell_command_bookmarker_paste")
 	assert.Contains(t, script, "bindkey '^g' shell_command_bookmarker_paste")
 }
+
+func TestGetFunctionName(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{title: "List Docker containers", expected: "list_docker_containers"},
+		{title: "  git: push --force!  ", expected: "git_push_force"},
+		{title: "2fa code", expected: "command_2fa_code"},
+		{title: "éàç", expected: "command_12"},
+		{title: "", expected: "command_12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.title, func(t *testing.T) {
+			command := models.NewCommand("ls", 0, time.Now())
+			command.ID = 12
+			command.Title = tt.title
+			assert.Equal(t, tt.expected, GetFunctionName(command))
+		})
+	}
+}
+
+func TestShellIntegrationService_GenerateFunctions(t *testing.T) {
+	service := NewShellIntegrationService()
+	logs := models.NewCommand("kubectl -n <namespace> logs <pod> --tail={{lines:100}}", 0, time.Now())
+	logs.ID = 1
+	logs.Title = "Pod logs"
+	logs.Description = "Display the logs of a pod\n\nin a namespace"
+	logs.Placeholders = []*models.PlaceholderDefinition{
+		{Name: "namespace", Description: "kubernetes namespace", AllowedValues: []string{"dev", "prod"}},
+	}
+	duplicate := models.NewCommand("ls -la", 0, time.Now())
+	duplicate.ID = 2
+	duplicate.Title = "pod logs"
+
+	var script bytes.Buffer
+	err := service.GenerateFunctions(&script, ShellTypeZsh, "bm_", []*models.Command{logs, duplicate})
+	require.NoError(t, err)
+
+	assert.Contains(t, script.String(), "#!/usr/bin/env zsh")
+	assert.Contains(t, script.String(), "# Pod logs\n# Display the logs of a pod\n#\n# in a namespace\n")
+	assert.Contains(t, script.String(), "#   $1 namespace: kubernetes namespace (values: dev, prod)\n")
+	assert.Contains(t, script.String(), "#   $3 lines (default: 100)\n")
+	assert.Contains(t, script.String(), "bm_pod_logs() {\n  if [[ $# -lt 2 ]]; then\n")
+	assert.Contains(t, script.String(), `echo "usage: bm_pod_logs <namespace> <pod> [lines]" >&2`)
+	assert.Contains(t, script.String(), `kubectl -n "${1}" logs "${2}" --tail="${3:-100}"`+"\n}")
+	assert.Contains(t, script.String(), "bm_pod_logs_2() {\nls -la\n}")
+}
+
+func TestShellIntegrationService_GenerateFunctionsQuoting(t *testing.T) {
+	service := NewShellIntegrationService()
+	command := models.NewCommand(
+		`grep '{{pattern}}' "{{file}}.log" {{dir:$HOME/my "logs"}} | sed $'s/\\t{{pattern}}/ /' # it's {{x}}`,
+		0, time.Now(),
+	)
+	command.Title = "search"
+
+	var script bytes.Buffer
+	require.NoError(t, service.GenerateFunctions(&script, ShellTypeBash, "", []*models.Command{command}))
+	// the single quotes are closed around the placeholders, the values are never split
+	assert.Contains(t, script.String(), ""+
+		`grep ''"${1}"'' "${2}.log" "${3:-\$HOME/my \"logs\"}" | sed $'s/\\t'"${1}"$'/ /' # it's "${4}"`+"\n}")
+}
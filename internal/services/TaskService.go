@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"sync/atomic"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/executors"
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+)
+
+// taskNameMaxChars is the maximum width of the name of the tasks
+const taskNameMaxChars = 80
+
+// TaskService runs the commands in the background, at most maxTasks at the same time
+type TaskService struct {
+	runner *task.Runner
+	// runRecorder persists the result of the tasks once finished, nil to keep them in memory only
+	runRecorder CommandRunRecorderInterface
+	// workingDirectory is the directory the commands are run in
+	workingDirectory string
+	// recordedRuns is the number of runs persisted since the start of the application
+	recordedRuns atomic.Int64
+	// updates is signaled when the status or the output of a task has changed,
+	// the changes made until the UI reads it are reported by a single signal
+	updates chan struct{}
+}
+
+// NewTaskService creates a TaskService running the commands with the executor,
+// the result of each run is recorded with runRecorder if not nil
+func NewTaskService(executor task.Executor, maxTasks int, runRecorder CommandRunRecorderInterface) *TaskService {
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		slog.Warn("Unable to get the working directory of the tasks", "error", err)
+	}
+	service := &TaskService{
+		runner:           nil,
+		runRecorder:      runRecorder,
+		workingDirectory: workingDirectory,
+		recordedRuns:     atomic.Int64{},
+		updates:          make(chan struct{}, 1),
+	}
+	service.runner = task.NewRunner(executor, maxTasks, service.notify, service.recordRun)
+	return service
+}
+
+// NewShellTaskService creates a TaskService running the commands in a subshell of the given type
+func NewShellTaskService(shellType ShellType, maxTasks int, runRecorder CommandRunRecorderInterface) *TaskService {
+	shell := "sh"
+	if shellType == ShellTypeBash || shellType == ShellTypeZsh {
+		shell = string(shellType)
+	}
+	return NewTaskService(&executors.DefaultTaskExecutor{Shell: shell}, maxTasks, runRecorder)
+}
+
+func (s *TaskService) notify(*task.Task) {
+	select {
+	case s.updates <- struct{}{}:
+	default:
+		// a change is already signaled, the UI reads the state of all the tasks anyway
+	}
+}
+
+// recordRun persists the result of a finished task, a failure is only logged
+// as it should not prevent running the commands
+func (s *TaskService) recordRun(t *task.Task) {
+	if s.runRecorder == nil {
+		return
+	}
+	status := models.GetExitCodeRunStatus(t.ExitCode())
+	switch t.Status() {
+	case task.Canceled:
+		status = models.CommandRunStatusCanceled
+	case task.Errored:
+		status = models.CommandRunStatusError
+	case task.Pending, task.Queued, task.Running, task.Exited:
+	}
+	run := models.NewCommandRun(t.SourceID, t.StartTime(), t.Duration(), t.ExitCode(), status)
+	run.WorkingDirectory = s.workingDirectory
+	output, truncated := t.Output()
+	if err := t.Err(); err != nil {
+		output += err.Error()
+	}
+	run.SetOutput(output, truncated)
+	if err := s.runRecorder.RecordCommandRun(run); err != nil {
+		slog.Warn("Unable to record command run", "commandId", t.SourceID, "error", err)
+		return
+	}
+	s.recordedRuns.Add(1)
+}
+
+// GetRecordedRunCount returns the number of runs persisted since the start of the application,
+// the UI reloads the commands when it changes to display their last run
+func (s *TaskService) GetRecordedRunCount() int64 {
+	return s.recordedRuns.Load()
+}
+
+// RunCommands queues a task for each command, the tasks are returned in the same order
+func (s *TaskService) RunCommands(commands []*models.Command) []*task.Task {
+	tasks := make([]*task.Task, 0, len(commands))
+	for _, command := range commands {
+		slog.Info("Running command", "id", command.ID)
+		tasks = append(tasks, s.runner.Enqueue(
+			command.ID, command.GetSingleLineDescription(taskNameMaxChars), command.Script,
+		))
+	}
+	return tasks
+}
+
+// RunCommand runs the command in the foreground with the given streams until it exits or ctx is done
+// and returns its task, the run is recorded like the queued ones
+func (s *TaskService) RunCommand(ctx context.Context, command *models.Command, streams task.Streams) *task.Task {
+	slog.Info("Running command in the foreground", "id", command.ID)
+	return s.runner.Run(
+		ctx, command.ID, command.GetSingleLineDescription(taskNameMaxChars), command.Script, streams,
+	)
+}
+
+// GetTasks returns the tasks run since the start of the application, the oldest first
+func (s *TaskService) GetTasks() []*task.Task {
+	return s.runner.Tasks()
+}
+
+// GetTask returns the task having the given ID, nil if it does not exist
+func (s *TaskService) GetTask(id resource.ID) *task.Task {
+	return s.runner.GetTask(id)
+}
+
+// CancelTask stops the task if it is running or queued,
+// false is returned if the task does not exist or is already finished
+func (s *TaskService) CancelTask(id resource.ID) bool {
+	return s.runner.Cancel(id)
+}
+
+// HasRunningTasks returns true if a task is running or queued
+func (s *TaskService) HasRunningTasks() bool {
+	for _, t := range s.runner.Tasks() {
+		if !t.Status().IsFinal() {
+			return true
+		}
+	}
+	return false
+}
+
+// GetTaskUpdates returns the channel signaled when the status or the output of a task has changed
+func (s *TaskService) GetTaskUpdates() <-chan struct{} {
+	return s.updates
+}
+
+// Stop cancels all the running and queued tasks
+func (s *TaskService) Stop() {
+	s.runner.Stop()
+}
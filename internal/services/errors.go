@@ -1,6 +1,11 @@
 package services
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
 
 type ShellcheckUnknownError struct {
 	Err error
@@ -34,3 +39,273 @@ type InvalidTerminalError struct {
 func (e *InvalidTerminalError) Error() string {
 	return fmt.Errorf("invalid terminal error: %w", e.Err).Error()
 }
+
+type TagTooLongError struct {
+	Tag string
+}
+
+func (e *TagTooLongError) Error() string {
+	return fmt.Sprintf("tag '%s' is too long, maximum %d characters allowed", e.Tag, models.TagMaxLength)
+}
+
+type EmptyTagError struct{}
+
+func (*EmptyTagError) Error() string {
+	return "tag cannot be empty"
+}
+
+type FolderTitleTooLongError struct {
+	Title string
+}
+
+func (e *FolderTitleTooLongError) Error() string {
+	return fmt.Sprintf(
+		"folder '%s' is too long, maximum %d characters allowed", e.Title, models.FolderTitleMaxLength,
+	)
+}
+
+type EmptyFolderTitleError struct{}
+
+func (*EmptyFolderTitleError) Error() string {
+	return "folder title cannot be empty"
+}
+
+type FolderNotFoundError struct {
+	ID resource.ID
+}
+
+func (e *FolderNotFoundError) Error() string {
+	return fmt.Sprintf("folder %d not found", e.ID)
+}
+
+type FolderPathNotFoundError struct {
+	Path string
+}
+
+func (e *FolderPathNotFoundError) Error() string {
+	return fmt.Sprintf("folder '%s' not found", e.Path)
+}
+
+type FolderCycleError struct {
+	FolderPath string
+	ParentPath string
+}
+
+func (e *FolderCycleError) Error() string {
+	return fmt.Sprintf("folder '%s' cannot be moved into its own sub folder '%s'", e.FolderPath, e.ParentPath)
+}
+
+type CommandNotFoundError struct {
+	ID resource.ID
+}
+
+func (e *CommandNotFoundError) Error() string {
+	return fmt.Sprintf("command %d not found", e.ID)
+}
+
+type RevisionNotFoundError struct {
+	ID        resource.ID
+	CommandID resource.ID
+}
+
+func (e *RevisionNotFoundError) Error() string {
+	return fmt.Sprintf("revision %d of command %d not found", e.ID, e.CommandID)
+}
+
+type CommandNotImportedError struct {
+	ID     resource.ID
+	Status models.CommandStatus
+}
+
+func (e *CommandNotImportedError) Error() string {
+	return fmt.Sprintf("command %d cannot be saved as a suggestion, its status is %s", e.ID, e.Status)
+}
+
+type UnsupportedBookmarkFileVersionError struct {
+	Version int
+}
+
+func (e *UnsupportedBookmarkFileVersionError) Error() string {
+	return fmt.Sprintf(
+		"bookmark file version %d is not supported, maximum version %d", e.Version, models.BookmarkFileVersion,
+	)
+}
+
+type InvalidBookmarkError struct {
+	Reason string
+	Index  int
+}
+
+func (e *InvalidBookmarkError) Error() string {
+	return fmt.Sprintf("command #%d: %s", e.Index+1, e.Reason)
+}
+
+type SkippedSnippetError struct {
+	Reason     string
+	LineNumber int
+}
+
+func (e *SkippedSnippetError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.LineNumber, e.Reason)
+}
+
+type UnknownSnippetFileFormatError struct {
+	FilePath string
+}
+
+func (e *UnknownSnippetFileFormatError) Error() string {
+	return fmt.Sprintf(
+		"unknown format of '%s', expecting a navi cheat file (.cheat), a directory of cheat files "+
+			"or a pet snippets file (.toml)", e.FilePath,
+	)
+}
+
+type InvalidIngestionRegexpError struct {
+	Err    error
+	Regexp string
+}
+
+func (e *InvalidIngestionRegexpError) Error() string {
+	return fmt.Sprintf("invalid ingestion filter regexp '%s': %v", e.Regexp, e.Err)
+}
+
+func (e *InvalidIngestionRegexpError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidConfigFileError struct {
+	Err      error
+	FilePath string
+}
+
+func (e *InvalidConfigFileError) Error() string {
+	return fmt.Sprintf("invalid configuration file '%s': %v", e.FilePath, e.Err)
+}
+
+func (e *InvalidConfigFileError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidSecretActionError struct {
+	Action string
+}
+
+func (e *InvalidSecretActionError) Error() string {
+	return fmt.Sprintf("invalid secret action '%s', expecting skip, redact or flag", e.Action)
+}
+
+type InvalidSecretPatternRegexpError struct {
+	Err     error
+	Pattern string
+	Regexp  string
+}
+
+func (e *InvalidSecretPatternRegexpError) Error() string {
+	return fmt.Sprintf("invalid regexp '%s' of secret pattern '%s' (secrets.patterns): %v", e.Regexp, e.Pattern, e.Err)
+}
+
+func (e *InvalidSecretPatternRegexpError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidDangerRuleRegexpError struct {
+	Err    error
+	Rule   string
+	Regexp string
+}
+
+func (e *InvalidDangerRuleRegexpError) Error() string {
+	return fmt.Sprintf("invalid regexp '%s' of danger rule '%s' (danger.rules): %v", e.Regexp, e.Rule, e.Err)
+}
+
+func (e *InvalidDangerRuleRegexpError) Unwrap() error {
+	return e.Err
+}
+
+type InvalidRiskLevelError struct {
+	Rule  string
+	Level string
+}
+
+func (e *InvalidRiskLevelError) Error() string {
+	return fmt.Sprintf("invalid risk level '%s' of danger rule '%s', expecting none, low, medium or high", e.Level, e.Rule)
+}
+
+type EmptyScriptError struct{}
+
+func (*EmptyScriptError) Error() string {
+	return "script cannot be empty"
+}
+
+type CommandTitleTooLongError struct {
+	Title string
+}
+
+func (e *CommandTitleTooLongError) Error() string {
+	return fmt.Sprintf(
+		"title '%s' is too long, maximum %d characters allowed", e.Title, models.CommandTitleMaxLength,
+	)
+}
+
+type InvalidCommandStatusError struct {
+	Action string
+	Status models.CommandStatus
+	ID     resource.ID
+}
+
+func (e *InvalidCommandStatusError) Error() string {
+	return fmt.Sprintf("command %d cannot be %s, its status is %s", e.ID, e.Action, e.Status)
+}
+
+type MissingPlaceholderValueError struct {
+	Name string
+}
+
+func (e *MissingPlaceholderValueError) Error() string {
+	return fmt.Sprintf("missing value of placeholder '%s', use --set %s=value", e.Name, e.Name)
+}
+
+type UnknownPlaceholderError struct {
+	Name string
+	ID   resource.ID
+}
+
+func (e *UnknownPlaceholderError) Error() string {
+	return fmt.Sprintf("command %d does not have any placeholder '%s'", e.ID, e.Name)
+}
+
+type DangerousCommandError struct {
+	Reason string
+	ID     resource.ID
+}
+
+func (e *DangerousCommandError) Error() string {
+	return fmt.Sprintf("command %d is dangerous (%s), use --yes to run it anyway", e.ID, e.Reason)
+}
+
+// CommandExitError is returned when a command run from the command line has not exited successfully,
+// the application exits with the same exit code
+type CommandExitError struct {
+	ID       resource.ID
+	ExitCode int
+}
+
+func (e *CommandExitError) Error() string {
+	return fmt.Sprintf("command %d exited with code %d", e.ID, e.ExitCode)
+}
+
+type UnknownCommandError struct {
+	Command string
+}
+
+func (e *UnknownCommandError) Error() string {
+	return fmt.Sprintf("unknown command '%s'", e.Command)
+}
+
+type UnknownCommandOutputFormatError struct {
+	Format string
+}
+
+func (e *UnknownCommandOutputFormatError) Error() string {
+	return fmt.Sprintf("unknown output format '%s', expecting text, json, jsonl, tsv or script0", e.Format)
+}
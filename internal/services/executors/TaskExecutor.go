@@ -1 +1,54 @@
 package executors
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os/exec"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+)
+
+// taskWaitDelay is the time given to the processes of a canceled task to exit
+// before their output pipes are closed
+const taskWaitDelay = 2 * time.Second
+
+// DefaultTaskExecutor runs the scripts of the tasks in a subshell
+type DefaultTaskExecutor struct {
+	// Shell is the shell interpreting the scripts (eg: bash), sh if empty
+	Shell string
+}
+
+// Execute runs the script with `<shell> -c` and the given streams.
+// A script without stdin runs in its own process group, so the processes it has started are killed
+// with it when the context is canceled. A script reading stdin stays in the process group of the
+// application so it can read the terminal and receives the signals sent from it (eg: ctrl+c).
+// The exit code of the shell is returned, err is only set if the shell cannot be run.
+func (e *DefaultTaskExecutor) Execute(ctx context.Context, script string, streams task.Streams) (int, error) {
+	shell := e.Shell
+	if shell == "" {
+		shell = "sh"
+	}
+	command := exec.CommandContext(ctx, shell, "-c", script) //nolint:gosec // running the script is the purpose
+	command.Stdin = streams.Stdin
+	command.Stdout = streams.Stdout
+	command.Stderr = streams.Stderr
+	command.WaitDelay = taskWaitDelay
+	if streams.Stdin == nil {
+		setProcessGroup(command)
+	}
+
+	slog.Debug("Executing task", "shell", shell, "script", script)
+	err := command.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		slog.Debug("Task exited", "exitCode", exitErr.ExitCode())
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		slog.Error("Failed to run task", "shell", shell, "error", err)
+		return -1, err
+	}
+	return 0, nil
+}
@@ -0,0 +1,11 @@
+//go:build !unix
+
+package executors
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing as process groups are not available,
+// only the shell is killed when the command is canceled
+func setProcessGroup(_ *exec.Cmd) {}
@@ -0,0 +1,69 @@
+//go:build unix
+
+package executors
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/task"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultTaskExecutor_Execute(t *testing.T) {
+	executor := &DefaultTaskExecutor{Shell: ""}
+	output := &bytes.Buffer{}
+
+	exitCode, err := executor.Execute(context.Background(), "echo out; echo err >&2; exit 3", task.Streams{
+		Stdin: nil, Stdout: output, Stderr: output,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, exitCode)
+	assert.Equal(t, "out\nerr\n", output.String())
+}
+
+func TestDefaultTaskExecutor_ExecuteStreams(t *testing.T) {
+	executor := &DefaultTaskExecutor{Shell: "sh"}
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	exitCode, err := executor.Execute(context.Background(), "cat; echo err >&2", task.Streams{
+		Stdin: strings.NewReader("piped\n"), Stdout: stdout, Stderr: stderr,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "piped\n", stdout.String())
+	assert.Equal(t, "err\n", stderr.String())
+}
+
+func TestDefaultTaskExecutor_ExecuteCanceled(t *testing.T) {
+	executor := &DefaultTaskExecutor{Shell: "sh"}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	// the sub process keeps the output open, it has to be killed with the shell
+	exitCode, err := executor.Execute(ctx, "sleep 10 | cat; echo never", task.Streams{
+		Stdin: nil, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, -1, exitCode)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestDefaultTaskExecutor_ExecuteUnknownShell(t *testing.T) {
+	executor := &DefaultTaskExecutor{Shell: "unknown-shell-for-test"}
+
+	exitCode, err := executor.Execute(context.Background(), "true", task.Streams{
+		Stdin: nil, Stdout: &bytes.Buffer{}, Stderr: &bytes.Buffer{},
+	})
+
+	require.Error(t, err)
+	assert.Equal(t, -1, exitCode)
+}
@@ -1,8 +1,12 @@
 package services
 
 import (
+	"context"
+	"io/fs"
+
 	"github.com/fchastanet/shell-command-bookmarker/internal/args"
 	"github.com/fchastanet/shell-command-bookmarker/internal/services/models"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 )
 
 type CommandExecutorInterface interface {
@@ -16,15 +20,23 @@ type LookupExecutorInterface interface {
 	LookPath(path string) (string, error)
 }
 
+// CommandRunRecorderInterface persists the result of the commands run by the tasks
+type CommandRunRecorderInterface interface {
+	RecordCommandRun(run *models.CommandRun) error
+}
+
 // AppServiceInterface defines the expected behavior of an AppService
 type AppServiceInterface interface {
-	Main(cli *args.Cli, sqliteSchema string) error
+	Main(cli *args.Cli, migrations fs.FS) error
 	IsTerminalCompatible() error
 	IsShellSelectionMode() bool
 	Init(cfg AppServiceConfig) error
 	Cleanup()
 	GetHistoryService() *HistoryService
+	GetTaskService() *TaskService
 	HandleShellIntegrationScriptGeneration(cli *args.Cli) bool
+	HandleImportExport(cli *args.Cli, migrations fs.FS) (bool, error)
+	HandleCommand(cli *args.Cli, migrations fs.FS) (bool, error)
 	Self() *AppService
 }
 
@@ -36,8 +48,32 @@ type HistoryServiceInterface interface {
 	GetCommandStatusesByCategory(category CommandCategory) []models.CommandStatus
 	GetCommandCategoryTitles() map[CommandCategory]string
 	GetAllCommandCategories() []CommandCategory
-	IngestHistory() error
+	SearchCommands(filter string, statuses ...models.CommandStatus) ([]*models.Command, error)
+	IngestHistory(ctx context.Context, onProgress func(HistoryIngestionReport)) (*HistoryIngestionReport, error)
 	UpdateCommand(command *models.Command) (*models.Command, error)
 	ComposeCommand(commands []*models.Command) (*models.Command, error)
 	CreateCommandsString(commands []*models.Command) string
+	GetTags() ([]*models.Tag, error)
+	GetTagTitles() []string
+	CreateTag(title string) (*models.Tag, error)
+	RenameTag(tagID resource.ID, title string) error
+	DeleteTag(tagID resource.ID) error
+	AddTagsToCommands(commands []*models.Command, tags []string) error
+	RemoveTagsFromCommands(commands []*models.Command, tags []string) error
+	GetFolders() ([]*models.Folder, error)
+	GetFolderPaths() []string
+	CreateFolderPath(path string) (*models.Folder, error)
+	RenameFolder(folderID resource.ID, title string) error
+	MoveFolder(folderID resource.ID, parentID resource.ID) error
+	MoveFolderToPath(folderID resource.ID, parentPath string) error
+	DeleteFolder(folderID resource.ID) error
+	MoveCommandsToFolder(commands []*models.Command, folderID resource.ID) error
+	MoveCommandsToFolderPath(commands []*models.Command, path string) (*models.Folder, error)
+	RecordCommandsUsage(commands []*models.Command) error
+	RecordCommandRun(run *models.CommandRun) error
+	GetRecentCommandRuns(commandID resource.ID) ([]*models.CommandRun, error)
+	GetPlaceholders(commands []*models.Command) ([]*models.Placeholder, error)
+	FillPlaceholders(commands []*models.Command, values map[string]string) ([]*models.Command, error)
+	GetCommandRevisions(commandID resource.ID) (*models.Command, []*models.CommandRevision, error)
+	RevertCommand(commandID resource.ID, revisionID resource.ID) (*models.Command, error)
 }
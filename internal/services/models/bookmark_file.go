@@ -0,0 +1,133 @@
+package models
+
+import (
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+const (
+	// BookmarkFileVersion is the version of the bookmark file format written by the export
+	BookmarkFileVersion = 1
+	// CommandTitleMaxLength is the maximum length of a command title (see command table constraint)
+	CommandTitleMaxLength = 50
+)
+
+// BookmarkFile is the content of an exported bookmark file (JSON or YAML)
+type BookmarkFile struct {
+	Commands []*BookmarkCommand `json:"commands" yaml:"commands"`
+	Version  int                `json:"version"  yaml:"version"`
+}
+
+// BookmarkCommand is a command as written in a bookmark file,
+// the folder is identified by its path as ids are specific to a database
+type BookmarkCommand struct {
+	CreationDatetime     time.Time              `json:"creationDatetime"       yaml:"creationDatetime"`
+	ModificationDatetime time.Time              `json:"modificationDatetime"   yaml:"modificationDatetime"`
+	Title                string                 `json:"title"                  yaml:"title"`
+	Description          string                 `json:"description,omitempty"  yaml:"description,omitempty"`
+	Script               string                 `json:"script"                 yaml:"script"`
+	Status               CommandStatus          `json:"status"                 yaml:"status"`
+	LintStatus           LintStatus             `json:"lintStatus"             yaml:"lintStatus"`
+	Folder               string                 `json:"folder,omitempty"       yaml:"folder,omitempty"`
+	LintIssues           []map[string]any       `json:"lintIssues,omitempty"   yaml:"lintIssues,omitempty"`
+	Tags                 []string               `json:"tags,omitempty"         yaml:"tags,omitempty"`
+	Placeholders         []*BookmarkPlaceholder `json:"placeholders,omitempty" yaml:"placeholders,omitempty"`
+	Elapsed              int                    `json:"elapsed,omitempty"      yaml:"elapsed,omitempty"`
+}
+
+// BookmarkPlaceholder is a placeholder definition as written in a bookmark file
+type BookmarkPlaceholder struct {
+	Name          string   `json:"name"                    yaml:"name"`
+	Description   string   `json:"description,omitempty"   yaml:"description,omitempty"`
+	AllowedValues []string `json:"allowedValues,omitempty" yaml:"allowedValues,omitempty"`
+}
+
+// NewBookmarkCommand converts the command for a bookmark file,
+// folderPath is the path of the command folder, empty for the root folder
+func NewBookmarkCommand(command *Command, folderPath string) *BookmarkCommand {
+	placeholders := make([]*BookmarkPlaceholder, 0, len(command.Placeholders))
+	for _, definition := range command.Placeholders {
+		placeholders = append(placeholders, &BookmarkPlaceholder{
+			Name:          definition.Name,
+			Description:   definition.Description,
+			AllowedValues: definition.AllowedValues,
+		})
+	}
+	return &BookmarkCommand{
+		CreationDatetime:     command.CreationDatetime,
+		ModificationDatetime: command.ModificationDatetime,
+		Title:                command.Title,
+		Description:          command.Description,
+		Script:               command.Script,
+		Status:               command.Status,
+		LintStatus:           command.LintStatus,
+		LintIssues:           command.GetLintIssues(),
+		Elapsed:              command.Elapsed,
+		Folder:               folderPath,
+		Tags:                 command.Tags,
+		Placeholders:         placeholders,
+	}
+}
+
+// ToCommand converts the bookmark to a new command, the folder has to be resolved by the caller.
+// Missing values are replaced by the defaults used for commands saved from the UI.
+func (b *BookmarkCommand) ToCommand() *Command {
+	now := time.Now().Truncate(time.Second)
+	command := NewCommand(b.Script, b.Elapsed, b.CreationDatetime)
+	command.Title = b.Title
+	command.Description = b.Description
+	command.Status = b.Status
+	if command.Status == "" {
+		command.Status = CommandStatusSaved
+	}
+	if command.CreationDatetime.IsZero() {
+		command.CreationDatetime = now
+	}
+	command.ModificationDatetime = b.ModificationDatetime
+	if command.ModificationDatetime.IsZero() {
+		command.ModificationDatetime = now
+	}
+	if b.LintStatus != "" {
+		command.LintStatus = b.LintStatus
+	}
+	if len(b.LintIssues) > 0 {
+		lintIssues, err := json.Marshal(b.LintIssues)
+		if err != nil {
+			slog.Warn("Error encoding lint issues, lint status reset", "script", b.Script, "error", err)
+			command.LintStatus = LintStatusNotAvailable
+		} else {
+			command.LintIssues = string(lintIssues)
+		}
+	}
+	if b.Tags != nil {
+		command.Tags = ParseTags(strings.Join(b.Tags, TagsSeparator))
+	}
+	for _, placeholder := range b.Placeholders {
+		command.Placeholders = append(command.Placeholders, &PlaceholderDefinition{
+			Name:          placeholder.Name,
+			Description:   placeholder.Description,
+			AllowedValues: placeholder.AllowedValues,
+		})
+	}
+	return command
+}
+
+// IsValidCommandStatus checks the status is one of the known command statuses
+func IsValidCommandStatus(status CommandStatus) bool {
+	switch status {
+	case CommandStatusImported, CommandStatusSaved, CommandStatusDeleted, CommandStatusObsolete:
+		return true
+	}
+	return false
+}
+
+// IsValidLintStatus checks the status is one of the known lint statuses
+func IsValidLintStatus(status LintStatus) bool {
+	switch status {
+	case LintStatusNotAvailable, LintStatusOK, LintStatusWarning, LintStatusError, LintStatusShellcheckFailed:
+		return true
+	}
+	return false
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/fchastanet/shell-command-bookmarker/pkg/utils"
 )
 
 type CommandStatus string
@@ -17,19 +18,38 @@ const (
 	CommandStatusObsolete CommandStatus = "OBSOLETE"
 )
 
+// SuggestionMinOccurrences is the number of occurrences in the history files
+// from which an imported command is suggested to be saved
+const SuggestionMinOccurrences = 2
+
 type Command struct {
 	CreationDatetime     time.Time
 	ModificationDatetime time.Time
+	LastUsedDatetime     time.Time // zero time when the command has never been used
+	FirstSeenDatetime    time.Time // zero time when the command has not been found in a history file
+	LastSeenDatetime     time.Time // zero time when the command has not been found in a history file
+	LastRunDatetime      time.Time // zero time when the command has never been run
 	Title                string
 	Description          string
 	Script               string
 	Status               CommandStatus
 	LintIssues           string
 	LintStatus           LintStatus
+	SecretReason         string // why the secret scanner redacted or flagged the command, empty if no secret
+	LastRunStatus        CommandRunStatus
+	RiskLevel            RiskLevel
+	RiskReason           string // danger rules matching the script, empty if the command is not risky
 	lintIssuesParsed     []map[string]any
+	Tags                 []string
+	Placeholders         []*PlaceholderDefinition
 	ID                   resource.ID
+	FolderID             resource.ID // RootFolderID when the command is not in a folder
+	HistorySourceID      resource.ID // NoHistorySourceID when the command is not imported from a history file
 	Elapsed              int
+	UseCount             int
+	OccurrenceCount      int // number of times the script has been found in the history files
 	FilterScore          int
+	FilterSnippet        string // highlighted part of the script matching the filter
 }
 
 type LintStatus string
@@ -49,20 +69,60 @@ func NewCommand(
 ) *Command {
 	return &Command{
 		ID:                   0,
+		FolderID:             RootFolderID,
+		HistorySourceID:      NoHistorySourceID,
+		SecretReason:         "",
 		Title:                "",
 		Description:          "",
 		Script:               script,
 		Elapsed:              elapsed,
 		LintIssues:           "[]",
 		lintIssuesParsed:     nil,
+		Tags:                 []string{},
+		Placeholders:         []*PlaceholderDefinition{},
 		LintStatus:           LintStatusNotAvailable,
 		Status:               CommandStatusImported,
 		CreationDatetime:     timestamp,
 		ModificationDatetime: time.Now(),
+		LastUsedDatetime:     time.Time{},
+		UseCount:             0,
+		OccurrenceCount:      0,
+		FirstSeenDatetime:    time.Time{},
+		LastSeenDatetime:     time.Time{},
+		LastRunDatetime:      time.Time{},
+		LastRunStatus:        CommandRunStatusNone,
+		RiskLevel:            RiskLevelNone,
+		RiskReason:           "",
 		FilterScore:          0,
+		FilterSnippet:        "",
+	}
+}
+
+// CommandOccurrences are the occurrences found in the history files of the script of an existing command
+type CommandOccurrences struct {
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Script    string
+	Count     int
+}
+
+// Add records an occurrence of the script found at the given time
+func (o *CommandOccurrences) Add(seenAt time.Time) {
+	o.Count++
+	if o.FirstSeen.IsZero() || seenAt.Before(o.FirstSeen) {
+		o.FirstSeen = seenAt
+	}
+	if seenAt.After(o.LastSeen) {
+		o.LastSeen = seenAt
 	}
 }
 
+// IsSuggestion returns true if the command has been imported and run often enough
+// to be suggested to be saved
+func (c *Command) IsSuggestion() bool {
+	return c.Status == CommandStatusImported && c.OccurrenceCount >= SuggestionMinOccurrences
+}
+
 func (c *Command) IsEditable() bool {
 	return c.Status == CommandStatusImported ||
 		c.Status == CommandStatusSaved
@@ -93,15 +153,11 @@ func (c *Command) GetID() resource.ID {
 	return c.ID
 }
 
+// GetSingleLineDescription returns the title or the script if there is no title,
+// truncated to maxChars terminal cells
 func (c *Command) GetSingleLineDescription(maxChars int) string {
 	if c.Title == "" {
-		if len(c.Script) > maxChars {
-			return c.Script[:maxChars] + "..."
-		}
-		return c.Script
-	}
-	if len(c.Title) > maxChars {
-		return c.Title[:maxChars] + "..."
+		return utils.TruncateWidth(c.Script, maxChars, "...")
 	}
-	return c.Title
+	return utils.TruncateWidth(c.Title, maxChars, "...")
 }
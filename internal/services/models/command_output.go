@@ -0,0 +1,99 @@
+package models
+
+import (
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// CommandOutput is a command as written by the json and jsonl output formats of the command line.
+// All the fields are always present so scripts can rely on them, the dates never set are null.
+// See doc/output-formats.md, the field names must not be changed.
+type CommandOutput struct {
+	CreationDatetime     time.Time            `json:"creationDatetime"`
+	ModificationDatetime time.Time            `json:"modificationDatetime"`
+	LastUsedDatetime     *time.Time           `json:"lastUsedDatetime"`
+	FirstSeenDatetime    *time.Time           `json:"firstSeenDatetime"`
+	LastSeenDatetime     *time.Time           `json:"lastSeenDatetime"`
+	LastRunDatetime      *time.Time           `json:"lastRunDatetime"`
+	Title                string               `json:"title"`
+	Description          string               `json:"description"`
+	Script               string               `json:"script"`
+	Status               CommandStatus        `json:"status"`
+	Folder               string               `json:"folder"`
+	LintStatus           LintStatus           `json:"lintStatus"`
+	LastRunStatus        CommandRunStatus     `json:"lastRunStatus"`
+	RiskLevel            RiskLevel            `json:"riskLevel"`
+	RiskReason           string               `json:"riskReason"`
+	SecretReason         string               `json:"secretReason"`
+	LintIssues           []map[string]any     `json:"lintIssues"`
+	Tags                 []string             `json:"tags"`
+	Placeholders         []*PlaceholderOutput `json:"placeholders"`
+	ID                   resource.ID          `json:"id"`
+	FolderID             resource.ID          `json:"folderId"`
+	Elapsed              int                  `json:"elapsed"`
+	UseCount             int                  `json:"useCount"`
+	OccurrenceCount      int                  `json:"occurrenceCount"`
+}
+
+// PlaceholderOutput is a placeholder definition as written by the json and jsonl output formats
+type PlaceholderOutput struct {
+	Name          string   `json:"name"`
+	Description   string   `json:"description"`
+	AllowedValues []string `json:"allowedValues"`
+}
+
+// NewCommandOutput converts the command for the output of the command line,
+// folderPath is the path of the command folder, empty for the root folder
+func NewCommandOutput(command *Command, folderPath string) *CommandOutput {
+	placeholders := make([]*PlaceholderOutput, 0, len(command.Placeholders))
+	for _, definition := range command.Placeholders {
+		allowedValues := definition.AllowedValues
+		if allowedValues == nil {
+			allowedValues = []string{}
+		}
+		placeholders = append(placeholders, &PlaceholderOutput{
+			Name:          definition.Name,
+			Description:   definition.Description,
+			AllowedValues: allowedValues,
+		})
+	}
+	tags := command.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	return &CommandOutput{
+		CreationDatetime:     command.CreationDatetime,
+		ModificationDatetime: command.ModificationDatetime,
+		LastUsedDatetime:     optionalTime(command.LastUsedDatetime),
+		FirstSeenDatetime:    optionalTime(command.FirstSeenDatetime),
+		LastSeenDatetime:     optionalTime(command.LastSeenDatetime),
+		LastRunDatetime:      optionalTime(command.LastRunDatetime),
+		Title:                command.Title,
+		Description:          command.Description,
+		Script:               command.Script,
+		Status:               command.Status,
+		Folder:               folderPath,
+		LintStatus:           command.LintStatus,
+		LastRunStatus:        command.LastRunStatus,
+		RiskLevel:            command.RiskLevel,
+		RiskReason:           command.RiskReason,
+		SecretReason:         command.SecretReason,
+		LintIssues:           command.GetLintIssues(),
+		Tags:                 tags,
+		Placeholders:         placeholders,
+		ID:                   command.ID,
+		FolderID:             command.FolderID,
+		Elapsed:              command.Elapsed,
+		UseCount:             command.UseCount,
+		OccurrenceCount:      command.OccurrenceCount,
+	}
+}
+
+// optionalTime returns nil for the zero time
+func optionalTime(value time.Time) *time.Time {
+	if value.IsZero() {
+		return nil
+	}
+	return &value
+}
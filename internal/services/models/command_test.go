@@ -0,0 +1,44 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommand_GetSingleLineDescription(t *testing.T) {
+	tests := []struct {
+		name   string
+		title  string
+		script string
+		want   string
+	}{
+		{name: "short script", script: "docker ps", want: "docker ps"},
+		{name: "long script", script: "docker compose logs -f", want: "docker com..."},
+		{name: "title preferred", title: "List containers", script: "docker ps", want: "List conta..."},
+		{name: "accents", title: "Démarrer été", want: "Démarrer é..."},
+		{name: "CJK not split", script: "cd 文档项目说明.txt", want: "cd 文档项..."},
+		{name: "emoji not split", title: "🎉🎉🎉🎉🎉🎉🎉", want: "🎉🎉🎉🎉🎉..."},
+		{name: "grapheme not split", script: "echo 👨‍👩‍👧👨‍👩‍👧👨‍👩‍👧", want: "echo 👨‍👩‍👧👨‍👩‍👧..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			command := NewCommand(tt.script, 0, time.Now())
+			command.Title = tt.title
+			assert.Equal(t, tt.want, command.GetSingleLineDescription(10))
+		})
+	}
+}
+
+func TestCommandOccurrences_Add(t *testing.T) {
+	first := time.Date(2024, 4, 5, 10, 0, 0, 0, time.UTC)
+	occurrences := &CommandOccurrences{Script: "docker ps -a | grep web"}
+	occurrences.Add(first.Add(time.Hour))
+	occurrences.Add(first)
+	occurrences.Add(first.Add(2 * time.Hour))
+
+	assert.Equal(t, 3, occurrences.Count)
+	assert.Equal(t, first, occurrences.FirstSeen)
+	assert.Equal(t, first.Add(2*time.Hour), occurrences.LastSeen)
+}
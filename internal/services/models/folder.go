@@ -0,0 +1,133 @@
+package models
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+const (
+	// FolderTitleMaxLength is the maximum length of a folder title (see folder table constraint)
+	FolderTitleMaxLength = 30
+	// FolderPathSeparator separates the folder titles of a folder path
+	FolderPathSeparator = "/"
+	// RootFolderID is the id used for commands that are not in any folder
+	RootFolderID resource.ID = 0
+)
+
+type Folder struct {
+	Title string
+	// Path is the full path of the folder from the root, eg: "docker/compose"
+	Path     string
+	ID       resource.ID
+	ParentID resource.ID
+	// Depth is 0 for top level folders
+	Depth int
+	// CommandCount is the number of non obsolete commands directly in the folder
+	CommandCount int
+}
+
+func (f *Folder) GetID() resource.ID {
+	return f.ID
+}
+
+// NormalizeFolderTitle trims the title and removes the path separator
+func NormalizeFolderTitle(title string) string {
+	title = strings.ReplaceAll(title, FolderPathSeparator, "-")
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// SplitFolderPath splits a folder path in normalized folder titles,
+// empty parts are ignored
+func SplitFolderPath(path string) []string {
+	titles := []string{}
+	for _, part := range strings.Split(path, FolderPathSeparator) {
+		title := NormalizeFolderTitle(part)
+		if title != "" {
+			titles = append(titles, title)
+		}
+	}
+	return titles
+}
+
+// SortFoldersAsTree orders the folders depth first, children being sorted
+// by title, and computes their Path and Depth.
+// Folders whose parent is unknown are considered as top level folders.
+func SortFoldersAsTree(folders []*Folder) []*Folder {
+	knownIDs := make(map[resource.ID]bool, len(folders))
+	for _, folder := range folders {
+		knownIDs[folder.ID] = true
+	}
+	children := map[resource.ID][]*Folder{}
+	for _, folder := range folders {
+		parentID := folder.ParentID
+		if !knownIDs[parentID] {
+			parentID = RootFolderID
+		}
+		children[parentID] = append(children[parentID], folder)
+	}
+
+	sorted := make([]*Folder, 0, len(folders))
+	visited := make(map[resource.ID]bool, len(folders))
+	var visit func(parentID resource.ID, parentPath string, depth int)
+	visit = func(parentID resource.ID, parentPath string, depth int) {
+		nodes := children[parentID]
+		sort.SliceStable(nodes, func(i, j int) bool {
+			return strings.ToLower(nodes[i].Title) < strings.ToLower(nodes[j].Title)
+		})
+		for _, folder := range nodes {
+			if visited[folder.ID] {
+				continue
+			}
+			visited[folder.ID] = true
+			folder.Depth = depth
+			folder.Path = folder.Title
+			if parentPath != "" {
+				folder.Path = parentPath + FolderPathSeparator + folder.Title
+			}
+			sorted = append(sorted, folder)
+			visit(folder.ID, folder.Path, depth+1)
+		}
+	}
+	visit(RootFolderID, "", 0)
+	return sorted
+}
+
+// GetFolderSubtreeIDs returns the id of the folder and the ids of all its descendants
+func GetFolderSubtreeIDs(folders []*Folder, folderID resource.ID) []resource.ID {
+	ids := []resource.ID{folderID}
+	seen := map[resource.ID]bool{folderID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, folder := range folders {
+			if folder.ParentID == ids[i] && !seen[folder.ID] {
+				seen[folder.ID] = true
+				ids = append(ids, folder.ID)
+			}
+		}
+	}
+	return ids
+}
+
+// FindFolderByPath returns the folder having the given path (case insensitive)
+func FindFolderByPath(folders []*Folder, path string) *Folder {
+	path = strings.Join(SplitFolderPath(path), FolderPathSeparator)
+	for _, folder := range folders {
+		if strings.EqualFold(folder.Path, path) {
+			return folder
+		}
+	}
+	return nil
+}
+
+// FolderPathSuggestions returns the paths of the folders starting with value
+func FolderPathSuggestions(value string, folders []*Folder) []string {
+	suggestions := []string{}
+	prefix := strings.ToLower(strings.TrimSpace(value))
+	for _, folder := range folders {
+		if strings.HasPrefix(strings.ToLower(folder.Path), prefix) {
+			suggestions = append(suggestions, folder.Path)
+		}
+	}
+	return suggestions
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestFolder(id, parentID resource.ID, title string) *Folder {
+	//nolint:exhaustruct // path and depth are computed by SortFoldersAsTree
+	return &Folder{ID: id, ParentID: parentID, Title: title}
+}
+
+func newTestFolders() []*Folder {
+	return []*Folder{
+		newTestFolder(3, 1, "compose"),
+		newTestFolder(1, 0, "docker"),
+		newTestFolder(4, 0, "Git"),
+		newTestFolder(2, 1, "build"),
+		newTestFolder(5, 2, "cache"),
+	}
+}
+
+func TestSortFoldersAsTree(t *testing.T) {
+	sorted := SortFoldersAsTree(newTestFolders())
+	paths := []string{}
+	depths := []int{}
+	for _, folder := range sorted {
+		paths = append(paths, folder.Path)
+		depths = append(depths, folder.Depth)
+	}
+	assert.Equal(t, []string{
+		"docker", "docker/build", "docker/build/cache", "docker/compose", "Git",
+	}, paths)
+	assert.Equal(t, []int{0, 1, 2, 1, 0}, depths)
+}
+
+func TestSortFoldersAsTreeUnknownParent(t *testing.T) {
+	sorted := SortFoldersAsTree([]*Folder{newTestFolder(2, 42, "orphan")})
+	assert.Len(t, sorted, 1)
+	assert.Equal(t, "orphan", sorted[0].Path)
+	assert.Equal(t, 0, sorted[0].Depth)
+}
+
+func TestGetFolderSubtreeIDs(t *testing.T) {
+	folders := newTestFolders()
+	assert.ElementsMatch(t, []resource.ID{1, 2, 3, 5}, GetFolderSubtreeIDs(folders, 1))
+	assert.Equal(t, []resource.ID{4}, GetFolderSubtreeIDs(folders, 4))
+}
+
+func TestSplitFolderPath(t *testing.T) {
+	assert.Equal(t, []string{}, SplitFolderPath(" / "))
+	assert.Equal(t, []string{"docker", "my build"}, SplitFolderPath("/docker/ my   build /"))
+}
+
+func TestFindFolderByPath(t *testing.T) {
+	folders := SortFoldersAsTree(newTestFolders())
+	folder := FindFolderByPath(folders, "Docker / Build")
+	if assert.NotNil(t, folder) {
+		assert.Equal(t, resource.ID(2), folder.ID)
+	}
+	assert.Nil(t, FindFolderByPath(folders, "docker/unknown"))
+}
+
+func TestFolderPathSuggestions(t *testing.T) {
+	folders := SortFoldersAsTree(newTestFolders())
+	assert.Equal(t, []string{"docker/build", "docker/build/cache"}, FolderPathSuggestions("docker/b", folders))
+	assert.Len(t, FolderPathSuggestions("", folders), len(folders))
+}
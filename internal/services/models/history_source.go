@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// NoHistorySourceID is used for the commands not imported from a history file
+const NoHistorySourceID resource.ID = 0
+
+// HistorySource is a shell history file from which commands are imported
+type HistorySource struct {
+	// Format is the shell writing the history file (bash, zsh or fish)
+	Format string
+	Path   string
+	ID     resource.ID
+}
+
+func (h *HistorySource) GetID() resource.ID {
+	return h.ID
+}
+
+// HistoryCheckpoint is the position in the file of a history source
+// up to which the commands have been ingested
+type HistoryCheckpoint struct {
+	// LastLineHash is the sha256 of the last line ingested
+	LastLineHash    string
+	HistorySourceID resource.ID
+	Inode           uint64
+	// Offset is the byte offset following the last line ingested
+	Offset int64
+}
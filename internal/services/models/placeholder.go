@@ -0,0 +1,193 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	// PlaceholderFieldsSeparator separates the name, the description and the allowed values
+	// of a placeholder definition (see ParsePlaceholderDefinitions)
+	PlaceholderFieldsSeparator = "|"
+	// PlaceholderValuesSeparator separates the allowed values of a placeholder definition
+	PlaceholderValuesSeparator = ","
+)
+
+// placeholderRegexp matches <name>, {{name}} and {{name:default}} placeholders
+//
+//nolint:gochecknoglobals // compiled once
+var placeholderRegexp = regexp.MustCompile(
+	`\{\{\s*([A-Za-z_][A-Za-z0-9_-]*)\s*(?::([^}]*))?\}\}|<([A-Za-z_][A-Za-z0-9_-]*)>`,
+)
+
+// PlaceholderDefinition is the description and the allowed values of a
+// placeholder of a command, stored alongside the command
+type PlaceholderDefinition struct {
+	Name          string
+	Description   string
+	AllowedValues []string
+}
+
+// Placeholder is a placeholder to fill in before using a command
+type Placeholder struct {
+	Name          string
+	Description   string
+	AllowedValues []string
+	// DefaultValue is the default value given in the script ({{name:default}})
+	DefaultValue string
+	// Value is the value previously entered for this placeholder, if any
+	Value string
+}
+
+// GetInitialValue returns the value proposed to the user: the previously entered value,
+// the default value of the script or the first allowed value
+func (p *Placeholder) GetInitialValue() string {
+	switch {
+	case p.Value != "" && (len(p.AllowedValues) == 0 || p.isAllowed(p.Value)):
+		return p.Value
+	case p.DefaultValue != "":
+		return p.DefaultValue
+	case len(p.AllowedValues) > 0:
+		return p.AllowedValues[0]
+	default:
+		return ""
+	}
+}
+
+func (p *Placeholder) isAllowed(value string) bool {
+	for _, allowed := range p.AllowedValues {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePlaceholders returns the placeholders of the script in order of appearance,
+// a placeholder used several times is returned once with the first default value given
+func ParsePlaceholders(script string) []*Placeholder {
+	placeholders := []*Placeholder{}
+	byName := map[string]*Placeholder{}
+	for _, match := range placeholderRegexp.FindAllStringSubmatch(script, -1) {
+		name, defaultValue := match[1], match[2]
+		if name == "" {
+			name = match[3]
+		}
+		if placeholder, ok := byName[name]; ok {
+			if placeholder.DefaultValue == "" {
+				placeholder.DefaultValue = defaultValue
+			}
+			continue
+		}
+		placeholder := &Placeholder{
+			Name:          name,
+			Description:   "",
+			AllowedValues: []string{},
+			DefaultValue:  defaultValue,
+			Value:         "",
+		}
+		byName[name] = placeholder
+		placeholders = append(placeholders, placeholder)
+	}
+	return placeholders
+}
+
+// FillPlaceholders replaces the placeholders of the script by the given values,
+// placeholders without value are left unchanged
+func FillPlaceholders(script string, values map[string]string) string {
+	return FillPlaceholdersFunc(script, func(name string, _ int, _ int) (string, bool) {
+		value, ok := values[name]
+		return value, ok
+	})
+}
+
+// FillPlaceholdersFunc replaces the placeholders of the script by the values returned by fill,
+// called in order of appearance with the name and the byte offsets of the start and of the end
+// of each placeholder in the script.
+// Placeholders for which fill returns false are left unchanged.
+func FillPlaceholdersFunc(script string, fill func(name string, start int, end int) (string, bool)) string {
+	var result strings.Builder
+	end := 0
+	for _, match := range placeholderRegexp.FindAllStringSubmatchIndex(script, -1) {
+		// {{name}} or <name>
+		nameStart, nameEnd := match[2], match[3]
+		if nameStart < 0 {
+			nameStart, nameEnd = match[6], match[7]
+		}
+		result.WriteString(script[end:match[0]])
+		if value, ok := fill(script[nameStart:nameEnd], match[0], match[1]); ok {
+			result.WriteString(value)
+		} else {
+			result.WriteString(script[match[0]:match[1]])
+		}
+		end = match[1]
+	}
+	result.WriteString(script[end:])
+	return result.String()
+}
+
+// ApplyDefinitions sets the description and the allowed values of the placeholders
+// having a definition
+func ApplyDefinitions(placeholders []*Placeholder, definitions []*PlaceholderDefinition) {
+	for _, placeholder := range placeholders {
+		for _, definition := range definitions {
+			if definition.Name != placeholder.Name {
+				continue
+			}
+			if placeholder.Description == "" {
+				placeholder.Description = definition.Description
+			}
+			if len(placeholder.AllowedValues) == 0 {
+				placeholder.AllowedValues = append([]string{}, definition.AllowedValues...)
+			}
+		}
+	}
+}
+
+// ParsePlaceholderDefinitions parses one placeholder definition per line,
+// formatted as "name | description | value1, value2", description and
+// allowed values are optional. Empty lines are ignored.
+func ParsePlaceholderDefinitions(value string) []*PlaceholderDefinition {
+	definitions := []*PlaceholderDefinition{}
+	for _, line := range strings.Split(value, "\n") {
+		fields := strings.SplitN(line, PlaceholderFieldsSeparator, 3) //nolint:mnd // name, description and values
+		name := strings.TrimSpace(fields[0])
+		if name == "" {
+			continue
+		}
+		definition := &PlaceholderDefinition{
+			Name:          name,
+			Description:   "",
+			AllowedValues: []string{},
+		}
+		if len(fields) > 1 {
+			definition.Description = strings.TrimSpace(fields[1])
+		}
+		if len(fields) > 2 { //nolint:mnd // allowed values are the third field
+			for _, allowed := range strings.Split(fields[2], PlaceholderValuesSeparator) {
+				if allowed = strings.TrimSpace(allowed); allowed != "" {
+					definition.AllowedValues = append(definition.AllowedValues, allowed)
+				}
+			}
+		}
+		definitions = append(definitions, definition)
+	}
+	return definitions
+}
+
+// FormatPlaceholderDefinitions formats the definitions, suitable for ParsePlaceholderDefinitions
+func FormatPlaceholderDefinitions(definitions []*PlaceholderDefinition) string {
+	lines := make([]string, 0, len(definitions))
+	for _, definition := range definitions {
+		line := definition.Name
+		if definition.Description != "" || len(definition.AllowedValues) > 0 {
+			line += " " + PlaceholderFieldsSeparator + " " + definition.Description
+		}
+		if len(definition.AllowedValues) > 0 {
+			line += " " + PlaceholderFieldsSeparator + " " +
+				strings.Join(definition.AllowedValues, PlaceholderValuesSeparator+" ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
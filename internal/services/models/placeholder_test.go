@@ -0,0 +1,111 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlaceholders(t *testing.T) {
+	placeholders := ParsePlaceholders(
+		"kubectl -n {{ namespace : default }} logs <pod> --context {{context}} && echo <pod> {{namespace:other}} < file",
+	)
+	require.Len(t, placeholders, 3)
+	assert.Equal(t, "namespace", placeholders[0].Name)
+	assert.Equal(t, " default ", placeholders[0].DefaultValue)
+	assert.Equal(t, "pod", placeholders[1].Name)
+	assert.Equal(t, "", placeholders[1].DefaultValue)
+	assert.Equal(t, "context", placeholders[2].Name)
+
+	assert.Empty(t, ParsePlaceholders("cat < in.txt > out.txt 2>&1 <<EOF"))
+}
+
+func TestFillPlaceholders(t *testing.T) {
+	assert.Equal(t,
+		"kubectl -n prod logs api-1 && echo api-1 <missing>",
+		FillPlaceholders(
+			"kubectl -n {{namespace:default}} logs <pod> && echo {{pod}} <missing>",
+			map[string]string{"namespace": "prod", "pod": "api-1"},
+		),
+	)
+}
+
+func TestFillPlaceholdersFunc(t *testing.T) {
+	script := "echo {{a}} <b> {{a:x}}"
+	var offsets []int
+	assert.Equal(t,
+		"echo A <b> A",
+		FillPlaceholdersFunc(script, func(name string, start int, end int) (string, bool) {
+			offsets = append(offsets, start, end)
+			return strings.ToUpper(name), name == "a"
+		}),
+	)
+	assert.Equal(t, []int{5, 10, 11, 14, 15, 22}, offsets)
+}
+
+func TestPlaceholder_GetInitialValue(t *testing.T) {
+	tests := []struct {
+		placeholder Placeholder
+		name        string
+		want        string
+	}{
+		{
+			name:        "nothing",
+			placeholder: Placeholder{Name: "a", Description: "", AllowedValues: nil, DefaultValue: "", Value: ""},
+			want:        "",
+		},
+		{
+			name:        "remembered value first",
+			placeholder: Placeholder{Name: "a", Description: "", AllowedValues: nil, DefaultValue: "def", Value: "old"},
+			want:        "old",
+		},
+		{
+			name:        "default value",
+			placeholder: Placeholder{Name: "a", Description: "", AllowedValues: nil, DefaultValue: "def", Value: ""},
+			want:        "def",
+		},
+		{
+			name: "remembered value not allowed anymore",
+			placeholder: Placeholder{
+				Name: "a", Description: "", AllowedValues: []string{"x", "y"}, DefaultValue: "", Value: "old",
+			},
+			want: "x",
+		},
+		{
+			name: "remembered value allowed",
+			placeholder: Placeholder{
+				Name: "a", Description: "", AllowedValues: []string{"x", "y"}, DefaultValue: "", Value: "y",
+			},
+			want: "y",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.placeholder.GetInitialValue())
+		})
+	}
+}
+
+func TestPlaceholderDefinitions(t *testing.T) {
+	definitions := ParsePlaceholderDefinitions(
+		"namespace | kubernetes namespace | default, prod ,\n\n  pod  \nhost | target host",
+	)
+	require.Len(t, definitions, 3)
+	assert.Equal(t, "namespace", definitions[0].Name)
+	assert.Equal(t, "kubernetes namespace", definitions[0].Description)
+	assert.Equal(t, []string{"default", "prod"}, definitions[0].AllowedValues)
+	assert.Equal(t, "pod", definitions[1].Name)
+	assert.Empty(t, definitions[1].AllowedValues)
+	assert.Equal(t, "target host", definitions[2].Description)
+
+	formatted := FormatPlaceholderDefinitions(definitions)
+	assert.Equal(t, "namespace | kubernetes namespace | default, prod\npod\nhost | target host", formatted)
+	assert.Equal(t, definitions, ParsePlaceholderDefinitions(formatted))
+
+	placeholders := ParsePlaceholders("ssh <host> kubectl -n <namespace>")
+	ApplyDefinitions(placeholders, definitions)
+	assert.Equal(t, "target host", placeholders[0].Description)
+	assert.Equal(t, []string{"default", "prod"}, placeholders[1].AllowedValues)
+}
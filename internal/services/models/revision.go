@@ -0,0 +1,78 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// CommandRevision is the content of a command before one of its saves
+type CommandRevision struct {
+	CreationDatetime time.Time
+	Title            string
+	Description      string
+	Script           string
+	Tags             []string
+	ID               resource.ID
+	CommandID        resource.ID
+}
+
+// NewCommandRevision creates a revision keeping the current content of the command
+func NewCommandRevision(command *Command, timestamp time.Time) *CommandRevision {
+	return &CommandRevision{
+		ID:               0,
+		CommandID:        command.ID,
+		CreationDatetime: timestamp,
+		Title:            command.Title,
+		Description:      command.Description,
+		Script:           command.Script,
+		Tags:             append([]string{}, command.Tags...),
+	}
+}
+
+func (r *CommandRevision) GetID() resource.ID {
+	return r.ID
+}
+
+// HasSameContent returns true if the revision has the same title, description,
+// script and tags as the command
+func (r *CommandRevision) HasSameContent(command *Command) bool {
+	return r.Title == command.Title &&
+		r.Description == command.Description &&
+		r.Script == command.Script &&
+		FormatTags(r.Tags) == FormatTags(command.Tags)
+}
+
+// ApplyTo replaces the content of the command by the content of the revision
+func (r *CommandRevision) ApplyTo(command *Command) {
+	command.Title = r.Title
+	command.Description = r.Description
+	command.Script = r.Script
+	command.Tags = append([]string{}, r.Tags...)
+}
+
+// GetRevisionText returns the content of a command version as text,
+// one field after the other, suitable for a line diff
+func GetRevisionText(title, description, script string, tags []string) string {
+	var text strings.Builder
+	text.WriteString("Title: " + title + "\n")
+	text.WriteString("Tags: " + FormatTags(tags) + "\n")
+	text.WriteString("Description:\n")
+	if description != "" {
+		text.WriteString(description + "\n")
+	}
+	text.WriteString("Script:\n")
+	text.WriteString(script)
+	return text.String()
+}
+
+// GetText returns the content of the revision as text (see GetRevisionText)
+func (r *CommandRevision) GetText() string {
+	return GetRevisionText(r.Title, r.Description, r.Script, r.Tags)
+}
+
+// GetRevisionText returns the content of the command as text (see GetRevisionText)
+func (c *Command) GetRevisionText() string {
+	return GetRevisionText(c.Title, c.Description, c.Script, c.Tags)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandRevision(t *testing.T) {
+	command := NewCommand("docker ps", 0, time.Now())
+	command.Title = "list containers"
+	command.Tags = []string{"docker"}
+	revision := NewCommandRevision(command, time.Now())
+
+	t.Run("same content", func(t *testing.T) {
+		assert.True(t, revision.HasSameContent(command))
+		command.Tags[0] = "container"
+		assert.Equal(t, []string{"docker"}, revision.Tags, "tags are copied")
+		assert.False(t, revision.HasSameContent(command))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		assert.Equal(t,
+			"Title: list containers\nTags: docker\nDescription:\nScript:\ndocker ps",
+			revision.GetText(),
+		)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		command.Script = "docker ps -a"
+		revision.ApplyTo(command)
+		assert.Equal(t, "docker ps", command.Script)
+		assert.Equal(t, []string{"docker"}, command.Tags)
+		assert.True(t, revision.HasSameContent(command))
+	})
+}
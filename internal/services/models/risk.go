@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// RiskLevel tells how much damage a command can do if it is run by mistake
+type RiskLevel string
+
+const (
+	// RiskLevelNone is the risk level of the commands matching no danger rule
+	RiskLevelNone   RiskLevel = ""
+	RiskLevelLow    RiskLevel = "LOW"
+	RiskLevelMedium RiskLevel = "MEDIUM"
+	RiskLevelHigh   RiskLevel = "HIGH"
+)
+
+// ParseRiskLevel converts a case insensitive level (none, low, medium or high) to a RiskLevel,
+// false is returned if the level is unknown
+func ParseRiskLevel(level string) (RiskLevel, bool) {
+	switch strings.ToUpper(strings.TrimSpace(level)) {
+	case "NONE":
+		return RiskLevelNone, true
+	case string(RiskLevelLow):
+		return RiskLevelLow, true
+	case string(RiskLevelMedium):
+		return RiskLevelMedium, true
+	case string(RiskLevelHigh):
+		return RiskLevelHigh, true
+	default:
+		return RiskLevelNone, false
+	}
+}
+
+// Rank orders the risk levels, 0 for RiskLevelNone up to 3 for RiskLevelHigh
+func (l RiskLevel) Rank() int {
+	switch l {
+	case RiskLevelLow:
+		return 1
+	case RiskLevelMedium:
+		return 2
+	case RiskLevelHigh:
+		return 3
+	case RiskLevelNone:
+		return 0
+	default:
+		return 0
+	}
+}
+
+// RequiresConfirmation returns true if copying, selecting or running the command must be confirmed
+func (l RiskLevel) RequiresConfirmation() bool {
+	return l == RiskLevelHigh
+}
+
+// CommandRisk is the risk level of the script of a command
+type CommandRisk struct {
+	Script string
+	// Reason lists the danger rules matching the script
+	Reason    string
+	Level     RiskLevel
+	CommandID resource.ID
+}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// CommandRunStatus is the outcome of a command run
+type CommandRunStatus string
+
+const (
+	// CommandRunStatusNone is the last run status of the commands never run
+	CommandRunStatusNone     CommandRunStatus = ""
+	CommandRunStatusSuccess  CommandRunStatus = "SUCCESS"
+	CommandRunStatusFailure  CommandRunStatus = "FAILURE"
+	CommandRunStatusCanceled CommandRunStatus = "CANCELED"
+	// CommandRunStatusError means the command could not be started
+	CommandRunStatusError CommandRunStatus = "ERROR"
+)
+
+const (
+	// MaxCommandRunOutputSize is the number of bytes of output kept for each run,
+	// the beginning of a longer output is dropped
+	MaxCommandRunOutputSize = 4096
+	// MaxCommandRuns is the number of runs kept for each command, the oldest ones are dropped
+	MaxCommandRuns = 20
+)
+
+// CommandRun is the result of an execution of a command
+type CommandRun struct {
+	StartDatetime    time.Time
+	WorkingDirectory string
+	// Output is the end of stdout and stderr
+	Output          string
+	Status          CommandRunStatus
+	ID              resource.ID
+	CommandID       resource.ID
+	Duration        time.Duration
+	ExitCode        int // -1 if the command has not exited (canceled or not started)
+	OutputTruncated bool
+}
+
+// NewCommandRun creates the run of a command without output
+func NewCommandRun(
+	commandID resource.ID, start time.Time, duration time.Duration, exitCode int, status CommandRunStatus,
+) *CommandRun {
+	return &CommandRun{
+		ID:               0,
+		CommandID:        commandID,
+		StartDatetime:    start,
+		Duration:         duration,
+		ExitCode:         exitCode,
+		Status:           status,
+		WorkingDirectory: "",
+		Output:           "",
+		OutputTruncated:  false,
+	}
+}
+
+// GetExitCodeRunStatus returns the status of a run of a command that has exited with the exit code
+func GetExitCodeRunStatus(exitCode int) CommandRunStatus {
+	if exitCode == 0 {
+		return CommandRunStatusSuccess
+	}
+	return CommandRunStatusFailure
+}
+
+func (r *CommandRun) GetID() resource.ID {
+	return r.ID
+}
+
+// SetOutput keeps the end of the output, at most MaxCommandRunOutputSize bytes,
+// truncated tells the output given is already the end of a longer output
+func (r *CommandRun) SetOutput(output string, truncated bool) {
+	if excess := len(output) - MaxCommandRunOutputSize; excess > 0 {
+		// the output is cut at the beginning of a character
+		for excess < len(output) && !utf8.RuneStart(output[excess]) {
+			excess++
+		}
+		output = output[excess:]
+		truncated = true
+	}
+	r.Output = output
+	r.OutputTruncated = truncated
+}
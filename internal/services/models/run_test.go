@@ -0,0 +1,25 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandRun_SetOutput(t *testing.T) {
+	run := NewCommandRun(1, time.Now(), 0, 0, CommandRunStatusSuccess)
+	run.SetOutput("é"+strings.Repeat("a", MaxCommandRunOutputSize-1), false)
+	assert.Equal(t, strings.Repeat("a", MaxCommandRunOutputSize-1), run.Output)
+	assert.True(t, run.OutputTruncated)
+
+	run.SetOutput("end", true)
+	assert.Equal(t, "end", run.Output)
+	assert.True(t, run.OutputTruncated)
+}
+
+func TestGetExitCodeRunStatus(t *testing.T) {
+	assert.Equal(t, CommandRunStatusSuccess, GetExitCodeRunStatus(0))
+	assert.Equal(t, CommandRunStatusFailure, GetExitCodeRunStatus(2))
+}
@@ -0,0 +1,22 @@
+package models
+
+const (
+	// SearchHighlightStart marks the beginning of a matched term in a search snippet
+	SearchHighlightStart = "\x02"
+	// SearchHighlightEnd marks the end of a matched term in a search snippet
+	SearchHighlightEnd = "\x03"
+	// SearchSnippetEllipsis is added where the script has been cut in a search snippet
+	SearchSnippetEllipsis = "…"
+	// SearchSnippetMaxTokens is the maximum number of tokens of a search snippet
+	SearchSnippetMaxTokens = 16
+)
+
+// CommandSearchResult is a command matching a full text search
+type CommandSearchResult struct {
+	Command *Command
+	// Snippet is the part of the script around the matched terms,
+	// matched terms are surrounded by SearchHighlightStart and SearchHighlightEnd
+	Snippet string
+	// Rank is the bm25 rank of the command, the lower the better
+	Rank float64
+}
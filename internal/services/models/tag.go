@@ -0,0 +1,92 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+const (
+	// TagMaxLength is the maximum length of a tag title (see tag table constraint)
+	TagMaxLength = 30
+	// TagsSeparator is the separator used to display and input a list of tags
+	TagsSeparator = ","
+)
+
+type Tag struct {
+	Title        string
+	ID           resource.ID
+	CommandCount int
+}
+
+func (t *Tag) GetID() resource.ID {
+	return t.ID
+}
+
+// ParseTags splits a comma separated list of tags.
+// Tags are trimmed, inner spaces are replaced by dashes and
+// duplicates are removed while keeping the original order.
+func ParseTags(value string) []string {
+	tags := []string{}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, TagsSeparator) {
+		tag := NormalizeTag(part)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// NormalizeTag trims the tag and replaces inner spaces by dashes
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(tag), "-")
+}
+
+// FormatTags returns the tags as a comma separated list, suitable for ParseTags
+func FormatTags(tags []string) string {
+	return strings.Join(tags, TagsSeparator+" ")
+}
+
+// HasTag returns true if the command has the given tag
+func (c *Command) HasTag(tag string) bool {
+	for _, t := range c.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// TagSuggestions computes the autocompletion suggestions of the last tag
+// being typed in a comma separated list of tags.
+// Each suggestion is the full value with the last tag completed, tags already
+// present in the value are not suggested.
+// A leading '-' (used to remove a tag) is kept on the completed tag.
+func TagSuggestions(value string, tags []string) []string {
+	head := ""
+	current := value
+	if pos := strings.LastIndex(value, TagsSeparator); pos >= 0 {
+		head = value[:pos+1] + " "
+		current = value[pos+1:]
+	}
+	current = strings.TrimLeft(current, " ")
+	if strings.HasPrefix(current, "-") {
+		head += "-"
+	}
+
+	existing := map[string]bool{}
+	for _, tag := range ParseTags(head) {
+		existing[strings.TrimPrefix(tag, "-")] = true
+	}
+	suggestions := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if existing[tag] {
+			continue
+		}
+		suggestions = append(suggestions, head+tag)
+	}
+	return suggestions
+}
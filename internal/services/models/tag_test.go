@@ -0,0 +1,59 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTags(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "empty", value: "", want: []string{}},
+		{name: "only separators", value: " , ,", want: []string{}},
+		{name: "trimmed", value: " docker ,k8s ", want: []string{"docker", "k8s"}},
+		{name: "inner spaces", value: "my tag", want: []string{"my-tag"}},
+		{name: "duplicates", value: "git, docker, git", want: []string{"git", "docker"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseTags(tt.value))
+		})
+	}
+}
+
+func TestFormatTags(t *testing.T) {
+	assert.Equal(t, "", FormatTags(nil))
+	assert.Equal(t, "docker, k8s", FormatTags([]string{"docker", "k8s"}))
+	assert.Equal(t, []string{"docker", "k8s"}, ParseTags(FormatTags([]string{"docker", "k8s"})))
+}
+
+func TestCommand_HasTag(t *testing.T) {
+	//nolint:exhaustruct // only tags are relevant for this test
+	cmd := &Command{Tags: []string{"docker", "K8s"}}
+	assert.True(t, cmd.HasTag("docker"))
+	assert.True(t, cmd.HasTag("k8s"))
+	assert.False(t, cmd.HasTag("git"))
+}
+
+func TestTagSuggestions(t *testing.T) {
+	tags := []string{"docker", "git", "k8s"}
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "empty value", value: "", want: []string{"docker", "git", "k8s"}},
+		{name: "first tag", value: "do", want: []string{"docker", "git", "k8s"}},
+		{name: "second tag", value: "docker, g", want: []string{"docker, git", "docker, k8s"}},
+		{name: "removal", value: "git,-d", want: []string{"git, -docker", "git, -k8s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, TagSuggestions(tt.value, tags))
+		})
+	}
+}
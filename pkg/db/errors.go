@@ -49,6 +49,10 @@ func (e *SchemaInitializationError) Error() string {
 	)
 }
 
+func (e *SchemaInitializationError) Unwrap() error {
+	return e.InnerError
+}
+
 type QueryExecutionError struct {
 	InnerError error
 	DBFilePath string
@@ -62,3 +66,93 @@ func (e *QueryExecutionError) Error() string {
 		e.InnerError,
 	)
 }
+
+type MigrationLoadingError struct {
+	InnerError error
+	DBFilePath string
+}
+
+func (e *MigrationLoadingError) Error() string {
+	return fmt.Sprintf("unable to load migrations for database file: %s (inner error: %v)",
+		e.DBFilePath,
+		e.InnerError,
+	)
+}
+
+func (e *MigrationLoadingError) Unwrap() error {
+	return e.InnerError
+}
+
+type InvalidMigrationFileNameError struct {
+	InnerError error
+	FileName   string
+}
+
+func (e *InvalidMigrationFileNameError) Error() string {
+	return fmt.Sprintf("invalid migration file name: %s, expected <version>_<name>.sql (inner error: %v)",
+		e.FileName,
+		e.InnerError,
+	)
+}
+
+type InvalidMigrationSequenceError struct {
+	ExpectedVersion int
+	FoundVersion    int
+}
+
+func (e *InvalidMigrationSequenceError) Error() string {
+	return fmt.Sprintf("invalid migration sequence: expected version %d, found version %d",
+		e.ExpectedVersion,
+		e.FoundVersion,
+	)
+}
+
+type MigrationError struct {
+	InnerError error
+	DBFilePath string
+	Name       string
+	Version    int
+}
+
+func (e *MigrationError) Error() string {
+	return fmt.Sprintf("migration %d (%s) failed for database file: %s (inner error: %v)",
+		e.Version,
+		e.Name,
+		e.DBFilePath,
+		e.InnerError,
+	)
+}
+
+func (e *MigrationError) Unwrap() error {
+	return e.InnerError
+}
+
+type DatabaseBackupError struct {
+	InnerError     error
+	DBFilePath     string
+	BackupFilePath string
+}
+
+func (e *DatabaseBackupError) Error() string {
+	return fmt.Sprintf("unable to backup database file: %s to %s (inner error: %v)",
+		e.DBFilePath,
+		e.BackupFilePath,
+		e.InnerError,
+	)
+}
+
+type DatabaseVersionTooRecentError struct {
+	DBFilePath       string
+	DBVersion        int
+	SupportedVersion int
+}
+
+func (e *DatabaseVersionTooRecentError) Error() string {
+	return fmt.Sprintf(
+		"database file: %s has schema version %d but this binary only supports up to version %d, "+
+			"please upgrade shell-command-bookmarker",
+		e.DBFilePath,
+		e.DBVersion,
+		e.SupportedVersion,
+	)
+}
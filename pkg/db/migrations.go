@@ -0,0 +1,262 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// migrationFileExtension is the extension of migration files
+	migrationFileExtension = ".sql"
+	// migrationNameSeparator separates the version from the name in migration file names
+	migrationNameSeparator = "_"
+	// baselineVersion is the version of databases created before migrations were introduced
+	baselineVersion = 1
+)
+
+// Migration represents a single versioned schema change
+type Migration struct {
+	Name    string
+	SQL     string
+	Version int
+}
+
+// LoadMigrations reads the migrations from the given file system.
+// Migration files must be named <version>_<name>.sql (eg: 0002_add_tag_color.sql),
+// versions must start at 1 and be contiguous.
+// The returned migrations are sorted by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || path.Ext(entry.Name()) != migrationFileExtension {
+			continue
+		}
+		migration, err := parseMigrationFileName(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		migration.SQL = string(content)
+		migrations = append(migrations, migration)
+	}
+
+	slices.SortFunc(migrations, func(i, j Migration) int {
+		return i.Version - j.Version
+	})
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, &InvalidMigrationSequenceError{
+				ExpectedVersion: i + 1,
+				FoundVersion:    migration.Version,
+			}
+		}
+	}
+
+	return migrations, nil
+}
+
+// parseMigrationFileName extracts version and name from a migration file name
+func parseMigrationFileName(fileName string) (Migration, error) {
+	baseName := strings.TrimSuffix(fileName, migrationFileExtension)
+	versionPart, namePart, found := strings.Cut(baseName, migrationNameSeparator)
+	if !found || namePart == "" {
+		return Migration{}, &InvalidMigrationFileNameError{FileName: fileName, InnerError: nil}
+	}
+	version, err := strconv.Atoi(versionPart)
+	if err != nil || version <= 0 {
+		return Migration{}, &InvalidMigrationFileNameError{FileName: fileName, InnerError: err}
+	}
+	return Migration{
+		Version: version,
+		Name:    strings.ReplaceAll(namePart, migrationNameSeparator, " "),
+		SQL:     "",
+	}, nil
+}
+
+// LatestVersion returns the highest version of the given migrations
+func LatestVersion(migrations []Migration) int {
+	if len(migrations) == 0 {
+		return 0
+	}
+	return migrations[len(migrations)-1].Version
+}
+
+// migrate brings the database schema up to date with the given migrations.
+// If isNew is false and migrations need to be applied, a backup of the database
+// file is taken before any change.
+func (a *SQLiteAdapter) migrate(migrations []Migration, isNew bool) error {
+	if err := a.createSchemaVersionTable(); err != nil {
+		return err
+	}
+
+	currentVersion, err := a.getSchemaVersion(isNew)
+	if err != nil {
+		return err
+	}
+
+	latestVersion := LatestVersion(migrations)
+	if currentVersion > latestVersion {
+		return &DatabaseVersionTooRecentError{
+			DBFilePath:       a.path,
+			DBVersion:        currentVersion,
+			SupportedVersion: latestVersion,
+		}
+	}
+	if currentVersion == latestVersion {
+		slog.Debug("Database schema is up to date", "version", currentVersion)
+		return nil
+	}
+
+	if !isNew {
+		backupPath, err := a.backup(currentVersion)
+		if err != nil {
+			return err
+		}
+		slog.Info("Database backup created before migration", "backupPath", backupPath)
+	}
+
+	return a.applyMigrations(migrations[currentVersion:])
+}
+
+// createSchemaVersionTable creates the table that records applied migrations
+func (a *SQLiteAdapter) createSchemaVersionTable() error {
+	_, err := a.db.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_datetime TEXT NOT NULL DEFAULT (datetime('now'))
+	)`)
+	if err != nil {
+		return &QueryExecutionError{
+			DBFilePath: a.path,
+			Query:      "Creating schema_version table",
+			InnerError: err,
+		}
+	}
+	return nil
+}
+
+// getSchemaVersion returns the current schema version of the database.
+// Databases created before the migration system have no recorded version but
+// already contain the initial schema, so they are stamped with the baseline version.
+func (a *SQLiteAdapter) getSchemaVersion(isNew bool) (int, error) {
+	var version int
+	row := a.db.QueryRow("SELECT IFNULL(MAX(version), 0) FROM schema_version")
+	if err := row.Scan(&version); err != nil {
+		return 0, &QueryExecutionError{
+			DBFilePath: a.path,
+			Query:      "Retrieving schema version",
+			InnerError: err,
+		}
+	}
+	if version > 0 || isNew {
+		return version, nil
+	}
+
+	var tablesCount int
+	row = a.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'command'")
+	if err := row.Scan(&tablesCount); err != nil {
+		return 0, &QueryExecutionError{
+			DBFilePath: a.path,
+			Query:      "Detecting legacy schema",
+			InnerError: err,
+		}
+	}
+	if tablesCount == 0 {
+		return 0, nil
+	}
+
+	slog.Info("Legacy database detected, recording baseline schema version", "version", baselineVersion)
+	_, err := a.db.Exec(
+		"INSERT INTO schema_version (version, name) VALUES (?, ?)",
+		baselineVersion, "initial schema",
+	)
+	if err != nil {
+		return 0, &QueryExecutionError{
+			DBFilePath: a.path,
+			Query:      "Recording baseline schema version",
+			InnerError: err,
+		}
+	}
+	return baselineVersion, nil
+}
+
+// backup writes a consistent copy of the database next to the original file
+func (a *SQLiteAdapter) backup(currentVersion int) (string, error) {
+	backupPath := fmt.Sprintf(
+		"%s.v%d.%s.bak", a.path, currentVersion, time.Now().Format("20060102150405"),
+	)
+	if _, err := a.db.Exec("VACUUM INTO ?", backupPath); err != nil {
+		return "", &DatabaseBackupError{
+			DBFilePath:     a.path,
+			BackupFilePath: backupPath,
+			InnerError:     err,
+		}
+	}
+	return backupPath, nil
+}
+
+// applyMigrations runs the given migrations in a single transaction
+func (a *SQLiteAdapter) applyMigrations(migrations []Migration) (err error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return &QueryExecutionError{
+			DBFilePath: a.path,
+			Query:      "Starting migration transaction",
+			InnerError: err,
+		}
+	}
+	defer func() {
+		if err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				slog.Error("Error rolling back migrations", "error", rollbackErr)
+			}
+		}
+	}()
+
+	for _, migration := range migrations {
+		slog.Info("Applying database migration", "version", migration.Version, "name", migration.Name)
+		if err = applyMigration(tx, migration); err != nil {
+			return &MigrationError{
+				DBFilePath: a.path,
+				Version:    migration.Version,
+				Name:       migration.Name,
+				InnerError: err,
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return &QueryExecutionError{
+			DBFilePath: a.path,
+			Query:      "Committing migrations",
+			InnerError: err,
+		}
+	}
+	return nil
+}
+
+func applyMigration(tx *sql.Tx, migration Migration) error {
+	if _, err := tx.Exec(migration.SQL); err != nil {
+		return err
+	}
+	_, err := tx.Exec(
+		"INSERT INTO schema_version (version, name) VALUES (?, ?)",
+		migration.Version, migration.Name,
+	)
+	return err
+}
@@ -0,0 +1,163 @@
+package db
+
+import (
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMigrationsFS(files map[string]string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		//nolint:exhaustruct // only data is relevant for migrations
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	return fsys
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("sorted by version", func(t *testing.T) {
+		migrations, err := LoadMigrations(newMigrationsFS(map[string]string{
+			"0002_add_index.sql":      "CREATE INDEX idx ON item(title);",
+			"0001_initial_schema.sql": "CREATE TABLE item (id INTEGER PRIMARY KEY, title TEXT);",
+			"README.md":               "ignored",
+		}))
+		require.NoError(t, err)
+		require.Len(t, migrations, 2)
+		assert.Equal(t, 1, migrations[0].Version)
+		assert.Equal(t, "initial schema", migrations[0].Name)
+		assert.Equal(t, 2, migrations[1].Version)
+		assert.Equal(t, "add index", migrations[1].Name)
+		assert.Equal(t, 2, LatestVersion(migrations))
+	})
+
+	t.Run("invalid file name", func(t *testing.T) {
+		_, err := LoadMigrations(newMigrationsFS(map[string]string{
+			"initial.sql": "",
+		}))
+		var target *InvalidMigrationFileNameError
+		assert.ErrorAs(t, err, &target)
+	})
+
+	t.Run("gap in versions", func(t *testing.T) {
+		_, err := LoadMigrations(newMigrationsFS(map[string]string{
+			"0001_initial.sql": "",
+			"0003_other.sql":   "",
+		}))
+		var target *InvalidMigrationSequenceError
+		require.ErrorAs(t, err, &target)
+		assert.Equal(t, 2, target.ExpectedVersion)
+		assert.Equal(t, 3, target.FoundVersion)
+	})
+}
+
+func openAdapter(t *testing.T, dbPath string, files map[string]string) (*SQLiteAdapter, error) {
+	t.Helper()
+	adapter, ok := NewSQLiteAdapter(dbPath, newMigrationsFS(files)).(*SQLiteAdapter)
+	require.True(t, ok)
+	return adapter, adapter.Open()
+}
+
+func getVersion(t *testing.T, adapter *SQLiteAdapter) int {
+	t.Helper()
+	var version int
+	require.NoError(t, adapter.GetDB().QueryRow("SELECT MAX(version) FROM schema_version").Scan(&version))
+	return version
+}
+
+func TestSQLiteAdapter_Migrate(t *testing.T) {
+	initial := map[string]string{
+		"0001_initial_schema.sql": "CREATE TABLE command (id INTEGER PRIMARY KEY, title TEXT);",
+	}
+	upgraded := map[string]string{
+		"0001_initial_schema.sql": initial["0001_initial_schema.sql"],
+		"0002_add_description.sql": "ALTER TABLE command ADD COLUMN description TEXT;" +
+			"UPDATE command SET description = 'migrated';",
+	}
+
+	t.Run("new database applies all migrations", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "new.db")
+		adapter, err := openAdapter(t, dbPath, upgraded)
+		require.NoError(t, err)
+		defer adapter.Close()
+		assert.Equal(t, 2, getVersion(t, adapter))
+
+		matches, err := filepath.Glob(dbPath + ".*.bak")
+		require.NoError(t, err)
+		assert.Empty(t, matches, "no backup expected for a new database")
+	})
+
+	t.Run("existing database is backed up and upgraded", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "existing.db")
+		adapter, err := openAdapter(t, dbPath, initial)
+		require.NoError(t, err)
+		_, err = adapter.GetDB().Exec("INSERT INTO command (title) VALUES ('kept')")
+		require.NoError(t, err)
+		require.NoError(t, adapter.Close())
+
+		adapter, err = openAdapter(t, dbPath, upgraded)
+		require.NoError(t, err)
+		defer adapter.Close()
+		assert.Equal(t, 2, getVersion(t, adapter))
+
+		var title, description string
+		require.NoError(t, adapter.GetDB().QueryRow("SELECT title, description FROM command").Scan(&title, &description))
+		assert.Equal(t, "kept", title)
+		assert.Equal(t, "migrated", description)
+
+		matches, err := filepath.Glob(dbPath + ".v1.*.bak")
+		require.NoError(t, err)
+		assert.Len(t, matches, 1)
+	})
+
+	t.Run("legacy database without version gets baseline", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "legacy.db")
+		adapter, err := openAdapter(t, dbPath, initial)
+		require.NoError(t, err)
+		_, err = adapter.GetDB().Exec("DROP TABLE schema_version")
+		require.NoError(t, err)
+		require.NoError(t, adapter.Close())
+
+		adapter, err = openAdapter(t, dbPath, upgraded)
+		require.NoError(t, err)
+		defer adapter.Close()
+		assert.Equal(t, 2, getVersion(t, adapter))
+	})
+
+	t.Run("failing migration is rolled back", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "failing.db")
+		adapter, err := openAdapter(t, dbPath, initial)
+		require.NoError(t, err)
+		require.NoError(t, adapter.Close())
+
+		_, err = openAdapter(t, dbPath, map[string]string{
+			"0001_initial_schema.sql": initial["0001_initial_schema.sql"],
+			"0002_add_column.sql":     "ALTER TABLE command ADD COLUMN extra TEXT;",
+			"0003_broken.sql":         "THIS IS NOT SQL;",
+		})
+		var migrationErr *MigrationError
+		require.ErrorAs(t, err, &migrationErr)
+		assert.Equal(t, 3, migrationErr.Version)
+
+		adapter, err = openAdapter(t, dbPath, initial)
+		require.NoError(t, err)
+		defer adapter.Close()
+		assert.Equal(t, 1, getVersion(t, adapter))
+	})
+
+	t.Run("database newer than binary", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "newer.db")
+		adapter, err := openAdapter(t, dbPath, upgraded)
+		require.NoError(t, err)
+		require.NoError(t, adapter.Close())
+
+		_, err = openAdapter(t, dbPath, initial)
+		var tooRecentErr *DatabaseVersionTooRecentError
+		require.ErrorAs(t, err, &tooRecentErr)
+		assert.Equal(t, 2, tooRecentErr.DBVersion)
+		assert.Equal(t, 1, tooRecentErr.SupportedVersion)
+	})
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -17,9 +18,9 @@ const (
 
 // SQLiteAdapter represents a connection to a SQLite database
 type SQLiteAdapter struct {
-	db     *sql.DB
-	path   string
-	schema string
+	db           *sql.DB
+	migrationsFS fs.FS
+	path         string
 }
 
 type Driver interface {
@@ -37,15 +38,16 @@ type Adapter interface {
 }
 
 // NewSQLiteAdapter creates a new SQLite adapter
-func NewSQLiteAdapter(dbPath, schema string) Adapter {
+// migrationsFS contains the versioned migrations (see LoadMigrations)
+func NewSQLiteAdapter(dbPath string, migrationsFS fs.FS) Adapter {
 	return &SQLiteAdapter{
-		db:     nil,
-		path:   dbPath,
-		schema: schema,
+		db:           nil,
+		path:         dbPath,
+		migrationsFS: migrationsFS,
 	}
 }
 
-// Open opens the database connection and initializes the schema if needed
+// Open opens the database connection and migrates the schema if needed
 func (a *SQLiteAdapter) Open() error {
 	// Create the directory if it doesn't exist
 	dbDir := filepath.Dir(a.path)
@@ -76,17 +78,15 @@ func (a *SQLiteAdapter) Open() error {
 		}
 	}
 
-	// Initialize the schema if the database is new
-	if isNew {
-		if err := a.initSchema(); err != nil {
-			err := a.Close() // Close the DB if initialization fails
-			if err != nil {
-				slog.Error("Error closing database after schema initialization failure", "error", err)
-			}
-			return &SchemaInitializationError{
-				DBFilePath: a.path,
-				InnerError: err,
-			}
+	// Bring the schema up to date
+	if err := a.initSchema(isNew); err != nil {
+		closeErr := a.Close() // Close the DB if initialization fails
+		if closeErr != nil {
+			slog.Error("Error closing database after schema initialization failure", "error", closeErr)
+		}
+		return &SchemaInitializationError{
+			DBFilePath: a.path,
+			InnerError: err,
 		}
 	}
 
@@ -111,19 +111,17 @@ func (a *SQLiteAdapter) BeginTx() (*sql.Tx, error) {
 	return a.db.Begin()
 }
 
-// initSchema initializes the database schema
-func (a *SQLiteAdapter) initSchema() error {
-	// Execute the schema SQL
-	_, err := a.db.Exec(a.schema)
+// initSchema loads the embedded migrations and applies the pending ones
+func (a *SQLiteAdapter) initSchema(isNew bool) error {
+	migrations, err := LoadMigrations(a.migrationsFS)
 	if err != nil {
-		return &QueryExecutionError{
+		return &MigrationLoadingError{
 			DBFilePath: a.path,
-			Query:      "Loading Schema",
 			InnerError: err,
 		}
 	}
 
-	return nil
+	return a.migrate(migrations, isNew)
 }
 
 // fileExists checks if a file exists
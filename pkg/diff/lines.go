@@ -0,0 +1,101 @@
+// Package diff computes line based differences between two texts
+package diff
+
+import "strings"
+
+// Operation is the kind of change of a diff line
+type Operation int
+
+const (
+	// OperationEqual is a line present in both texts
+	OperationEqual Operation = iota
+	// OperationDelete is a line only present in the old text
+	OperationDelete
+	// OperationInsert is a line only present in the new text
+	OperationInsert
+)
+
+// Line is a line of a diff
+type Line struct {
+	Text      string
+	Operation Operation
+}
+
+// Prefix returns the unified diff prefix of the line
+func (l Line) Prefix() string {
+	switch l.Operation {
+	case OperationDelete:
+		return "-"
+	case OperationInsert:
+		return "+"
+	case OperationEqual:
+		return " "
+	default:
+		return " "
+	}
+}
+
+// Lines returns the lines of the old and new texts with the operations
+// transforming the old text into the new one, using the longest common
+// subsequence of lines. Deleted lines are given before inserted ones.
+func Lines(oldText, newText string) []Line {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	// lcs[i][j] is the length of the longest common subsequence
+	// of oldLines[i:] and newLines[j:]
+	lcs := make([][]int, len(oldLines)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]Line, 0, max(len(oldLines), len(newLines)))
+	i, j := 0, 0
+	for i < len(oldLines) && j < len(newLines) {
+		switch {
+		case oldLines[i] == newLines[j]:
+			lines = append(lines, Line{Text: oldLines[i], Operation: OperationEqual})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, Line{Text: oldLines[i], Operation: OperationDelete})
+			i++
+		default:
+			lines = append(lines, Line{Text: newLines[j], Operation: OperationInsert})
+			j++
+		}
+	}
+	for ; i < len(oldLines); i++ {
+		lines = append(lines, Line{Text: oldLines[i], Operation: OperationDelete})
+	}
+	for ; j < len(newLines); j++ {
+		lines = append(lines, Line{Text: newLines[j], Operation: OperationInsert})
+	}
+	return lines
+}
+
+// HasChanges returns true if at least one line is not equal
+func HasChanges(lines []Line) bool {
+	for _, line := range lines {
+		if line.Operation != OperationEqual {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		oldText string
+		newText string
+		want    []Line
+	}{
+		{
+			name:    "both empty",
+			oldText: "",
+			newText: "",
+			want:    []Line{},
+		},
+		{
+			name:    "identical",
+			oldText: "a\nb",
+			newText: "a\nb\n",
+			want: []Line{
+				{Text: "a", Operation: OperationEqual},
+				{Text: "b", Operation: OperationEqual},
+			},
+		},
+		{
+			name:    "line changed",
+			oldText: "a\nb\nc",
+			newText: "a\nB\nc",
+			want: []Line{
+				{Text: "a", Operation: OperationEqual},
+				{Text: "b", Operation: OperationDelete},
+				{Text: "B", Operation: OperationInsert},
+				{Text: "c", Operation: OperationEqual},
+			},
+		},
+		{
+			name:    "lines added and removed",
+			oldText: "a\nb",
+			newText: "b\nc\nd",
+			want: []Line{
+				{Text: "a", Operation: OperationDelete},
+				{Text: "b", Operation: OperationEqual},
+				{Text: "c", Operation: OperationInsert},
+				{Text: "d", Operation: OperationInsert},
+			},
+		},
+		{
+			name:    "from empty",
+			oldText: "",
+			newText: "a",
+			want: []Line{
+				{Text: "a", Operation: OperationInsert},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Lines(tt.oldText, tt.newText)
+			assert.Equal(t, tt.want, lines)
+			assert.Equal(t, tt.oldText != tt.newText && tt.name != "identical", HasChanges(lines))
+		})
+	}
+}
+
+func TestLine_Prefix(t *testing.T) {
+	assert.Equal(t, " ", Line{Text: "a", Operation: OperationEqual}.Prefix())
+	assert.Equal(t, "-", Line{Text: "a", Operation: OperationDelete}.Prefix())
+	assert.Equal(t, "+", Line{Text: "a", Operation: OperationInsert}.Prefix())
+}
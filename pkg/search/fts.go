@@ -0,0 +1,32 @@
+package search
+
+import (
+	"strings"
+)
+
+// FTSQuery converts a user filter into a FTS5 match expression.
+// Each word becomes a quoted prefix query, so special characters typed by
+// the user are never interpreted as FTS5 operators, and all the words are
+// required (implicit AND).
+// Returns an empty string if the filter does not contain any word.
+func FTSQuery(filter string) string {
+	words := strings.Fields(filter)
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		// a lone quote would produce an empty phrase
+		if strings.Trim(word, `"`) == "" {
+			continue
+		}
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// RankToScore converts a bm25 rank (negative, the lower the better) into a
+// score between 0 and MaxScore relative to the best rank of the results.
+func RankToScore(rank float64, bestRank float64) int {
+	if bestRank >= 0 || rank >= 0 {
+		return MaxScore
+	}
+	return max(0, min(MaxScore, int(MaxScore*rank/bestRank)))
+}
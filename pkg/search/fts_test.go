@@ -0,0 +1,33 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{name: "empty", filter: "  ", want: ""},
+		{name: "single word", filter: "dock", want: `"dock"*`},
+		{name: "several words", filter: "docker  ps", want: `"docker"* "ps"*`},
+		{name: "operators are quoted", filter: "NOT a-b OR c*", want: `"NOT"* "a-b"* "OR"* "c*"*`},
+		{name: "quotes are escaped", filter: `say "hi`, want: `"say"* """hi"*`},
+		{name: "lone quote ignored", filter: `" ls`, want: `"ls"*`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FTSQuery(tt.filter))
+		})
+	}
+}
+
+func TestRankToScore(t *testing.T) {
+	assert.Equal(t, MaxScore, RankToScore(-4, -4))
+	assert.Equal(t, MaxScore/2, RankToScore(-2, -4))
+	assert.Equal(t, MaxScore, RankToScore(0, 0))
+}
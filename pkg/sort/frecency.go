@@ -0,0 +1,57 @@
+package sort
+
+import "time"
+
+// Weights of a use depending on its age, inspired by the Firefox frecency algorithm
+const (
+	frecencyDay         = 24 * time.Hour
+	frecencyHoursWeight = 100 // used in the last 4 hours
+	frecencyDayWeight   = 80  // used in the last day
+	frecencyWeekWeight  = 60  // used in the last week
+	frecencyMonthWeight = 40  // used in the last month
+	frecencyOldWeight   = 20  // used in the last 3 months
+	frecencyStaleWeight = 10  // used more than 3 months ago
+
+	frecencyHoursMaxAge = 4 * time.Hour
+	frecencyWeekMaxAge  = 7 * frecencyDay
+	frecencyMonthMaxAge = 30 * frecencyDay
+	frecencyOldMaxAge   = 90 * frecencyDay
+)
+
+// Frecency combines the frequency and the recency of the uses of an element,
+// the use count is weighted by the age of the last use at the given time.
+// An element that has never been used has a frecency of 0.
+func Frecency(useCount int, lastUsed time.Time, now time.Time) int {
+	if useCount <= 0 || lastUsed.IsZero() {
+		return 0
+	}
+	age := now.Sub(lastUsed)
+	switch {
+	case age < frecencyHoursMaxAge:
+		return useCount * frecencyHoursWeight
+	case age < frecencyDay:
+		return useCount * frecencyDayWeight
+	case age < frecencyWeekMaxAge:
+		return useCount * frecencyWeekWeight
+	case age < frecencyMonthMaxAge:
+		return useCount * frecencyMonthWeight
+	case age < frecencyOldMaxAge:
+		return useCount * frecencyOldWeight
+	default:
+		return useCount * frecencyStaleWeight
+	}
+}
+
+// CompareFrecency compares the frecency of two elements at the given time,
+// the most recently used element wins when frecencies are equal
+func CompareFrecency(
+	useCount1 int, lastUsed1 time.Time,
+	useCount2 int, lastUsed2 time.Time,
+	now time.Time,
+) int {
+	result := CompareInt(Frecency(useCount1, lastUsed1, now), Frecency(useCount2, lastUsed2, now))
+	if result != 0 {
+		return result
+	}
+	return CompareTime(lastUsed1, lastUsed2)
+}
@@ -0,0 +1,48 @@
+package sort
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFrecency(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		lastUsed time.Time
+		name     string
+		useCount int
+		want     int
+	}{
+		{name: "never used", useCount: 0, lastUsed: time.Time{}, want: 0},
+		{name: "no last use date", useCount: 3, lastUsed: time.Time{}, want: 0},
+		{name: "used recently", useCount: 2, lastUsed: now.Add(-time.Hour), want: 200},
+		{name: "used today", useCount: 2, lastUsed: now.Add(-10 * time.Hour), want: 160},
+		{name: "used this week", useCount: 2, lastUsed: now.Add(-3 * frecencyDay), want: 120},
+		{name: "used this month", useCount: 2, lastUsed: now.Add(-20 * frecencyDay), want: 80},
+		{name: "used this quarter", useCount: 2, lastUsed: now.Add(-60 * frecencyDay), want: 40},
+		{name: "used long ago", useCount: 2, lastUsed: now.Add(-365 * frecencyDay), want: 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Frecency(tt.useCount, tt.lastUsed, now))
+		})
+	}
+}
+
+func TestCompareFrecency(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	t.Run("frequent old use beats single recent use", func(t *testing.T) {
+		assert.Equal(t, 1, CompareFrecency(10, now.Add(-3*frecencyDay), 1, now.Add(-time.Minute), now))
+	})
+	t.Run("recent use beats old use with same count", func(t *testing.T) {
+		assert.Equal(t, -1, CompareFrecency(1, now.Add(-60*frecencyDay), 1, now.Add(-time.Hour), now))
+	})
+	t.Run("most recent wins on equal frecency", func(t *testing.T) {
+		assert.Equal(t, 1, CompareFrecency(1, now.Add(-time.Minute), 1, now.Add(-time.Hour), now))
+	})
+	t.Run("never used elements are equal", func(t *testing.T) {
+		assert.Equal(t, 0, CompareFrecency(0, time.Time{}, 0, time.Time{}, now))
+	})
+}
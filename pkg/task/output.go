@@ -0,0 +1,56 @@
+package task
+
+import (
+	"sync"
+	"unicode/utf8"
+)
+
+// MaxOutputSize is the number of bytes of output kept for each task,
+// the beginning of a longer output is dropped
+const MaxOutputSize = 1024 * 1024
+
+// keptOutputPercent is the percentage of MaxOutputSize kept when the beginning of the output is dropped,
+// the output is not moved at each write once the maximum size is reached
+const keptOutputPercent = 75
+
+// output keeps the end of the output written by a task, it can be read while the task is writing it
+type output struct {
+	buf       []byte
+	maxSize   int
+	truncated bool
+	mu        sync.Mutex
+}
+
+func newOutput(maxSize int) *output {
+	return &output{
+		buf:       []byte{},
+		maxSize:   maxSize,
+		truncated: false,
+		mu:        sync.Mutex{},
+	}
+}
+
+// Write appends the bytes to the output, it never fails.
+// Once the maximum size is exceeded, the beginning is dropped to keep keptOutputPercent of the maximum size.
+func (o *output) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.buf = append(o.buf, p...)
+	if len(o.buf) > o.maxSize {
+		excess := len(o.buf) - o.maxSize*keptOutputPercent/100
+		// the output is cut at the beginning of a character
+		for excess < len(o.buf) && !utf8.RuneStart(o.buf[excess]) {
+			excess++
+		}
+		o.buf = append(o.buf[:0], o.buf[excess:]...)
+		o.truncated = true
+	}
+	return len(p), nil
+}
+
+// String returns the output kept and true if its beginning has been dropped
+func (o *output) String() (string, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return string(o.buf), o.truncated
+}
@@ -0,0 +1,233 @@
+package task
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// MaxFinishedTasks is the number of finished tasks kept by the runner, the oldest ones are dropped
+const MaxFinishedTasks = 100
+
+// Executor runs the script of a task with the given streams until the context is canceled
+type Executor interface {
+	Execute(ctx context.Context, script string, streams Streams) (exitCode int, err error)
+}
+
+// Streams are the standard streams of the script of a task
+type Streams struct {
+	// Stdin is the input of the script, the script has no input if nil
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+// Runner runs the tasks in the order they are enqueued,
+// at most maxTasks tasks are running at the same time
+type Runner struct {
+	executor Executor
+	ctx      context.Context //nolint:containedctx // canceled by Stop to stop all the tasks
+	stop     context.CancelFunc
+	// onChange, if not nil, is called each time the status or the output of a task changes,
+	// it is called from the goroutines running the tasks
+	onChange func(*Task)
+	// onFinish, if not nil, is called once the script of a task has returned,
+	// before the change is notified. It is not called for the tasks canceled while queued.
+	onFinish func(*Task)
+	// tasks are sorted by ID
+	tasks    []*Task
+	queue    []*Task
+	running  int
+	maxTasks int
+	lastID   resource.ID
+	mu       sync.Mutex
+}
+
+// NewRunner creates a runner executing the scripts with the executor
+func NewRunner(executor Executor, maxTasks int, onChange func(*Task), onFinish func(*Task)) *Runner {
+	ctx, stop := context.WithCancel(context.Background())
+	return &Runner{
+		executor: executor,
+		ctx:      ctx,
+		stop:     stop,
+		onChange: onChange,
+		onFinish: onFinish,
+		tasks:    []*Task{},
+		queue:    []*Task{},
+		running:  0,
+		maxTasks: max(1, maxTasks),
+		lastID:   0,
+		mu:       sync.Mutex{},
+	}
+}
+
+// Enqueue creates a task running the script, it is started as soon as a slot is available
+func (r *Runner) Enqueue(sourceID resource.ID, name string, script string) *Task {
+	r.mu.Lock()
+	r.lastID++
+	task := newTask(r.lastID, sourceID, name, script)
+	task.setStatus(Queued)
+	r.tasks = append(r.tasks, task)
+	r.queue = append(r.queue, task)
+	r.dropOldTasks()
+	r.mu.Unlock()
+
+	r.notify(task)
+	r.startQueuedTasks()
+	return task
+}
+
+// dropOldTasks forgets the oldest finished tasks beyond MaxFinishedTasks
+func (r *Runner) dropOldTasks() {
+	finished := 0
+	for _, task := range r.tasks {
+		if task.Status().IsFinal() {
+			finished++
+		}
+	}
+	r.tasks = slices.DeleteFunc(r.tasks, func(task *Task) bool {
+		if finished > MaxFinishedTasks && task.Status().IsFinal() {
+			finished--
+			return true
+		}
+		return false
+	})
+}
+
+// startQueuedTasks starts the queued tasks while there are free slots
+func (r *Runner) startQueuedTasks() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for r.running < r.maxTasks && len(r.queue) > 0 {
+		task := r.queue[0]
+		r.queue = r.queue[1:]
+		ctx, ok := task.start(r.ctx)
+		if !ok {
+			// canceled while queued
+			continue
+		}
+		r.running++
+		go r.run(ctx, task)
+	}
+}
+
+func (r *Runner) run(ctx context.Context, task *Task) {
+	r.execute(ctx, task, Streams{Stdin: nil, Stdout: nil, Stderr: nil})
+
+	r.mu.Lock()
+	r.running--
+	r.mu.Unlock()
+	r.notify(task)
+	r.startQueuedTasks()
+}
+
+// Run runs the script in the calling goroutine without waiting for a free slot and returns
+// the finished task. The script reads streams.Stdin and its stdout and stderr are also written
+// to streams.Stdout and streams.Stderr as they are produced, the output of the task keeps both.
+// The task is canceled when ctx is done.
+func (r *Runner) Run(ctx context.Context, sourceID resource.ID, name string, script string, streams Streams) *Task {
+	r.mu.Lock()
+	r.lastID++
+	task := newTask(r.lastID, sourceID, name, script)
+	r.tasks = append(r.tasks, task)
+	r.dropOldTasks()
+	r.mu.Unlock()
+
+	taskCtx, _ := task.start(ctx)
+	r.execute(taskCtx, task, streams)
+	r.notify(task)
+	return task
+}
+
+// execute runs the script of the started task until it exits or ctx is done,
+// stdout and stderr are both kept in the output of the task
+func (r *Runner) execute(ctx context.Context, task *Task, streams Streams) {
+	r.notify(task)
+	capture := io.Writer(&notifyingWriter{
+		writer: task.output,
+		notify: func() { r.notify(task) },
+	})
+	stdout, stderr := capture, capture
+	if streams.Stdout != nil {
+		stdout = io.MultiWriter(capture, streams.Stdout)
+	}
+	if streams.Stderr != nil {
+		stderr = io.MultiWriter(capture, streams.Stderr)
+	}
+	exitCode, err := r.executor.Execute(ctx, task.Script, Streams{
+		Stdin:  streams.Stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+	if ctx.Err() != nil {
+		// stopped by Cancel or by Stop
+		task.Cancel()
+	}
+	task.finish(exitCode, err)
+	if r.onFinish != nil {
+		r.onFinish(task)
+	}
+}
+
+func (r *Runner) notify(task *Task) {
+	if r.onChange != nil {
+		r.onChange(task)
+	}
+}
+
+// Tasks returns the tasks, the oldest first
+func (r *Runner) Tasks() []*Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.tasks)
+}
+
+// GetTask returns the task having the given ID, nil if it does not exist
+func (r *Runner) GetTask(id resource.ID) *Task {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, task := range r.tasks {
+		if task.ID == id {
+			return task
+		}
+	}
+	return nil
+}
+
+// Cancel stops the task if it is running or removes it from the queue,
+// false is returned if the task does not exist or is already finished
+func (r *Runner) Cancel(id resource.ID) bool {
+	task := r.GetTask(id)
+	if task == nil || !task.Cancel() {
+		return false
+	}
+	r.notify(task)
+	return true
+}
+
+// Stop cancels all the tasks, no task is started anymore
+func (r *Runner) Stop() {
+	r.mu.Lock()
+	queue := r.queue
+	r.queue = []*Task{}
+	r.mu.Unlock()
+	for _, task := range queue {
+		task.Cancel()
+	}
+	r.stop()
+}
+
+// notifyingWriter calls notify after each write
+type notifyingWriter struct {
+	writer io.Writer
+	notify func()
+}
+
+func (w *notifyingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.notify()
+	return n, err
+}
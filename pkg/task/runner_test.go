@@ -0,0 +1,217 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	waitTimeout = 2 * time.Second
+	waitTick    = 5 * time.Millisecond
+)
+
+var errExecutor = errors.New("executor failure")
+
+// fakeExecutor writes the script as output then blocks until the script is released or canceled,
+// the exit code is the length of the script
+type fakeExecutor struct {
+	released map[string]chan struct{}
+	executed []string
+	mu       sync.Mutex
+}
+
+func newFakeExecutor(scripts ...string) *fakeExecutor {
+	executor := &fakeExecutor{
+		released: make(map[string]chan struct{}, len(scripts)),
+		executed: []string{},
+		mu:       sync.Mutex{},
+	}
+	for _, script := range scripts {
+		executor.released[script] = make(chan struct{})
+	}
+	return executor
+}
+
+func (e *fakeExecutor) Execute(ctx context.Context, script string, streams Streams) (int, error) {
+	e.mu.Lock()
+	e.executed = append(e.executed, script)
+	released := e.released[script]
+	e.mu.Unlock()
+	if script == "error" {
+		return -1, errExecutor
+	}
+	_, _ = streams.Stdout.Write([]byte(script))
+	if streams.Stdin != nil {
+		input, _ := io.ReadAll(streams.Stdin)
+		_, _ = streams.Stderr.Write(input)
+	}
+	select {
+	case <-released:
+		return len(script), nil
+	case <-ctx.Done():
+		return -1, nil
+	}
+}
+
+func (e *fakeExecutor) release(script string) {
+	close(e.released[script])
+}
+
+func (e *fakeExecutor) getExecuted() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]string{}, e.executed...)
+}
+
+func waitStatus(t *testing.T, task *Task, status Status) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return task.Status() == status
+	}, waitTimeout, waitTick, "task #%d should be %s, it is %s", task.ID, status, task.Status())
+}
+
+func TestRunner_ConcurrencyLimit(t *testing.T) {
+	executor := newFakeExecutor("first", "second", "third")
+	runner := NewRunner(executor, 2, nil, nil)
+	defer runner.Stop()
+
+	first := runner.Enqueue(1, "first task", "first")
+	second := runner.Enqueue(2, "second task", "second")
+	third := runner.Enqueue(3, "third task", "third")
+	waitStatus(t, first, Running)
+	waitStatus(t, second, Running)
+	assert.Equal(t, Queued, third.Status())
+
+	executor.release("first")
+	waitStatus(t, first, Exited)
+	waitStatus(t, third, Running)
+	assert.Equal(t, 5, first.ExitCode())
+	output, truncated := first.Output()
+	assert.Equal(t, "first", output)
+	assert.False(t, truncated)
+
+	executor.release("second")
+	executor.release("third")
+	waitStatus(t, second, Exited)
+	waitStatus(t, third, Exited)
+	assert.ElementsMatch(t, []string{"first", "second", "third"}, executor.getExecuted())
+	assert.Equal(t, []*Task{first, second, third}, runner.Tasks())
+	assert.Same(t, third, runner.GetTask(third.ID))
+}
+
+func TestRunner_Cancel(t *testing.T) {
+	executor := newFakeExecutor("running", "queued")
+	finished := make(chan *Task, 10)
+	runner := NewRunner(executor, 1, nil, func(task *Task) {
+		finished <- task
+	})
+	defer runner.Stop()
+
+	running := runner.Enqueue(1, "running task", "running")
+	queued := runner.Enqueue(2, "queued task", "queued")
+	waitStatus(t, running, Running)
+
+	assert.True(t, runner.Cancel(queued.ID))
+	assert.Equal(t, Canceled, queued.Status())
+	assert.True(t, runner.Cancel(running.ID))
+	waitStatus(t, running, Canceled)
+	assert.Equal(t, -1, running.ExitCode())
+	assert.False(t, runner.Cancel(running.ID), "a finished task cannot be canceled")
+	assert.False(t, runner.Cancel(42), "unknown task")
+
+	// the canceled queued task is never executed
+	next := runner.Enqueue(3, "error task", "error")
+	waitStatus(t, next, Errored)
+	require.ErrorIs(t, next.Err(), errExecutor)
+	assert.Equal(t, []string{"running", "error"}, executor.getExecuted())
+	// the task canceled while queued has never finished
+	assert.Same(t, running, <-finished)
+	assert.Same(t, next, <-finished)
+	assert.Empty(t, finished)
+}
+
+func TestRunner_Stop(t *testing.T) {
+	executor := newFakeExecutor("running", "queued")
+	changes := make(chan *Task, 10)
+	runner := NewRunner(executor, 1, func(task *Task) {
+		select {
+		case changes <- task:
+		default:
+		}
+	}, nil)
+
+	running := runner.Enqueue(1, "running task", "running")
+	queued := runner.Enqueue(2, "queued task", "queued")
+	waitStatus(t, running, Running)
+	runner.Stop()
+
+	waitStatus(t, running, Canceled)
+	assert.Equal(t, Canceled, queued.Status())
+	assert.NotEmpty(t, changes, "the changes should be notified")
+}
+
+func TestRunner_Run(t *testing.T) {
+	executor := newFakeExecutor("foreground", "running")
+	finished := make(chan *Task, 2)
+	runner := NewRunner(executor, 1, nil, func(task *Task) {
+		finished <- task
+	})
+	defer runner.Stop()
+	// the foreground task does not wait for the slot taken by the running task
+	running := runner.Enqueue(1, "running task", "running")
+	waitStatus(t, running, Running)
+	executor.release("foreground")
+
+	var stdout, stderr strings.Builder
+	task := runner.Run(context.Background(), 2, "foreground task", "foreground", Streams{
+		Stdin: strings.NewReader(" input"), Stdout: &stdout, Stderr: &stderr,
+	})
+	assert.Equal(t, Exited, task.Status())
+	assert.Equal(t, len("foreground"), task.ExitCode())
+	assert.Equal(t, "foreground", stdout.String())
+	assert.Equal(t, " input", stderr.String())
+	output, _ := task.Output()
+	assert.Equal(t, "foreground input", output, "the task output keeps stdout and stderr")
+	assert.Same(t, task, <-finished)
+	assert.Same(t, task, runner.GetTask(task.ID))
+
+	t.Run("canceled by the context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		task := runner.Run(ctx, 3, "canceled task", "never released", Streams{Stdin: nil, Stdout: nil, Stderr: nil})
+		assert.Equal(t, Canceled, task.Status())
+		assert.Equal(t, -1, task.ExitCode())
+	})
+}
+
+func TestOutput_Write(t *testing.T) {
+	out := newOutput(7)
+	n, err := out.Write([]byte("abc"))
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	output, truncated := out.String()
+	assert.Equal(t, "abc", output)
+	assert.False(t, truncated)
+
+	// the beginning is dropped down to 75% of the maximum size
+	// without splitting the multibyte characters
+	_, err = out.Write([]byte("déjà vu"))
+	require.NoError(t, err)
+	output, truncated = out.String()
+	assert.Equal(t, "à vu", output)
+	assert.True(t, truncated)
+
+	// the output is not cut again until the maximum size is exceeded
+	_, err = out.Write([]byte("!!"))
+	require.NoError(t, err)
+	output, _ = out.String()
+	assert.Equal(t, "à vu!!", output)
+}
@@ -0,0 +1,158 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
+)
+
+// Task is a script run by a Runner, its state can be read while it is running
+type Task struct {
+	startTime time.Time
+	endTime   time.Time
+	err       error
+	cancel    context.CancelFunc
+	output    *output
+	// Name describes the task in the UI
+	Name   string
+	Script string
+	status Status
+	ID     resource.ID
+	// SourceID is the ID of the element the task has been created from (eg: a command)
+	SourceID resource.ID
+	exitCode int
+	mu       sync.Mutex
+}
+
+func newTask(id resource.ID, sourceID resource.ID, name string, script string) *Task {
+	return &Task{
+		ID:        id,
+		SourceID:  sourceID,
+		Name:      name,
+		Script:    script,
+		status:    Pending,
+		exitCode:  -1,
+		err:       nil,
+		cancel:    nil,
+		startTime: time.Time{},
+		endTime:   time.Time{},
+		output:    newOutput(MaxOutputSize),
+		mu:        sync.Mutex{},
+	}
+}
+
+func (t *Task) GetID() resource.ID {
+	return t.ID
+}
+
+// Status returns the current stage of the task
+func (t *Task) Status() Status {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// ExitCode returns the exit code of the script, -1 if it has not exited
+func (t *Task) ExitCode() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exitCode
+}
+
+// Err returns the error preventing the script to run, nil if none
+func (t *Task) Err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// StartTime returns the time the script has been started, zero time if not started yet
+func (t *Task) StartTime() time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.startTime
+}
+
+// Duration returns the time the script has run, up to now if it is still running
+func (t *Task) Duration() time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case t.startTime.IsZero():
+		return 0
+	case t.endTime.IsZero():
+		return time.Since(t.startTime)
+	default:
+		return t.endTime.Sub(t.startTime)
+	}
+}
+
+// Output returns the output (stdout and stderr) of the script,
+// truncated is true if its beginning has been dropped because it exceeds MaxOutputSize
+func (t *Task) Output() (output string, truncated bool) {
+	return t.output.String()
+}
+
+// setStatus changes the status if the task is not finished yet
+func (t *Task) setStatus(status Status) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status.IsFinal() {
+		return false
+	}
+	t.status = status
+	return true
+}
+
+// start marks the task running and returns the context canceled by Cancel,
+// false is returned if the task has been canceled while it was queued
+func (t *Task) start(parent context.Context) (context.Context, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status.IsFinal() {
+		return nil, false
+	}
+	ctx, cancel := context.WithCancel(parent)
+	t.cancel = cancel
+	t.status = Running
+	t.startTime = time.Now()
+	return ctx, true
+}
+
+// finish records the result of the script
+func (t *Task) finish(exitCode int, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.endTime = time.Now()
+	if t.cancel != nil {
+		t.cancel()
+	}
+	switch {
+	case t.status == Canceled:
+	case err != nil:
+		t.status = Errored
+		t.err = err
+	default:
+		t.status = Exited
+	}
+	t.exitCode = exitCode
+}
+
+// Cancel stops the script if it is running or prevents it to start if it is queued,
+// false is returned if the task is already finished
+func (t *Task) Cancel() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.status.IsFinal() {
+		return false
+	}
+	t.status = Canceled
+	if t.cancel != nil {
+		t.cancel()
+	} else {
+		t.endTime = time.Now()
+	}
+	return true
+}
@@ -11,6 +11,8 @@ import (
 type YesNoPromptMsg struct {
 	form      *huh.Form
 	yesAction PromptAction
+	// isAccepted returns true if the yesAction has to be invoked once the form is completed
+	isAccepted func(form *huh.Form) bool
 }
 
 type PromptAction func() tea.Cmd
@@ -35,6 +37,87 @@ func YesNoPrompt(
 	return CmdHandler(YesNoPromptMsg{
 		form:      form,
 		yesAction: yesAction,
+		isAccepted: func(form *huh.Form) bool {
+			return form.GetBool("confirmKey") || form.State == huh.StateAborted
+		},
+	})
+}
+
+// ConfirmPrompt sends a message to enable the prompt widget, asking the user
+// to confirm an action. Unlike YesNoPrompt, aborting the form does not accept
+// it, the action is only invoked if the user explicitly answers yes.
+func ConfirmPrompt(
+	prompt string,
+	keyMap *huh.KeyMap,
+	yesAction PromptAction,
+) tea.Cmd {
+	group := huh.NewGroup(
+		huh.NewConfirm().
+			Title(prompt).
+			Key("confirmKey").
+			Affirmative("Yes!").
+			Negative("No."),
+	)
+	form := huh.NewForm(group)
+	form.WithKeyMap(keyMap)
+	return CmdHandler(YesNoPromptMsg{
+		form:      form,
+		yesAction: yesAction,
+		isAccepted: func(form *huh.Form) bool {
+			return form.State == huh.StateCompleted && form.GetBool("confirmKey")
+		},
+	})
+}
+
+// InputPrompt sends a message to enable the prompt widget, asking the user
+// for a single line value. suggestionsFunc, if not nil, computes the
+// autocompletion suggestions from the value being typed.
+// If the user validates the input then the action is invoked with the value.
+func InputPrompt(
+	prompt string,
+	placeholder string,
+	suggestionsFunc func(value string) []string,
+	keyMap *huh.KeyMap,
+	action func(value string) tea.Cmd,
+) tea.Cmd {
+	value := ""
+	input := huh.NewInput().
+		Title(prompt).
+		Key("inputKey").
+		Placeholder(placeholder).
+		Value(&value)
+	if suggestionsFunc != nil {
+		input.SuggestionsFunc(func() []string {
+			return suggestionsFunc(value)
+		}, &value)
+	}
+	form := huh.NewForm(huh.NewGroup(input))
+	form.WithKeyMap(keyMap)
+	return CmdHandler(YesNoPromptMsg{
+		form: form,
+		yesAction: func() tea.Cmd {
+			return action(form.GetString("inputKey"))
+		},
+		isAccepted: func(form *huh.Form) bool {
+			return form.State == huh.StateCompleted
+		},
+	})
+}
+
+// FormPrompt sends a message to enable the prompt widget with the given form,
+// the action is invoked once the user has completed the form.
+func FormPrompt(
+	form *huh.Form,
+	keyMap *huh.KeyMap,
+	action PromptAction,
+) tea.Cmd {
+	form.WithKeyMap(keyMap)
+	return CmdHandler(YesNoPromptMsg{
+		form:      form,
+		yesAction: action,
+		isAccepted: func(form *huh.Form) bool {
+			return form.State == huh.StateCompleted
+		},
 	})
 }
 
@@ -51,7 +134,7 @@ func (m YesNoPromptMsg) Update(msg tea.Msg) tea.Cmd {
 	_, cmd := m.form.Update(msg)
 	cmds = append(cmds, cmd)
 	if m.form.State != huh.StateNormal {
-		if m.form.GetBool("confirmKey") || m.form.State == huh.StateAborted {
+		if m.isAccepted(m.form) {
 			cmds = append(cmds, m.yesAction())
 		}
 	}
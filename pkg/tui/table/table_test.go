@@ -4,6 +4,7 @@ import (
 	"slices"
 	"testing"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/resource"
 	"github.com/fchastanet/shell-command-bookmarker/pkg/tui"
@@ -212,6 +213,59 @@ func TestTable_SelectRange(t *testing.T) {
 	}
 }
 
+func TestTable_BulkInsertBeforeCurrentRow(t *testing.T) {
+	tests := []struct {
+		name        string
+		selectRowID resource.ID
+	}{
+		{name: "current row is kept", selectRowID: resource.ID(0)},
+		{name: "current row is kept when the row to select is unknown", selectRowID: resource.ID(-1)},
+		{name: "current row is selected again", selectRowID: resource2.ID},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tbl := setupTest()
+			tbl.GotoID(resource2.ID)
+
+			// the new rows are sorted before the current row
+			cmd := tbl.Update(BulkInsertMsg[*testResource]{
+				InfoMsg: "",
+				Items: []*testResource{
+					{n: -2, ID: 7}, {n: -1, ID: 8},
+					&resource0, &resource1, &resource2, &resource3, &resource4, &resource5,
+				},
+				SelectRowID: tt.selectRowID,
+			})
+
+			got, ok := tbl.CurrentRow()
+			require.True(t, ok)
+			assert.Equal(t, &resource2, got)
+			selectedMsg := findRowSelectedActionMsg(cmd)
+			require.NotNil(t, selectedMsg)
+			assert.Equal(t, &resource2, selectedMsg.Row)
+			assert.Equal(t, resource2.ID, selectedMsg.RowID)
+		})
+	}
+}
+
+// findRowSelectedActionMsg runs the command and returns the RowSelectedActionMsg it sends
+func findRowSelectedActionMsg(cmd tea.Cmd) *RowSelectedActionMsg[*testResource] {
+	if cmd == nil {
+		return nil
+	}
+	switch msg := cmd().(type) {
+	case RowSelectedActionMsg[*testResource]:
+		return &msg
+	case tea.BatchMsg:
+		for _, batchCmd := range msg {
+			if selectedMsg := findRowSelectedActionMsg(batchCmd); selectedMsg != nil {
+				return selectedMsg
+			}
+		}
+	}
+	return nil
+}
+
 func sortStrings(i, j resource.ID) int {
 	if i < j {
 		return -1
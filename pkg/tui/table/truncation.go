@@ -25,11 +25,20 @@ func TruncateLeft(s string, w int, prefix string) string {
 	// instead it removes n chars from the left-side of the string and prefixes
 	// the prefix string. Because it is ANSI aware it is still useful. It is
 	// only called if the string is determined to need truncating.
-	if overlap := lipgloss.Width(s) - w; overlap > 0 {
+	width := lipgloss.Width(s)
+	if overlap := width - w; overlap > 0 {
 		// needs truncating - truncate off the overlapping number of chars as
 		// well as the width of the prefix so that once the prefix is prepended
 		// the length obeys w.
-		return ansi.TruncateLeft(s, overlap+lipgloss.Width(prefix), prefix)
+		n := overlap + lipgloss.Width(prefix)
+		truncated := ansi.TruncateLeft(s, n, prefix)
+		// a wide character (CJK, emoji) cut in its middle is kept entirely,
+		// remove one more cell until the result fits
+		for lipgloss.Width(truncated) > w && n < width {
+			n++
+			truncated = ansi.TruncateLeft(s, n, prefix)
+		}
+		return truncated
 	}
 	return s
 }
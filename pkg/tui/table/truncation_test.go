@@ -3,6 +3,7 @@ package table
 import (
 	"testing"
 
+	"github.com/charmbracelet/lipgloss"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,3 +18,45 @@ func TestTruncateLeft_Truncate(t *testing.T) {
 	got := TruncateLeft(path, 5, "…")
 	assert.Equal(t, "…/e/f", got)
 }
+
+func TestTruncateRight_Multibyte(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{"Accents", "cp résumé.pdf /tmp", 10, "cp résumé…"},
+		{"CJK", "/home/用户/文档", 9, "/home/用…"},
+		{"CJKNotSplit", "/home/用户/文档", 8, "/home/…"},
+		{"Emoji", "👨‍👩‍👧 family", 5, "👨‍👩‍👧 f…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateRight(tt.s, tt.width, "…")
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, lipgloss.Width(got), tt.width)
+		})
+	}
+}
+
+func TestTruncateLeft_Multibyte(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		width int
+		want  string
+	}{
+		{"Accents", "/tmp/été/résumé", 8, "…/résumé"},
+		{"CJK", "/home/用户/文档/项目/src", 9, "…项目/src"},
+		{"CJKNotSplit", "/home/用户/文档/项目/src", 8, "…目/src"},
+		{"Emoji", "git commit -m '修复 🎉'", 5, "… 🎉'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateLeft(tt.s, tt.width, "…")
+			assert.Equal(t, tt.want, got)
+			assert.LessOrEqual(t, lipgloss.Width(got), tt.width)
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package utils
 
-import "strings"
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
 
 // RemoveFirstLines removes the first n lines from a string
 func RemoveFirstLines(s string, n int) string {
@@ -15,3 +19,12 @@ func RemoveFirstLines(s string, n int) string {
 
 	return strings.Join(lines[n:], "\n")
 }
+
+// TruncateWidth cuts s to maxWidth terminal cells and appends tail if s is wider,
+// multibyte and wide characters (CJK, emoji) are never split
+func TruncateWidth(s string, maxWidth int, tail string) string {
+	if ansi.StringWidth(s) <= maxWidth {
+		return s
+	}
+	return ansi.Truncate(s, maxWidth, "") + tail
+}